@@ -14,11 +14,15 @@ import (
 
 	"mmemory/internal/bot"
 	"mmemory/internal/bot/handlers"
+	"mmemory/internal/models"
+	"mmemory/internal/repository/interfaces"
 	"mmemory/internal/repository/sqlite"
 	"mmemory/internal/service"
 	"mmemory/pkg/ai"
 	"mmemory/pkg/config"
+	"mmemory/pkg/holiday"
 	"mmemory/pkg/logger"
+	"mmemory/pkg/msgtemplate"
 	"mmemory/pkg/server"
 	"mmemory/pkg/version"
 )
@@ -65,6 +69,7 @@ func main() {
 	reminderRepo := sqlite.NewReminderRepository(database.GetDB())
 	reminderLogRepo := sqlite.NewReminderLogRepository(database.GetDB())
 	conversationRepo := sqlite.NewConversationRepository(database.GetDB())
+	routineRepo := sqlite.NewRoutineRepository(database.GetDB())
 
 	// 初始化Telegram Bot（使用自定义HTTP客户端）
 	bot, err := bot.NewBotWithCustomClient(cfg.Bot.Token, cfg.Bot.Debug)
@@ -74,14 +79,25 @@ func main() {
 
 	logger.Infof("✅ Telegram Bot 授权成功: @%s", bot.Self.UserName)
 
+	// 所有发往 Telegram 的消息经发送队列限速，遇到 429 和网络错误时自动重试
+	sendQueue := service.NewSendQueue(bot, service.SendQueueConfig{
+		GlobalRate:   cfg.Bot.SendQueue.GlobalRate,
+		ChatInterval: cfg.Bot.SendQueue.ChatInterval,
+		MaxRetries:   cfg.Bot.SendQueue.MaxRetries,
+		Workers:      cfg.Bot.SendQueue.Workers,
+	})
+	sendQueue.Start()
+	defer sendQueue.Stop()
+
 	// 初始化服务层
 	userService := service.NewUserService(userRepo)
 	reminderService := service.NewReminderService(reminderRepo)
 	reminderLogService := service.NewReminderLogService(reminderLogRepo, reminderRepo)
-	notificationService := service.NewNotificationService(bot)
+	notificationService := service.NewNotificationService(sendQueue)
 	schedulerService := service.NewSchedulerService(reminderRepo, reminderLogRepo, notificationService)
 	monitoringService := service.NewMonitoringService(userRepo, reminderRepo, reminderLogRepo)
 	conversationService := service.NewConversationService(conversationRepo)
+	routineService := service.NewRoutineService(routineRepo, reminderRepo, reminderLogRepo)
 
 	// 初始化AI服务（如果启用）
 	var aiParserService service.AIParserService
@@ -144,6 +160,203 @@ func main() {
 		reminderServiceWithScheduler.SetScheduler(schedulerService)
 	}
 
+	if routineServiceWithScheduler, ok := routineService.(interface {
+		SetScheduler(service.SchedulerService)
+	}); ok {
+		routineServiceWithScheduler.SetScheduler(schedulerService)
+	}
+
+	if reminderLogServiceWithScheduler, ok := reminderLogService.(interface {
+		SetScheduler(service.SchedulerService)
+	}); ok {
+		reminderLogServiceWithScheduler.SetScheduler(schedulerService)
+	}
+
+	if schedulerWithTimezone, ok := schedulerService.(interface {
+		SetDefaultTimezone(string) error
+	}); ok && cfg.Scheduler.Timezone != "" {
+		if err := schedulerWithTimezone.SetDefaultTimezone(cfg.Scheduler.Timezone); err != nil {
+			logger.Warnf("⚠️ 调度器时区配置无效，使用默认时区: %v", err)
+		}
+	}
+
+	// 通知中展示的时间与调度使用同一默认时区
+	if notificationWithTimezone, ok := notificationService.(interface {
+		SetDefaultTimezone(string) error
+	}); ok && cfg.Scheduler.Timezone != "" {
+		if err := notificationWithTimezone.SetDefaultTimezone(cfg.Scheduler.Timezone); err != nil {
+			logger.Warnf("⚠️ 通知时区配置无效，使用默认时区: %v", err)
+		}
+	}
+
+	if schedulerWithMissedPolicy, ok := schedulerService.(interface {
+		SetMissedPolicy(string, time.Duration) error
+	}); ok && cfg.Scheduler.MissedPolicy != "" {
+		if err := schedulerWithMissedPolicy.SetMissedPolicy(cfg.Scheduler.MissedPolicy, cfg.Scheduler.MissedLookback); err != nil {
+			logger.Warnf("⚠️ 错过提醒补偿策略配置无效，使用默认策略: %v", err)
+		}
+	}
+
+	if schedulerWithWorkers, ok := schedulerService.(interface {
+		SetMaxWorkers(int) error
+	}); ok && cfg.Scheduler.MaxWorkers > 0 {
+		if err := schedulerWithWorkers.SetMaxWorkers(cfg.Scheduler.MaxWorkers); err != nil {
+			logger.Warnf("⚠️ 调度器工作线程数配置无效，使用默认值: %v", err)
+		}
+	}
+
+	// 定期与数据库增量同步调度，配置热更新时同样同步一次
+	if schedulerWithReconcile, ok := schedulerService.(interface {
+		SetReconcileInterval(time.Duration) error
+	}); ok {
+		if err := schedulerWithReconcile.SetReconcileInterval(cfg.Scheduler.ReconcileInterval); err != nil {
+			logger.Warnf("⚠️ 调度同步间隔配置无效，使用默认值: %v", err)
+		}
+
+		hotReloadManager.RegisterReloadHandler("scheduler", func(newConfig *config.Config) error {
+			if err := schedulerWithReconcile.SetReconcileInterval(newConfig.Scheduler.ReconcileInterval); err != nil {
+				return err
+			}
+			return schedulerService.RefreshSchedules()
+		})
+	}
+
+	// 默认关怀策略，提醒和用户均未设置时使用
+	followUpPolicy := models.DefaultFollowUpPolicy
+	if cfg.Scheduler.FollowUpPolicy != "" {
+		if policy, err := models.ParseFollowUpPolicy(cfg.Scheduler.FollowUpPolicy); err != nil {
+			logger.Warnf("⚠️ 关怀策略配置无效，使用默认策略: %v", err)
+		} else {
+			followUpPolicy = policy
+		}
+	}
+	if schedulerWithFollowUp, ok := schedulerService.(interface {
+		SetFollowUpPolicy(models.FollowUpPolicy)
+	}); ok {
+		schedulerWithFollowUp.SetFollowUpPolicy(followUpPolicy)
+	}
+	if reminderLogServiceWithFollowUp, ok := reminderLogService.(interface {
+		SetDefaultFollowUpPolicy(models.FollowUpPolicy)
+	}); ok {
+		reminderLogServiceWithFollowUp.SetDefaultFollowUpPolicy(followUpPolicy)
+	}
+
+	if schedulerWithResume, ok := schedulerService.(interface {
+		SetResumeNotice(bool)
+	}); ok {
+		schedulerWithResume.SetResumeNotice(cfg.Scheduler.ResumeNotice)
+	}
+
+	// Telegram 之外的通知渠道，用户可通过 /channel 选择并设置备用渠道
+	if notificationWithChannels, ok := notificationService.(interface {
+		RegisterChannel(service.NotificationChannel)
+		SetDefaultChannels(string) error
+	}); ok {
+		if cfg.Notification.Email.Enabled {
+			emailChannel, err := service.NewEmailChannel(service.EmailConfig{
+				Host:     cfg.Notification.Email.Host,
+				Port:     cfg.Notification.Email.Port,
+				Username: cfg.Notification.Email.Username,
+				Password: cfg.Notification.Email.Password,
+				From:     cfg.Notification.Email.From,
+			})
+			if err != nil {
+				logger.Warnf("⚠️ 邮件渠道配置无效，未启用: %v", err)
+			} else {
+				notificationWithChannels.RegisterChannel(emailChannel)
+				logger.Info("📧 邮件通知渠道已启用")
+			}
+		}
+
+		if cfg.Notification.Webhook.Enabled {
+			webhookChannel, err := service.NewWebhookChannel(service.WebhookConfig{
+				Secret:       cfg.Notification.Webhook.Secret,
+				Timeout:      cfg.Notification.Webhook.Timeout,
+				AllowPrivate: cfg.Notification.Webhook.AllowPrivate,
+			})
+			if err != nil {
+				logger.Warnf("⚠️ Webhook 渠道配置无效，未启用: %v", err)
+			} else {
+				notificationWithChannels.RegisterChannel(webhookChannel)
+				logger.Info("🔗 Webhook 通知渠道已启用")
+			}
+		}
+
+		if cfg.Notification.DefaultChannels != "" {
+			if err := notificationWithChannels.SetDefaultChannels(cfg.Notification.DefaultChannels); err != nil {
+				logger.Warnf("⚠️ 默认通知渠道配置无效，只使用 Telegram: %v", err)
+			}
+		}
+	}
+
+	// 记录发出的提醒消息，关怀回复原消息，用户响应后统一更新
+	if notificationWithMessages, ok := notificationService.(interface {
+		SetMessageRepository(interfaces.ReminderMessageRepository)
+	}); ok {
+		notificationWithMessages.SetMessageRepository(sqlite.NewReminderMessageRepository(database.GetDB()))
+	}
+
+	// 按用户语言渲染通知消息，模板文件随配置热更新重新加载
+	if notificationWithTemplates, ok := notificationService.(interface {
+		SetTemplates(*msgtemplate.Set)
+		SetReminderLogRepository(interfaces.ReminderLogRepository)
+	}); ok {
+		notificationWithTemplates.SetReminderLogRepository(reminderLogRepo)
+
+		if cfg.Notification.TemplateDir != "" {
+			templates := msgtemplate.NewSet(cfg.Notification.TemplateDir)
+			if err := templates.Load(); err != nil {
+				logger.Warnf("⚠️ 加载消息模板失败，使用内置中文模板: %v", err)
+			} else {
+				logger.Infof("🌐 消息模板已加载，语言: %v", templates.Locales())
+			}
+			notificationWithTemplates.SetTemplates(templates)
+
+			hotReloadManager.RegisterReloadHandler("templates", func(newConfig *config.Config) error {
+				if err := templates.Reload(newConfig.Notification.TemplateDir); err != nil {
+					return fmt.Errorf("重新加载消息模板失败: %w", err)
+				}
+				logger.Infof("🌐 消息模板已重新加载，语言: %v", templates.Locales())
+				return nil
+			})
+		}
+	}
+
+	// 多实例部署时只有持有调度租约的实例触发提醒
+	if schedulerWithElection, ok := schedulerService.(interface {
+		EnableLeaderElection(interfaces.LeaseRepository, string, time.Duration)
+	}); ok && cfg.Scheduler.LeaderElection {
+		instanceID := cfg.Scheduler.InstanceID
+		if instanceID == "" {
+			hostname, _ := os.Hostname()
+			instanceID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+		}
+		schedulerWithElection.EnableLeaderElection(sqlite.NewLeaseRepository(database.GetDB()), instanceID, cfg.Scheduler.LeaseTTL)
+	}
+
+	// 加载节假日日历，"工作日"提醒据此跳过放假日并在调休上班日提醒
+	if schedulerWithHolidays, ok := schedulerService.(interface {
+		SetHolidayCalendar(holiday.Calendar, string)
+	}); ok && cfg.Scheduler.HolidayDir != "" {
+		calendar := holiday.NewFileCalendar(cfg.Scheduler.HolidayDir)
+		if err := calendar.Load(); err != nil {
+			logger.Warnf("⚠️ 加载节假日日历失败，工作日按周一到周五计算: %v", err)
+		} else {
+			logger.Infof("📅 节假日日历已加载，地区: %v", calendar.Regions())
+		}
+		schedulerWithHolidays.SetHolidayCalendar(calendar, cfg.Scheduler.HolidayRegion)
+
+		// 配置热更新时重新加载节假日文件并重建调度计划
+		hotReloadManager.RegisterReloadHandler("holiday", func(newConfig *config.Config) error {
+			if err := calendar.Reload(newConfig.Scheduler.HolidayDir); err != nil {
+				return fmt.Errorf("重新加载节假日日历失败: %w", err)
+			}
+			schedulerWithHolidays.SetHolidayCalendar(calendar, newConfig.Scheduler.HolidayRegion)
+			logger.Infof("📅 节假日日历已重新加载，地区: %v", calendar.Regions())
+			return schedulerService.RefreshSchedules()
+		})
+	}
+
 	// 启动监控服务
 	var metricsServer *server.MetricsServer
 	var monitoringCtx context.Context
@@ -164,7 +377,13 @@ func main() {
 
 	// 初始化消息处理器
 	messageHandler := handlers.NewMessageHandler(reminderService, userService, reminderLogService, aiParserService, conversationService)
+	messageHandler.SetScheduler(schedulerService)
+	messageHandler.SetRoutineService(routineService)
+	messageHandler.SetSender(sendQueue)
 	callbackHandler := handlers.NewCallbackHandler(reminderService, reminderLogService, schedulerService)
+	callbackHandler.SetRoutineService(routineService)
+	callbackHandler.SetSender(sendQueue)
+	callbackHandler.SetNotificationService(notificationService)
 
 	// 启动调度器
 	if err := schedulerService.Start(); err != nil {
@@ -172,12 +391,9 @@ func main() {
 	}
 	defer schedulerService.Stop()
 
-	// 启动超时处理器
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	go startOvertimeProcessor(ctx, reminderLogService, notificationService)
-
 	// 监听系统信号
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -363,45 +579,3 @@ func processUpdates(ctx context.Context, updates tgbotapi.UpdatesChannel, bot *t
 		}
 	}
 }
-
-// startOvertimeProcessor 启动超时处理器
-func startOvertimeProcessor(ctx context.Context, reminderLogService service.ReminderLogService, notificationService service.NotificationService) {
-	logger.Info("⏰ 超时处理器启动")
-
-	ticker := time.NewTicker(30 * time.Minute) // 每30分钟检查一次
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			logger.Info("超时处理器停止")
-			return
-		case <-ticker.C:
-			// 检查超时的提醒
-			overdueLogs, err := reminderLogService.GetOverdueReminders(ctx)
-			if err != nil {
-				logger.Errorf("获取超时提醒失败: %v", err)
-				continue
-			}
-
-			for _, log := range overdueLogs {
-				// 发送关怀消息
-				if err := notificationService.SendFollowUp(ctx, log); err != nil {
-					logger.Errorf("发送关怀消息失败 (LogID: %d): %v", log.ID, err)
-					continue
-				}
-
-				// 更新关怀次数
-				if err := reminderLogService.UpdateFollowUpCount(ctx, log.ID); err != nil {
-					logger.Errorf("更新关怀次数失败 (LogID: %d): %v", log.ID, err)
-				}
-
-				logger.Debugf("💌 已发送关怀消息: LogID=%d, 次数=%d", log.ID, log.FollowUpCount+1)
-			}
-
-			if len(overdueLogs) > 0 {
-				logger.Infof("📤 处理了 %d 个超时提醒", len(overdueLogs))
-			}
-		}
-	}
-}
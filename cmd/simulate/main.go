@@ -0,0 +1,243 @@
+// simulate 在数据库副本上用模拟时钟回放调度计划，打印这段时间内将会发送的消息
+//
+// 用法:
+//
+//	go run ./cmd/simulate -config configs/config.yaml -days 7
+//
+// 原数据库不会被修改，所有写入都发生在临时副本上
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"mmemory/internal/repository/sqlite"
+	"mmemory/internal/service"
+	"mmemory/pkg/clock"
+	"mmemory/pkg/config"
+	"mmemory/pkg/holiday"
+	"mmemory/pkg/logger"
+)
+
+func main() {
+	configFile := flag.String("config", "", "配置文件路径，默认按 ./configs/config.yaml 查找")
+	days := flag.Int("days", 7, "模拟的天数")
+	from := flag.String("from", "", "模拟起始时间，格式 2006-01-02 或 2006-01-02 15:04，默认当前时间")
+	logLevel := flag.String("log-level", "warn", "模拟期间的日志级别")
+	flag.Parse()
+
+	if *days <= 0 {
+		log.Fatalf("模拟天数必须大于0: %d", *days)
+	}
+
+	configManager := config.NewConfigManager()
+	var cfg *config.Config
+	var err error
+	if *configFile != "" {
+		cfg, err = configManager.LoadFromFile(*configFile)
+	} else {
+		cfg, err = configManager.Load()
+	}
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+
+	if err := logger.Init(*logLevel, "text", "stdout", ""); err != nil {
+		log.Fatalf("初始化日志失败: %v", err)
+	}
+
+	loc := time.Local
+	if cfg.Scheduler.Timezone != "" {
+		if loc, err = time.LoadLocation(cfg.Scheduler.Timezone); err != nil {
+			log.Fatalf("调度器时区配置无效: %v", err)
+		}
+	}
+
+	start := time.Now().In(loc)
+	if *from != "" {
+		if start, err = parseStart(*from, loc); err != nil {
+			log.Fatalf("起始时间格式错误: %v", err)
+		}
+	}
+	end := start.AddDate(0, 0, *days)
+
+	// 在数据库副本上运行，提醒记录、次数统计等写入不影响原数据
+	tempDir, err := os.MkdirTemp("", "mmemory-simulate-")
+	if err != nil {
+		log.Fatalf("创建临时目录失败: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	dbConfig := cfg.Database
+	dbConfig.DSN, err = copyDatabase(cfg.Database.DSN, tempDir)
+	if err != nil {
+		log.Fatalf("复制数据库失败: %v", err)
+	}
+
+	database, err := sqlite.NewDatabase(&dbConfig)
+	if err != nil {
+		log.Fatalf("打开数据库副本失败: %v", err)
+	}
+	defer database.Close()
+
+	simulated := clock.NewSimulated(start)
+	recorder := &recordingBot{clock: simulated, out: os.Stdout, loc: loc}
+
+	reminderRepo := sqlite.NewReminderRepository(database.GetDB())
+	reminderLogRepo := sqlite.NewReminderLogRepository(database.GetDB())
+	notificationService := service.NewNotificationService(recorder)
+	schedulerService := service.NewSchedulerService(reminderRepo, reminderLogRepo, notificationService)
+
+	scheduler, ok := schedulerService.(interface {
+		SetClock(clock.Clock)
+		SetDefaultTimezone(string) error
+		SetMissedPolicy(string, time.Duration) error
+		SetHolidayCalendar(holiday.Calendar, string)
+		SetReconcileInterval(time.Duration) error
+	})
+	if !ok {
+		log.Fatal("调度器不支持模拟时钟")
+	}
+
+	scheduler.SetClock(simulated)
+	// 副本没有其他写入方，无需定期同步
+	if err := scheduler.SetReconcileInterval(0); err != nil {
+		log.Fatalf("关闭调度同步失败: %v", err)
+	}
+	if cfg.Scheduler.Timezone != "" {
+		if err := scheduler.SetDefaultTimezone(cfg.Scheduler.Timezone); err != nil {
+			log.Fatalf("调度器时区配置无效: %v", err)
+		}
+		if notification, ok := notificationService.(interface{ SetDefaultTimezone(string) error }); ok {
+			if err := notification.SetDefaultTimezone(cfg.Scheduler.Timezone); err != nil {
+				log.Fatalf("通知时区配置无效: %v", err)
+			}
+		}
+	}
+	if cfg.Scheduler.MissedPolicy != "" {
+		if err := scheduler.SetMissedPolicy(cfg.Scheduler.MissedPolicy, cfg.Scheduler.MissedLookback); err != nil {
+			log.Fatalf("错过提醒补偿策略配置无效: %v", err)
+		}
+	}
+	if cfg.Scheduler.HolidayDir != "" {
+		calendar := holiday.NewFileCalendar(cfg.Scheduler.HolidayDir)
+		if err := calendar.Load(); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️ 加载节假日日历失败，工作日按周一到周五计算: %v\n", err)
+		}
+		scheduler.SetHolidayCalendar(calendar, cfg.Scheduler.HolidayRegion)
+	}
+
+	fmt.Printf("🧪 模拟时间段: %s ~ %s (%s)\n\n",
+		start.Format("2006-01-02 15:04"), end.Format("2006-01-02 15:04"), loc)
+
+	if err := schedulerService.Start(); err != nil {
+		log.Fatalf("启动调度器失败: %v", err)
+	}
+	simulated.AdvanceTo(end)
+	if err := schedulerService.Stop(); err != nil {
+		log.Fatalf("停止调度器失败: %v", err)
+	}
+
+	fmt.Printf("\n✅ 模拟结束，共发送 %d 条消息，涉及 %d 个用户\n", recorder.count, len(recorder.chats))
+}
+
+// parseStart 解析起始时间，只给日期时从当天 00:00 开始
+func parseStart(value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无法解析: %s", value)
+}
+
+// copyDatabase 将 SQLite 数据库文件复制到 dir，返回副本的 DSN
+func copyDatabase(dsn, dir string) (string, error) {
+	path, query, _ := strings.Cut(dsn, "?")
+	path = strings.TrimPrefix(path, "file:")
+	if path == "" || path == ":memory:" {
+		return "", fmt.Errorf("只支持基于文件的数据库: %s", dsn)
+	}
+
+	target := filepath.Join(dir, filepath.Base(path))
+	if err := copyFile(path, target); err != nil {
+		return "", err
+	}
+	// WAL 模式下未合并的写入保存在 -wal 文件中
+	if _, err := os.Stat(path + "-wal"); err == nil {
+		if err := copyFile(path+"-wal", target+"-wal"); err != nil {
+			return "", err
+		}
+	}
+
+	if query != "" {
+		return target + "?" + query, nil
+	}
+	return target, nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("打开 %s 失败: %w", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("创建 %s 失败: %w", dst, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("复制 %s 失败: %w", src, err)
+	}
+	return out.Close()
+}
+
+// recordingBot 代替 Telegram Bot，按模拟时间打印将要发送的消息
+type recordingBot struct {
+	mu    sync.Mutex
+	clock clock.Clock
+	out   io.Writer
+	loc   *time.Location
+	count int
+	chats map[int64]struct{}
+}
+
+func (b *recordingBot) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var chatID int64
+	var text string
+	switch msg := c.(type) {
+	case tgbotapi.MessageConfig:
+		chatID, text = msg.ChatID, msg.Text
+	case tgbotapi.EditMessageTextConfig:
+		chatID, text = msg.ChatID, msg.Text
+	default:
+		return tgbotapi.Message{}, nil
+	}
+
+	if b.chats == nil {
+		b.chats = make(map[int64]struct{})
+	}
+	b.chats[chatID] = struct{}{}
+	b.count++
+
+	fmt.Fprintf(b.out, "[%s] → %d\n%s\n\n", b.clock.Now().In(b.loc).Format("2006-01-02 15:04 Mon"), chatID, indent(text))
+	return tgbotapi.Message{MessageID: b.count}, nil
+}
+
+func indent(text string) string {
+	return "    " + strings.ReplaceAll(strings.TrimSpace(text), "\n", "\n    ")
+}
@@ -0,0 +1,94 @@
+package ai
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EndCondition 重复提醒的结束条件
+type EndCondition struct {
+	UntilDate      string // 截止日期 YYYY-MM-DD，含当天
+	MaxOccurrences int    // 最多触发次数，0 表示不限
+}
+
+// IsEmpty 是否没有任何结束条件
+func (e EndCondition) IsEmpty() bool {
+	return e.UntilDate == "" && e.MaxOccurrences == 0
+}
+
+var (
+	// "持续21天"、"坚持4周"、"连续3个月"
+	endDurationPattern = regexp.MustCompile(`[，,]?\s*(?:持续|坚持|连续)\s*(\d+)\s*(天|周|个月)`)
+	// "直到2026年12月31日"、"截止到12月31号为止"、"到2026-12-31结束"
+	endUntilPattern = regexp.MustCompile(`[，,]?\s*(?:直到|截止到|截至|截止|到)\s*(?:(\d{4})[年\-/])?(\d{1,2})[月\-/](\d{1,2})[日号]?\s*(?:为止|结束|止)?`)
+	// "共10次"、"一共5次"、"提醒10次后停止"
+	endCountPattern = regexp.MustCompile(`[，,]?\s*(?:(?:共|一共|总共)\s*(\d+)\s*次|(\d+)\s*次(?:后停止|后结束|就停|为止))`)
+)
+
+// ExtractEndCondition 从消息中提取结束条件，返回去掉结束条件描述后的消息
+// 支持: 持续/坚持/连续N天(周/个月)、直到/截止到某日、共N次/N次后停止
+func ExtractEndCondition(message string, now time.Time) (string, EndCondition) {
+	var end EndCondition
+
+	if m := endDurationPattern.FindStringSubmatch(message); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if n > 0 {
+			today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+			var last time.Time
+			switch m[2] {
+			case "周":
+				last = today.AddDate(0, 0, 7*n-1)
+			case "个月":
+				last = today.AddDate(0, n, -1)
+			default:
+				last = today.AddDate(0, 0, n-1)
+			}
+			end.UntilDate = last.Format("2006-01-02")
+			message = strings.Replace(message, m[0], "", 1)
+		}
+	}
+
+	if m := endUntilPattern.FindStringSubmatch(message); m != nil {
+		if until, ok := untilDate(m[1], m[2], m[3], now); ok {
+			end.UntilDate = until
+			message = strings.Replace(message, m[0], "", 1)
+		}
+	}
+
+	if m := endCountPattern.FindStringSubmatch(message); m != nil {
+		count := m[1]
+		if count == "" {
+			count = m[2]
+		}
+		if n, err := strconv.Atoi(count); err == nil && n > 0 {
+			end.MaxOccurrences = n
+			message = strings.Replace(message, m[0], "", 1)
+		}
+	}
+
+	return strings.TrimSpace(message), end
+}
+
+// untilDate 组装截止日期，省略年份时取今天之后最近的该日期
+func untilDate(yearStr, monthStr, dayStr string, now time.Time) (string, bool) {
+	month, _ := strconv.Atoi(monthStr)
+	day, _ := strconv.Atoi(dayStr)
+	year := now.Year()
+	if yearStr != "" {
+		year, _ = strconv.Atoi(yearStr)
+	}
+
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, now.Location())
+	if date.Month() != time.Month(month) || date.Day() != day {
+		return "", false // 日期不存在，如2月30日
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if yearStr == "" && date.Before(today) {
+		date = date.AddDate(1, 0, 0)
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", date.Year(), int(date.Month()), date.Day()), true
+}
@@ -0,0 +1,40 @@
+package ai
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestExtractEndCondition 测试结束条件提取
+func TestExtractEndCondition(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		message     string
+		wantMessage string
+		wantEnd     EndCondition
+	}{
+		{"持续N天", "每天早上7点提醒我冥想，坚持21天", "每天早上7点提醒我冥想", EndCondition{UntilDate: "2026-03-21"}},
+		{"连续N天在前", "连续7天每天9点提醒我背单词", "每天9点提醒我背单词", EndCondition{UntilDate: "2026-03-07"}},
+		{"持续N周", "每周一9点提醒我开会，持续4周", "每周一9点提醒我开会", EndCondition{UntilDate: "2026-03-28"}},
+		{"持续N个月", "每天8点提醒我吃维生素，持续1个月", "每天8点提醒我吃维生素", EndCondition{UntilDate: "2026-03-31"}},
+		{"直到具体日期", "每周一9点提醒我交周报，直到2026年12月31日", "每周一9点提醒我交周报", EndCondition{UntilDate: "2026-12-31"}},
+		{"截止日期省略年份已过", "每天8点提醒我打卡，截止到2月1日为止", "每天8点提醒我打卡", EndCondition{UntilDate: "2027-02-01"}},
+		{"共N次", "每天20点提醒我吃药，共10次", "每天20点提醒我吃药", EndCondition{MaxOccurrences: 10}},
+		{"N次后停止", "每周三18点提醒我跑步，5次后停止", "每周三18点提醒我跑步", EndCondition{MaxOccurrences: 5}},
+		{"日期和次数组合", "每天9点提醒我喝水，直到2026-04-30，共20次", "每天9点提醒我喝水", EndCondition{UntilDate: "2026-04-30", MaxOccurrences: 20}},
+		{"时间段不是截止日期", "每天9点到18点每2小时提醒我喝水", "每天9点到18点每2小时提醒我喝水", EndCondition{}},
+		{"无效日期", "每天8点提醒我打卡，直到2月30日", "每天8点提醒我打卡，直到2月30日", EndCondition{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, end := ExtractEndCondition(tt.message, now)
+			assert.Equal(t, tt.wantMessage, message)
+			assert.Equal(t, tt.wantEnd, end)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package ai
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"mmemory/internal/models"
+)
+
+const leadUnit = `(?:[\d零一二两三四五六七八九十]+|半)\s*个?\s*半?\s*(?:分钟|小时|天|周)`
+
+var (
+	// "提前半小时提醒我"、"提前1天、1小时和10分钟通知我"
+	leadPattern = regexp.MustCompile(`[，,]?\s*提前\s*(` + leadUnit + `(?:\s*[、,，和及与]\s*(?:提前\s*)?` + leadUnit + `)*)\s*(提醒我|提醒|通知我|通知)?`)
+	// 单个提前量: 数量、"个半"中的半、单位
+	leadItemPattern = regexp.MustCompile(`([\d零一二两三四五六七八九十]+|半)\s*个?\s*(半)?\s*(分钟|小时|天|周)`)
+	// 消息中的第一个时间点，用于补回"提醒我"
+	leadClockPattern = regexp.MustCompile(`\d{1,2}(?:[:：]\d{2}|点半|点\d{1,2}分?|点)`)
+)
+
+// ExtractLeadTimes 从消息中提取提前通知，返回去掉提前描述后的消息和按提前量从大到小排列的提前量
+// 支持: 提前N分钟/小时/天/周、提前半小时、提前一个半小时，多个提前量可用顿号或"和"连接
+// "下午3点看牙医，提前半小时提醒我"去掉提前描述后会丢失"提醒我"，此时把它补在第一个时间点之后
+func ExtractLeadTimes(message string) (string, []time.Duration) {
+	m := leadPattern.FindStringSubmatch(message)
+	if m == nil {
+		return message, nil
+	}
+
+	seen := make(map[time.Duration]bool)
+	var offsets []time.Duration
+	for _, item := range leadItemPattern.FindAllStringSubmatch(m[1], -1) {
+		offset, ok := leadDuration(item[1], item[2] != "", item[3])
+		if !ok || offset <= 0 || offset > models.MaxLeadTime || seen[offset] {
+			continue
+		}
+		seen[offset] = true
+		offsets = append(offsets, offset)
+	}
+	if len(offsets) == 0 {
+		return message, nil
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] > offsets[j] })
+
+	message = strings.TrimSpace(strings.Replace(message, m[0], "", 1))
+	if strings.HasPrefix(m[2], "提醒") && !strings.Contains(message, "提醒我") {
+		if loc := leadClockPattern.FindStringIndex(message); loc != nil {
+			message = message[:loc[1]] + "提醒我" + message[loc[1]:]
+		}
+	}
+	return message, offsets
+}
+
+// leadDuration 将数量和单位换算为时长，half 表示"一个半小时"中的半
+func leadDuration(amount string, half bool, unit string) (time.Duration, bool) {
+	var base time.Duration
+	switch unit {
+	case "分钟":
+		base = time.Minute
+	case "小时":
+		base = time.Hour
+	case "天":
+		base = 24 * time.Hour
+	case "周":
+		base = 7 * 24 * time.Hour
+	}
+
+	if amount == "半" {
+		return base / 2, true
+	}
+	n, ok := parseChineseNumber(amount)
+	if !ok {
+		return 0, false
+	}
+	d := time.Duration(n) * base
+	if half {
+		d += base / 2
+	}
+	return d, true
+}
+
+var chineseDigits = map[rune]int{'零': 0, '一': 1, '二': 2, '两': 2, '三': 3, '四': 4, '五': 5, '六': 6, '七': 7, '八': 8, '九': 9}
+
+// parseChineseNumber 解析阿拉伯数字或九十九以内的中文数字
+func parseChineseNumber(s string) (int, bool) {
+	if n, err := strconv.Atoi(s); err == nil {
+		return n, true
+	}
+
+	tens, units := "", s
+	if idx := strings.Index(s, "十"); idx >= 0 {
+		tens, units = s[:idx], s[idx+len("十"):]
+		if tens == "" {
+			tens = "一"
+		}
+	}
+
+	digit := func(part string) (int, bool) {
+		runes := []rune(part)
+		if len(runes) != 1 {
+			return 0, false
+		}
+		n, ok := chineseDigits[runes[0]]
+		return n, ok
+	}
+
+	n := 0
+	if strings.Contains(s, "十") {
+		t, ok := digit(tens)
+		if !ok {
+			return 0, false
+		}
+		n = t * 10
+		if units == "" {
+			return n, true
+		}
+	}
+	u, ok := digit(units)
+	if !ok {
+		return 0, false
+	}
+	return n + u, true
+}
@@ -0,0 +1,34 @@
+package ai
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestExtractLeadTimes 测试提前通知提取
+func TestExtractLeadTimes(t *testing.T) {
+	tests := []struct {
+		name        string
+		message     string
+		wantMessage string
+		wantLeads   []time.Duration
+	}{
+		{"提前半小时在后", "明天下午3点提醒我看牙医，提前半小时", "明天下午3点提醒我看牙医", []time.Duration{30 * time.Minute}},
+		{"补回提醒我", "明天下午3点看牙医，提前半小时提醒我", "明天下午3点提醒我看牙医", []time.Duration{30 * time.Minute}},
+		{"多个提前量", "2026年5月20日上午10点提醒我体检，提前1天、1小时和10分钟通知我", "2026年5月20日上午10点提醒我体检", []time.Duration{24 * time.Hour, time.Hour, 10 * time.Minute}},
+		{"中文数字", "明天9点提醒我交报告，提前两天和十五分钟提醒", "明天9点提醒我交报告", []time.Duration{48 * time.Hour, 15 * time.Minute}},
+		{"一个半小时", "今天18点提醒我去机场，提前一个半小时", "今天18点提醒我去机场", []time.Duration{90 * time.Minute}},
+		{"没有提前量", "每周一提醒我提前准备周报", "每周一提醒我提前准备周报", nil},
+		{"超过30天忽略", "明天9点提醒我续费，提前60天", "明天9点提醒我续费，提前60天", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, leads := ExtractLeadTimes(tt.message)
+			assert.Equal(t, tt.wantMessage, message)
+			assert.Equal(t, tt.wantLeads, leads)
+		})
+	}
+}
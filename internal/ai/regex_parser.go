@@ -10,6 +10,7 @@ import (
 
 	"mmemory/internal/models"
 	"mmemory/pkg/ai"
+	"mmemory/pkg/clock"
 	"mmemory/pkg/logger"
 )
 
@@ -17,6 +18,7 @@ import (
 // 用于在AI服务不可用时提供基础的提醒解析能力
 type RegexParser struct {
 	patterns []*ReminderPattern
+	clock    clock.Clock
 }
 
 // ReminderPattern 提醒解析模式
@@ -31,11 +33,27 @@ type ReminderPattern struct {
 func NewRegexParser() *RegexParser {
 	parser := &RegexParser{
 		patterns: make([]*ReminderPattern, 0),
+		clock:    clock.Real,
 	}
 	parser.initPatterns()
 	return parser
 }
 
+// SetClock 设置时钟，"今天"、"明天"等相对日期以此为基准
+func (p *RegexParser) SetClock(c clock.Clock) {
+	if c == nil {
+		c = clock.Real
+	}
+	p.clock = c
+}
+
+func (p *RegexParser) now() time.Time {
+	if p.clock == nil {
+		return time.Now()
+	}
+	return p.clock.Now()
+}
+
 // initPatterns 初始化解析模式
 func (p *RegexParser) initPatterns() {
 	// 注意: 模式顺序很重要！更具体的模式应该放在前面
@@ -45,7 +63,7 @@ func (p *RegexParser) initPatterns() {
 		Pattern: regexp.MustCompile(`今天\s*(上午|中午|下午|晚上|早上|早晨|午后)?\s*(\d{1,2})(?:[:：点时](\d{1,2}))?\s*(?:分)?\s*提醒我\s*(.+)`),
 		Type:    models.ReminderTypeTask,
 		ScheduleGen: func(matches []string) models.SchedulePattern {
-			today := time.Now().Format("2006-01-02")
+			today := p.now().Format("2006-01-02")
 			return models.SchedulePattern(fmt.Sprintf("once:%s", today))
 		},
 		TimeGen: func(matches []string) (int, int) {
@@ -105,7 +123,20 @@ func (p *RegexParser) initPatterns() {
 		Pattern: regexp.MustCompile(`工作日.*?(\d+)点.*?提醒我(.+)`),
 		Type:    models.ReminderTypeHabit,
 		ScheduleGen: func(matches []string) models.SchedulePattern {
-			return "weekly:1,2,3,4,5" // 周一到周五
+			return models.SchedulePatternWorkday // 法定工作日，跳过节假日并包含调休上班日
+		},
+		TimeGen: func(matches []string) (int, int) {
+			hour, _ := strconv.Atoi(matches[1])
+			return hour, 0
+		},
+	})
+
+	// 5. 每月最后一天: "每月最后一天晚上8点提醒我交房租"
+	p.patterns = append(p.patterns, &ReminderPattern{
+		Pattern: regexp.MustCompile(`每月最后一天.*?(\d+)点.*?提醒我(.+)`),
+		Type:    models.ReminderTypeHabit,
+		ScheduleGen: func(matches []string) models.SchedulePattern {
+			return models.SchedulePattern(fmt.Sprintf("%s:%s", models.SchedulePatternMonthly, models.MonthlyLastDay))
 		},
 		TimeGen: func(matches []string) (int, int) {
 			hour, _ := strconv.Atoi(matches[1])
@@ -113,12 +144,59 @@ func (p *RegexParser) initPatterns() {
 		},
 	})
 
-	// 5. 明天提醒: "明天下午2点提醒我取快递"
+	// 6. 每月第N个/最后一个星期几: "每月第一个周一上午10点提醒我开例会"
+	p.patterns = append(p.patterns, &ReminderPattern{
+		Pattern: regexp.MustCompile(`每月(?:第([一二三四五1-5])|最后一)个(?:周|星期)([一二三四五六日天]).*?(\d+)点.*?提醒我(.+)`),
+		Type:    models.ReminderTypeHabit,
+		ScheduleGen: func(matches []string) models.SchedulePattern {
+			weekday := parseWeekday(matches[2])
+			nth := models.MonthlyLastDay
+			if matches[1] != "" {
+				nth = strconv.Itoa(parseOrdinal(matches[1]))
+			}
+			return models.SchedulePattern(fmt.Sprintf("%s:%d#%s", models.SchedulePatternMonthly, weekday, nth))
+		},
+		TimeGen: func(matches []string) (int, int) {
+			hour, _ := strconv.Atoi(matches[3])
+			return hour, 0
+		},
+	})
+
+	// 7. 每月指定日期: "每月15号早上9点提醒我还信用卡"、"每月1号和15号9点提醒我发工资"
+	p.patterns = append(p.patterns, &ReminderPattern{
+		Pattern: regexp.MustCompile(`每月([\d、,，和号日]+)[号日].*?(\d+)点.*?提醒我(.+)`),
+		Type:    models.ReminderTypeHabit,
+		ScheduleGen: func(matches []string) models.SchedulePattern {
+			days := regexp.MustCompile(`\d+`).FindAllString(matches[1], -1)
+			return models.SchedulePattern(fmt.Sprintf("%s:%s", models.SchedulePatternMonthly, strings.Join(days, ",")))
+		},
+		TimeGen: func(matches []string) (int, int) {
+			hour, _ := strconv.Atoi(matches[2])
+			return hour, 0
+		},
+	})
+
+	// 8. 每年指定日期: "每年3月15日早上9点提醒我给妈妈过生日"
+	p.patterns = append(p.patterns, &ReminderPattern{
+		Pattern: regexp.MustCompile(`每年(\d{1,2})月(\d{1,2})[日号].*?(\d+)点.*?提醒我(.+)`),
+		Type:    models.ReminderTypeHabit,
+		ScheduleGen: func(matches []string) models.SchedulePattern {
+			month, _ := strconv.Atoi(matches[1])
+			day, _ := strconv.Atoi(matches[2])
+			return models.SchedulePattern(fmt.Sprintf("%s:%02d-%02d", models.SchedulePatternYearly, month, day))
+		},
+		TimeGen: func(matches []string) (int, int) {
+			hour, _ := strconv.Atoi(matches[3])
+			return hour, 0
+		},
+	})
+
+	// 9. 明天提醒: "明天下午2点提醒我取快递"
 	p.patterns = append(p.patterns, &ReminderPattern{
 		Pattern: regexp.MustCompile(`明天.*?(\d+)点.*?提醒我(.+)`),
 		Type:    models.ReminderTypeTask,
 		ScheduleGen: func(matches []string) models.SchedulePattern {
-			tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+			tomorrow := p.now().AddDate(0, 0, 1).Format("2006-01-02")
 			return models.SchedulePattern(fmt.Sprintf("once:%s", tomorrow))
 		},
 		TimeGen: func(matches []string) (int, int) {
@@ -127,7 +205,7 @@ func (p *RegexParser) initPatterns() {
 		},
 	})
 
-	// 6. 具体日期: "2025年10月15日上午10点提醒我体检"
+	// 10. 具体日期: "2025年10月15日上午10点提醒我体检"
 	p.patterns = append(p.patterns, &ReminderPattern{
 		Pattern: regexp.MustCompile(`(\d{4})年(\d{1,2})月(\d{1,2})日.*?(\d+)点.*?提醒我(.+)`),
 		Type:    models.ReminderTypeTask,
@@ -146,19 +224,45 @@ func (p *RegexParser) initPatterns() {
 
 // Parse 实现Parser接口
 func (p *RegexParser) Parse(ctx context.Context, userID string, message string) (*ai.ParseResult, error) {
-	message = strings.TrimSpace(message)
+	// "提前半小时提醒我"等提前通知不影响模式匹配，先提取出来
+	message, leads := ExtractLeadTimes(strings.TrimSpace(message))
+
+	result := p.match(message)
+	if result == nil {
+		// 没有匹配到任何模式
+		return nil, ai.NewAIError(ai.ErrorTypeParsing, "no regex pattern matched", nil)
+	}
+	result.Reminder.LeadTimes = models.FormatLeadTimes(leads)
+	return result, nil
+}
+
+// match 按顺序匹配所有模式，没有匹配时返回 nil
+func (p *RegexParser) match(message string) *ai.ParseResult {
+	// 带结束条件的消息（"持续21天"、"直到12月31日"）只匹配重复提醒模式
+	if cleaned, end := ExtractEndCondition(message, p.now()); !end.IsEmpty() {
+		for _, pattern := range p.patterns {
+			if pattern.Type != models.ReminderTypeHabit {
+				continue
+			}
+			if matches := pattern.Pattern.FindStringSubmatch(cleaned); len(matches) > 0 {
+				logger.Infof("Regex pattern matched with end condition: %s", pattern.Pattern.String())
+				result := p.buildParseResult(matches, pattern)
+				result.Reminder.UntilDate = end.UntilDate
+				result.Reminder.MaxOccurrences = end.MaxOccurrences
+				return result
+			}
+		}
+	}
 
 	// 遍历所有模式进行匹配
 	for _, pattern := range p.patterns {
 		matches := pattern.Pattern.FindStringSubmatch(message)
 		if len(matches) > 0 {
 			logger.Infof("Regex pattern matched: %s", pattern.Pattern.String())
-			return p.buildParseResult(matches, pattern), nil
+			return p.buildParseResult(matches, pattern)
 		}
 	}
-
-	// 没有匹配到任何模式
-	return nil, ai.NewAIError(ai.ErrorTypeParsing, "no regex pattern matched", nil)
+	return nil
 }
 
 // buildParseResult 构建解析结果
@@ -170,6 +274,17 @@ func (p *RegexParser) buildParseResult(matches []string, pattern *ReminderPatter
 	// 生成时间信息
 	hour, minute := pattern.TimeGen(matches)
 
+	// 同一句中有多个时间点时（"每天8点、13点和20点提醒我吃药"）全部作为触发时间
+	var times []string
+	if idx := strings.Index(matches[0], "提醒我"); idx > 0 {
+		if clocks := extractClockTimes(matches[0][:idx]); len(clocks) > 1 {
+			hour, minute = clocks[0][0], clocks[0][1]
+			for _, clock := range clocks {
+				times = append(times, fmt.Sprintf("%02d:%02d", clock[0], clock[1]))
+			}
+		}
+	}
+
 	// 生成调度模式
 	schedulePattern := pattern.ScheduleGen(matches)
 
@@ -183,14 +298,15 @@ func (p *RegexParser) buildParseResult(matches []string, pattern *ReminderPatter
 			Time: ai.TimeInfo{
 				Hour:            hour,
 				Minute:          minute,
-				Timezone:        "Asia/Shanghai",
+				Timezone:        ai.DefaultTimezone,
 				IsRelativeTime:  false,
 				ScheduleDetails: string(schedulePattern),
+				Times:           times,
 			},
 		},
 		ParsedBy:    p.GetName(),
 		ProcessTime: 0, // 正则解析几乎无延迟
-		Timestamp:   time.Now(),
+		Timestamp:   p.now(),
 	}
 }
 
@@ -209,6 +325,38 @@ func (p *RegexParser) IsHealthy() bool {
 	return true // 正则解析器总是健康的
 }
 
+// clockPattern 匹配 "8点"、"8点30"、"8点半"、"20:30"，可带时段前缀
+var clockPattern = regexp.MustCompile(`(上午|中午|下午|晚上|早上|早晨|午后)?\s*(\d{1,2})(?:[:：](\d{1,2})|点(半|\d{1,2})?)`)
+
+// extractClockTimes 提取文本中的所有时间点，省略时段的沿用前一个时段（"晚上8点和10点"）
+func extractClockTimes(text string) [][2]int {
+	var clocks [][2]int
+	period := ""
+	for _, m := range clockPattern.FindAllStringSubmatch(text, -1) {
+		if m[1] != "" {
+			period = m[1]
+		}
+
+		hour, _ := strconv.Atoi(m[2])
+		minute := 0
+		switch {
+		case m[3] != "":
+			minute, _ = strconv.Atoi(m[3])
+		case m[4] == "半":
+			minute = 30
+		case m[4] != "":
+			minute, _ = strconv.Atoi(m[4])
+		}
+
+		hour = normalizeHourForPeriod(hour, period)
+		if hour < 0 || hour > 23 || minute > 59 {
+			continue
+		}
+		clocks = append(clocks, [2]int{hour, minute})
+	}
+	return clocks
+}
+
 // parseWeekday 解析中文星期为数字
 func parseWeekday(weekday string) int {
 	weekdayMap := map[string]int{
@@ -221,6 +369,21 @@ func parseWeekday(weekday string) int {
 	return 1 // 默认周一
 }
 
+// parseOrdinal 解析"第几个"中的序号
+func parseOrdinal(ordinal string) int {
+	ordinalMap := map[string]int{
+		"一": 1, "二": 2, "三": 3, "四": 4, "五": 5,
+	}
+	if n, ok := ordinalMap[ordinal]; ok {
+		return n
+	}
+	n, err := strconv.Atoi(ordinal)
+	if err != nil {
+		return 1
+	}
+	return n
+}
+
 func normalizeHourForPeriod(hour int, period string) int {
 	if hour < 0 || hour > 23 {
 		return hour
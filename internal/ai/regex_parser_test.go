@@ -2,6 +2,7 @@ package ai
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -83,7 +84,7 @@ func TestRegexParser_WorkdayReminder(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "上班", result.Reminder.Title)
 	assert.Equal(t, 9, result.Reminder.Time.Hour)
-	assert.Equal(t, "weekly:1,2,3,4,5", string(result.Reminder.SchedulePattern))
+	assert.Equal(t, "workday", string(result.Reminder.SchedulePattern))
 }
 
 // TestRegexParser_TomorrowReminder 测试明天提醒解析
@@ -130,6 +131,112 @@ func TestRegexParser_SpecificDateReminder(t *testing.T) {
 	assert.Equal(t, "once:2025-10-15", string(result.Reminder.SchedulePattern))
 }
 
+// TestRegexParser_MonthlyAndYearlyReminder 测试每月和每年提醒解析
+func TestRegexParser_MonthlyAndYearlyReminder(t *testing.T) {
+	parser := NewRegexParser()
+	ctx := context.Background()
+
+	tests := []struct {
+		name            string
+		message         string
+		expectedTitle   string
+		expectedHour    int
+		expectedPattern string
+	}{
+		{"每月指定日期", "每月15号早上9点提醒我还信用卡", "还信用卡", 9, "monthly:15"},
+		{"每月多个日期", "每月1号和15号10点提醒我记账", "记账", 10, "monthly:1,15"},
+		{"每月最后一天", "每月最后一天晚上8点提醒我交房租", "交房租", 8, "monthly:L"},
+		{"每月第一个周一", "每月第一个周一上午10点提醒我开例会", "开例会", 10, "monthly:1#1"},
+		{"每月最后一个周五", "每月最后一个星期五下午5点提醒我写周报", "写周报", 5, "monthly:5#L"},
+		{"每年指定日期", "每年3月8日早上9点提醒我给妈妈过生日", "给妈妈过生日", 9, "yearly:03-08"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parser.Parse(ctx, "user1", tt.message)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedTitle, result.Reminder.Title)
+			assert.Equal(t, tt.expectedHour, result.Reminder.Time.Hour)
+			assert.Equal(t, tt.expectedPattern, string(result.Reminder.SchedulePattern))
+			assert.Equal(t, models.ReminderTypeHabit, result.Reminder.Type)
+		})
+	}
+}
+
+// TestRegexParser_MultipleTimes 测试一句话中包含多个时间点
+func TestRegexParser_MultipleTimes(t *testing.T) {
+	parser := NewRegexParser()
+	ctx := context.Background()
+
+	tests := []struct {
+		name            string
+		message         string
+		expectedTitle   string
+		expectedTimes   []string
+		expectedPattern string
+	}{
+		{"每天三次", "每天8点、13点和20点提醒我吃药", "吃药", []string{"08:00", "13:00", "20:00"}, "daily"},
+		{"沿用时段", "每天晚上8点和10点半提醒我关窗", "关窗", []string{"20:00", "22:30"}, "daily"},
+		{"工作日", "工作日早上9点和下午2点提醒我站起来活动", "站起来活动", []string{"09:00", "14:00"}, "workday"},
+		{"单个时间不填充", "每天9点30分提醒我吃药", "吃药", nil, "daily"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parser.Parse(ctx, "user1", tt.message)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedTitle, result.Reminder.Title)
+			assert.Equal(t, tt.expectedTimes, result.Reminder.Time.Times)
+			assert.Equal(t, tt.expectedPattern, string(result.Reminder.SchedulePattern))
+			if len(tt.expectedTimes) > 0 {
+				assert.Equal(t, tt.expectedTimes[0], fmt.Sprintf("%02d:%02d", result.Reminder.Time.Hour, result.Reminder.Time.Minute))
+			}
+		})
+	}
+}
+
+// TestRegexParser_EndCondition 测试重复提醒的结束条件
+func TestRegexParser_EndCondition(t *testing.T) {
+	parser := NewRegexParser()
+	ctx := context.Background()
+
+	result, err := parser.Parse(ctx, "user1", "每天早上7点提醒我冥想，共21次")
+	require.NoError(t, err)
+	assert.Equal(t, "冥想", result.Reminder.Title)
+	assert.Equal(t, 21, result.Reminder.MaxOccurrences)
+	assert.Equal(t, models.SchedulePatternDaily, result.Reminder.SchedulePattern)
+
+	result, err = parser.Parse(ctx, "user1", "每周一早上9点提醒我交周报，直到2099年12月31日")
+	require.NoError(t, err)
+	assert.Equal(t, "交周报", result.Reminder.Title)
+	assert.Equal(t, "2099-12-31", result.Reminder.UntilDate)
+
+	// 一次性提醒不带结束条件
+	result, err = parser.Parse(ctx, "user1", "明天下午2点提醒我取快递")
+	require.NoError(t, err)
+	assert.Empty(t, result.Reminder.UntilDate)
+	assert.Zero(t, result.Reminder.MaxOccurrences)
+}
+
+// TestRegexParser_LeadTimes 测试提前通知
+func TestRegexParser_LeadTimes(t *testing.T) {
+	parser := NewRegexParser()
+	ctx := context.Background()
+
+	result, err := parser.Parse(ctx, "user1", "明天15点看牙医，提前1天和半小时提醒我")
+	require.NoError(t, err)
+	assert.Equal(t, "看牙医", result.Reminder.Title)
+	assert.Equal(t, 15, result.Reminder.Time.Hour)
+	assert.Equal(t, "1d,30m", result.Reminder.LeadTimes)
+
+	result, err = parser.Parse(ctx, "user1", "每天早上9点提醒我开站会，提前10分钟")
+	require.NoError(t, err)
+	assert.Equal(t, "开站会", result.Reminder.Title)
+	assert.Equal(t, "10m", result.Reminder.LeadTimes)
+}
+
 // TestRegexParser_NoMatch 测试无法匹配的消息
 func TestRegexParser_NoMatch(t *testing.T) {
 	parser := NewRegexParser()
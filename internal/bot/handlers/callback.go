@@ -17,6 +17,15 @@ type CallbackHandler struct {
 	reminderService    service.ReminderService
 	reminderLogService service.ReminderLogService
 	schedulerService   service.SchedulerService
+
+	// 例程服务（可选，完成一步后发送下一步）
+	routineService service.RoutineService
+
+	// 发送队列（可选），设置后所有消息经队列限速发送
+	sender service.BotAPI
+
+	// 通知服务（可选），用户响应后更新该次提醒的其他消息
+	notificationService service.NotificationService
 }
 
 func NewCallbackHandler(
@@ -31,6 +40,29 @@ func NewCallbackHandler(
 	}
 }
 
+// SetRoutineService 设置例程服务，完成例程中的一步后自动发送下一步
+func (h *CallbackHandler) SetRoutineService(routineService service.RoutineService) {
+	h.routineService = routineService
+}
+
+// SetSender 设置发送队列，消息按 Telegram 的速率限制排队发送
+func (h *CallbackHandler) SetSender(sender service.BotAPI) {
+	h.sender = sender
+}
+
+// SetNotificationService 设置通知服务，用户响应后原提醒和关怀消息都更新为最终状态
+func (h *CallbackHandler) SetNotificationService(notificationService service.NotificationService) {
+	h.notificationService = notificationService
+}
+
+// send 优先经发送队列发送，未设置时直接调用 Bot
+func (h *CallbackHandler) send(bot *tgbotapi.BotAPI, c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	if h.sender != nil {
+		return h.sender.Send(c)
+	}
+	return bot.Send(c)
+}
+
 func (h *CallbackHandler) HandleCallback(ctx context.Context, bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery) error {
 	// 解析回调数据
 	parts := strings.Split(callback.Data, "_")
@@ -67,6 +99,19 @@ func (h *CallbackHandler) HandleCallback(ctx context.Context, bot *tgbotapi.BotA
 		return h.handleReminderResume(ctx, bot, callback, uint(resourceID))
 	case "edit":
 		return h.handleReminderEdit(ctx, bot, callback, uint(resourceID))
+	case "skipat", "pauseuntil":
+		// 每日日程中的按钮，第四段为 Unix 时间
+		if len(parts) < 4 {
+			return h.sendCallbackResponse(bot, callback.ID, "❌ 缺少时间")
+		}
+		unix, err := strconv.ParseInt(parts[3], 10, 64)
+		if err != nil {
+			return h.sendCallbackResponse(bot, callback.ID, "❌ 无效的时间")
+		}
+		if action == "skipat" {
+			return h.handleSkipOccurrence(ctx, bot, callback, uint(resourceID), time.Unix(unix, 0))
+		}
+		return h.handlePauseUntil(ctx, bot, callback, uint(resourceID), time.Unix(unix, 0))
 	default:
 		return h.sendCallbackResponse(bot, callback.ID, "❌ 未知操作")
 	}
@@ -83,17 +128,37 @@ func (h *CallbackHandler) handleComplete(ctx context.Context, bot *tgbotapi.BotA
 		return h.sendCallbackResponse(bot, callback.ID, "❌ 提醒记录不存在")
 	}
 
-	// 标记为已完成
-	if err := h.reminderLogService.MarkAsCompleted(ctx, logID, "用户确认完成"); err != nil {
+	// 标记为已完成，重复点击时记录已完成，不再安排例程下一步
+	completed, err := h.reminderLogService.MarkAsCompleted(ctx, logID, "用户确认完成")
+	if err != nil {
 		logger.Errorf("标记提醒完成失败: %v", err)
 		return h.sendCallbackResponse(bot, callback.ID, "❌ 操作失败，请稍后重试")
 	}
+	if !completed {
+		return h.sendCallbackResponse(bot, callback.ID, "✅ 已经标记过完成")
+	}
 
 	// 编辑原消息
 	response := fmt.Sprintf("✅ <b>太棒了！</b>\n\n📝 %s\n\n🎉 已记录完成，继续保持！", log.Reminder.Title)
+
+	// 例程中的步骤完成后安排下一步
+	if h.routineService != nil && log.Reminder.IsRoutineStep() {
+		next, err := h.routineService.AdvanceRoutine(ctx, log)
+		switch {
+		case err != nil:
+			logger.Errorf("安排例程下一步失败 (LogID: %d): %v", logID, err)
+		case next == nil:
+			response += "\n\n🏁 例程全部完成！"
+		case next.RoutineDelay > 0:
+			response += fmt.Sprintf("\n\n➡️ %d 分钟后提醒下一步：%s", next.RoutineDelay, next.Title)
+		default:
+			response += fmt.Sprintf("\n\n➡️ 下一步：%s", next.Title)
+		}
+	}
 	if err := h.editMessage(bot, callback.Message, response); err != nil {
 		logger.Errorf("编辑消息失败: %v", err)
 	}
+	h.closeMessages(ctx, logID, callback.Message, response)
 
 	// 发送回调响应
 	return h.sendCallbackResponse(bot, callback.ID, "✅ 已标记为完成")
@@ -123,6 +188,7 @@ func (h *CallbackHandler) handleDelay(ctx context.Context, bot *tgbotapi.BotAPI,
 	if err := h.editMessage(bot, callback.Message, response); err != nil {
 		logger.Errorf("编辑消息失败: %v", err)
 	}
+	h.closeMessages(ctx, logID, callback.Message, response)
 
 	// 发送回调响应
 	return h.sendCallbackResponse(bot, callback.ID, fmt.Sprintf("⏰ 已延期%d小时", hours))
@@ -150,6 +216,7 @@ func (h *CallbackHandler) handleSkip(ctx context.Context, bot *tgbotapi.BotAPI,
 	if err := h.editMessage(bot, callback.Message, response); err != nil {
 		logger.Errorf("编辑消息失败: %v", err)
 	}
+	h.closeMessages(ctx, logID, callback.Message, response)
 
 	// 发送回调响应
 	return h.sendCallbackResponse(bot, callback.ID, "😴 已跳过")
@@ -168,7 +235,7 @@ func (h *CallbackHandler) handleReminderDelete(ctx context.Context, bot *tgbotap
 	if callback.Message != nil {
 		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, fmt.Sprintf("✅ 已删除提醒 #%d", reminderID))
 		msg.ParseMode = tgbotapi.ModeHTML
-		if _, err := bot.Send(msg); err != nil {
+		if _, err := h.send(bot, msg); err != nil {
 			logger.Warnf("发送删除提示失败: %v", err)
 		}
 	}
@@ -195,9 +262,9 @@ func (h *CallbackHandler) handleReminderPause(ctx context.Context, bot *tgbotapi
 
 	if callback.Message != nil && reminder != nil {
 		msg := tgbotapi.NewMessage(callback.Message.Chat.ID,
-			fmt.Sprintf("⏸️ 已暂停提醒 #%d\n📝 %s\n⏳ 暂停至 %s", reminderID, reminder.Title, until))
+			fmt.Sprintf("⏸️ 已暂停提醒 #%d\n📝 %s\n⏳ 暂停至 %s，到期后自动恢复", reminderID, reminder.Title, until))
 		msg.ParseMode = tgbotapi.ModeHTML
-		if _, err := bot.Send(msg); err != nil {
+		if _, err := h.send(bot, msg); err != nil {
 			logger.Warnf("发送暂停提示失败: %v", err)
 		}
 	}
@@ -218,9 +285,9 @@ func (h *CallbackHandler) handleReminderResume(ctx context.Context, bot *tgbotap
 	reminder, _ := h.reminderService.GetReminderByID(ctx, reminderID)
 	if callback.Message != nil && reminder != nil {
 		msg := tgbotapi.NewMessage(callback.Message.Chat.ID,
-			fmt.Sprintf("▶️ 已恢复提醒 #%d\n📝 %s\n⏰ %s", reminderID, reminder.Title, reminder.TargetTime[:5]))
+			fmt.Sprintf("▶️ 已恢复提醒 #%d\n📝 %s\n⏰ %s", reminderID, reminder.Title, formatTimes(reminder)))
 		msg.ParseMode = tgbotapi.ModeHTML
-		if _, err := bot.Send(msg); err != nil {
+		if _, err := h.send(bot, msg); err != nil {
 			logger.Warnf("发送恢复提示失败: %v", err)
 		}
 	}
@@ -260,7 +327,7 @@ func (h *CallbackHandler) handleReminderEdit(ctx context.Context, bot *tgbotapi.
 💡 AI会智能理解你的编辑意图`,
 		reminderID,
 		reminder.Title,
-		reminder.TargetTime[:5],
+		formatTimes(reminder),
 		reminder.SchedulePattern,
 		reminder.Title,
 		reminder.Title,
@@ -270,7 +337,7 @@ func (h *CallbackHandler) handleReminderEdit(ctx context.Context, bot *tgbotapi.
 	if callback.Message != nil {
 		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, editText)
 		msg.ParseMode = tgbotapi.ModeHTML
-		if _, err := bot.Send(msg); err != nil {
+		if _, err := h.send(bot, msg); err != nil {
 			logger.Warnf("发送编辑提示失败: %v", err)
 		}
 	}
@@ -278,6 +345,95 @@ func (h *CallbackHandler) handleReminderEdit(ctx context.Context, bot *tgbotapi.
 	return h.sendCallbackResponse(bot, callback.ID, "📝 请通过文字描述你的修改")
 }
 
+// handleSkipOccurrence 跳过日程中的某一次提醒，并移除对应按钮
+func (h *CallbackHandler) handleSkipOccurrence(ctx context.Context, bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery, reminderID uint, occurrence time.Time) error {
+	if err := h.schedulerService.SkipOccurrence(ctx, reminderID, occurrence); err != nil {
+		logger.Warnf("跳过单次提醒失败 (ID: %d, 时间: %s): %v", reminderID, occurrence.Format(time.RFC3339), err)
+		return h.sendCallbackResponse(bot, callback.ID, fmt.Sprintf("❌ %v", err))
+	}
+
+	h.removeButtons(bot, callback.Message, func(data string) bool { return data == callback.Data })
+	return h.sendCallbackResponse(bot, callback.ID, "⏭️ 已跳过这次提醒")
+}
+
+// handlePauseUntil 将提醒暂停到日程当天结束，并移除该提醒在日程中的全部按钮
+func (h *CallbackHandler) handlePauseUntil(ctx context.Context, bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery, reminderID uint, until time.Time) error {
+	duration := time.Until(until)
+	if duration <= 0 {
+		h.removeButtons(bot, callback.Message, func(string) bool { return true })
+		return h.sendCallbackResponse(bot, callback.ID, "❌ 这份日程已过期")
+	}
+
+	reminder, err := h.reminderService.GetReminderByID(ctx, reminderID)
+	if err != nil || reminder == nil {
+		return h.sendCallbackResponse(bot, callback.ID, "❌ 提醒不存在")
+	}
+
+	// 已暂停到更晚的时间时保持原样；到期时正值午夜，静默恢复，不发送恢复通知
+	if reminder.PausedUntil == nil || reminder.PausedUntil.Before(until) {
+		if err := h.reminderService.PauseReminderQuietly(ctx, reminderID, duration, "在每日日程中暂停到明天"); err != nil {
+			logger.Errorf("日程中暂停提醒失败 (ID: %d): %v", reminderID, err)
+			return h.sendCallbackResponse(bot, callback.ID, "❌ 暂停失败，请稍后重试")
+		}
+	}
+
+	skipPrefix := fmt.Sprintf("reminder_skipat_%d_", reminderID)
+	pausePrefix := fmt.Sprintf("reminder_pauseuntil_%d_", reminderID)
+	h.removeButtons(bot, callback.Message, func(data string) bool {
+		return strings.HasPrefix(data, skipPrefix) || strings.HasPrefix(data, pausePrefix)
+	})
+	return h.sendCallbackResponse(bot, callback.ID, fmt.Sprintf("⏸️ %s 今天不再提醒", reminder.Title))
+}
+
+// removeButtons 从消息的内联键盘中移除回调数据满足 match 的按钮，空行一并移除
+func (h *CallbackHandler) removeButtons(bot *tgbotapi.BotAPI, message *tgbotapi.Message, match func(data string) bool) {
+	if message == nil || message.ReplyMarkup == nil {
+		return
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	removed := false
+	for _, row := range message.ReplyMarkup.InlineKeyboard {
+		var kept []tgbotapi.InlineKeyboardButton
+		for _, button := range row {
+			if button.CallbackData != nil && match(*button.CallbackData) {
+				removed = true
+				continue
+			}
+			kept = append(kept, button)
+		}
+		if len(kept) > 0 {
+			rows = append(rows, kept)
+		}
+	}
+	if !removed {
+		return
+	}
+
+	edit := tgbotapi.NewEditMessageReplyMarkup(message.Chat.ID, message.MessageID, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows})
+	if rows == nil {
+		edit.ReplyMarkup = nil
+	}
+	if _, err := h.send(bot, edit); err != nil {
+		logger.Warnf("更新日程按钮失败: %v", err)
+	}
+}
+
+// closeMessages 将该次提醒的原消息和关怀消息更新为与用户点击的消息相同的结果，并移除按钮
+func (h *CallbackHandler) closeMessages(ctx context.Context, logID uint, clicked *tgbotapi.Message, text string) {
+	if h.notificationService == nil {
+		return
+	}
+
+	skip := 0
+	if clicked != nil {
+		skip = clicked.MessageID
+	}
+	if err := h.notificationService.CloseMessages(ctx, logID, text, skip); err != nil {
+		logger.Warnf("更新提醒相关消息失败 (LogID: %d): %v", logID, err)
+	}
+}
+
 func (h *CallbackHandler) sendCallbackResponse(bot *tgbotapi.BotAPI, callbackID, text string) error {
 	callback := tgbotapi.NewCallback(callbackID, text)
 	_, err := bot.Request(callback)
@@ -289,6 +445,6 @@ func (h *CallbackHandler) editMessage(bot *tgbotapi.BotAPI, message *tgbotapi.Me
 	edit.ParseMode = tgbotapi.ModeHTML
 	edit.ReplyMarkup = nil // 移除键盘
 
-	_, err := bot.Send(edit)
+	_, err := h.send(bot, edit)
 	return err
 }
@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"net/mail"
 	"sort"
 	"strconv"
 	"strings"
@@ -14,6 +15,7 @@ import (
 	"mmemory/internal/service"
 	"mmemory/pkg/ai"
 	"mmemory/pkg/logger"
+	"mmemory/pkg/rrule"
 	"mmemory/pkg/version"
 )
 
@@ -25,6 +27,15 @@ type MessageHandler struct {
 	// AI服务（可选，用于智能解析和对话）
 	aiParserService     service.AIParserService
 	conversationService service.ConversationService
+
+	// 调度服务（可选，用于展示下次触发时间）
+	schedulerService service.SchedulerService
+
+	// 例程服务（可选，用于 /routine 命令）
+	routineService service.RoutineService
+
+	// 发送队列（可选），设置后所有消息经队列限速发送
+	sender service.BotAPI
 }
 
 func NewMessageHandler(
@@ -43,6 +54,29 @@ func NewMessageHandler(
 	}
 }
 
+// SetScheduler 设置调度服务，用于在列表和创建确认中展示下次触发时间
+func (h *MessageHandler) SetScheduler(scheduler service.SchedulerService) {
+	h.schedulerService = scheduler
+}
+
+// SetRoutineService 设置例程服务，启用 /routine 命令
+func (h *MessageHandler) SetRoutineService(routineService service.RoutineService) {
+	h.routineService = routineService
+}
+
+// SetSender 设置发送队列，消息按 Telegram 的速率限制排队发送
+func (h *MessageHandler) SetSender(sender service.BotAPI) {
+	h.sender = sender
+}
+
+// send 优先经发送队列发送，未设置时直接调用 Bot
+func (h *MessageHandler) send(bot *tgbotapi.BotAPI, c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	if h.sender != nil {
+		return h.sender.Send(c)
+	}
+	return bot.Send(c)
+}
+
 func (h *MessageHandler) HandleMessage(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message) error {
 	// 确保用户存在
 	user, err := h.ensureUser(ctx, message.From)
@@ -73,6 +107,24 @@ func (h *MessageHandler) handleCommand(ctx context.Context, bot *tgbotapi.BotAPI
 		return h.handleDeleteCommand(ctx, bot, message, user)
 	case "version":
 		return h.handleVersionCommand(bot, message)
+	case "timezone":
+		return h.handleTimezoneCommand(ctx, bot, message, user)
+	case "rrule":
+		return h.handleRRuleCommand(ctx, bot, message, user)
+	case "next":
+		return h.handleNextCommand(ctx, bot, message, user)
+	case "followup":
+		return h.handleFollowUpCommand(ctx, bot, message, user)
+	case "quiet":
+		return h.handleQuietCommand(ctx, bot, message, user)
+	case "lead":
+		return h.handleLeadCommand(ctx, bot, message, user)
+	case "channel":
+		return h.handleChannelCommand(ctx, bot, message, user)
+	case "agenda":
+		return h.handleAgendaCommand(ctx, bot, message, user)
+	case "routine":
+		return h.handleRoutineCommand(ctx, bot, message, user)
 	default:
 		return h.sendMessage(bot, message.Chat.ID, "未知命令，请输入 /help 查看帮助")
 	}
@@ -105,12 +157,21 @@ func (h *MessageHandler) handleHelpCommand(bot *tgbotapi.BotAPI, message *tgbota
 
 🔹 管理提醒：
 • /list - 查看我的提醒列表
+• /next - 查看未来24小时内的提醒
 • 回复提醒时可选择：完成/延期/跳过
 
 🔹 其他命令：
 • /start - 重新开始
 • /help - 查看帮助
 • /stats - 查看统计数据
+• /timezone - 查看或设置时区
+• /followup - 设置未回复时的关怀策略
+• /quiet - 设置免打扰时段
+• /lead - 设置提醒的提前通知
+• /channel - 设置通知渠道（Telegram、邮件、Webhook）
+• /agenda - 每天早上推送今日日程
+• /routine - 将多个提醒组成按顺序进行的例程
+• /rrule - 使用 RRULE 自定义重复规则（高级）
 • /version - 查看版本信息
 
 💡 直接发送文字消息即可创建提醒，我会智能识别你的需求！`
@@ -142,6 +203,706 @@ func (h *MessageHandler) handleVersionCommand(bot *tgbotapi.BotAPI, message *tgb
 	return h.sendMessage(bot, message.Chat.ID, versionText)
 }
 
+func (h *MessageHandler) handleTimezoneCommand(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message, user *models.User) error {
+	args := strings.TrimSpace(message.CommandArguments())
+	if args == "" {
+		current := user.Timezone
+		if current == "" {
+			current = ai.DefaultTimezone
+		}
+		return h.sendMessage(bot, message.Chat.ID,
+			fmt.Sprintf("🌏 当前时区：<b>%s</b>\n\n"+
+				"用法：/timezone <时区>\n"+
+				"示例：/timezone Europe/Berlin", current))
+	}
+
+	loc, err := time.LoadLocation(args)
+	if err != nil {
+		return h.sendMessage(bot, message.Chat.ID, "❌ 无效的时区，请使用 IANA 时区名称，例如 Europe/Berlin、Asia/Shanghai")
+	}
+
+	oldTimezone := user.Timezone
+	user.Timezone = loc.String()
+	if err := h.userService.UpdateUser(ctx, user); err != nil {
+		logger.Errorf("更新用户时区失败: %v", err)
+		return h.sendErrorMessage(bot, message.Chat.ID, "更新时区失败，请稍后重试")
+	}
+
+	// 使用旧时区的提醒一并迁移到新时区，并重新调度
+	reminders, err := h.reminderService.GetUserReminders(ctx, user.ID)
+	if err != nil {
+		logger.Errorf("获取用户提醒失败: %v", err)
+	}
+	moved := 0
+	for _, reminder := range followTimezoneChange(reminders, oldTimezone, user.Timezone) {
+		if err := h.reminderService.UpdateReminder(ctx, reminder); err != nil {
+			logger.Errorf("更新提醒时区失败 (ID: %d): %v", reminder.ID, err)
+			continue
+		}
+		moved++
+	}
+
+	// 每日日程按新时区的本地时间推送
+	if user.AgendaTime != "" && h.schedulerService != nil {
+		h.schedulerService.ScheduleAgenda(user)
+	}
+
+	return h.sendMessage(bot, message.Chat.ID,
+		fmt.Sprintf("✅ 时区已设置为 <b>%s</b>\n\n🔄 已同步 %d 个提醒，将按新时区的本地时间提醒你", user.Timezone, moved))
+}
+
+// followTimezoneChange 返回用户时区变更后需要重新调度的提醒
+// 显式设置为旧时区的提醒改为新时区；未设置时区的提醒保持为空，继续跟随用户时区，以后再改时区仍会生效
+func followTimezoneChange(reminders []*models.Reminder, oldTimezone, newTimezone string) []*models.Reminder {
+	if oldTimezone == "" {
+		oldTimezone = ai.DefaultTimezone
+	}
+
+	var changed []*models.Reminder
+	for _, reminder := range reminders {
+		switch reminder.Timezone {
+		case "":
+			reminder.User.Timezone = newTimezone
+		case oldTimezone:
+			reminder.Timezone = newTimezone
+		default:
+			continue
+		}
+		changed = append(changed, reminder)
+	}
+	return changed
+}
+
+func (h *MessageHandler) handleFollowUpCommand(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message, user *models.User) error {
+	usage := "用法：\n" +
+		"/followup &lt;策略&gt; - 设置我的默认关怀策略\n" +
+		"/followup &lt;提醒ID&gt; &lt;策略|default&gt; - 单独设置某个提醒，default 表示沿用默认\n\n" +
+		"策略格式：间隔[,间隔...][;max=次数][;then=overdue|skipped]，off 表示不再关怀\n" +
+		"示例：/followup 15m,1h,3h;max=5;then=skipped\n" +
+		"示例：/followup 3 off"
+
+	fields := strings.Fields(message.CommandArguments())
+	if len(fields) == 0 {
+		current := user.FollowUpPolicy
+		if current == "" {
+			current = "系统默认"
+		}
+		return h.sendMessage(bot, message.Chat.ID,
+			fmt.Sprintf("💌 当前默认关怀策略：<b>%s</b>\n\n%s", current, usage))
+	}
+
+	reminderID, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		// 未指定提醒ID时设置用户默认策略
+		policy, err := models.ParseFollowUpPolicy(strings.Join(fields, ";"))
+		if err != nil {
+			return h.sendMessage(bot, message.Chat.ID, fmt.Sprintf("❌ %v\n\n%s", err, usage))
+		}
+
+		user.FollowUpPolicy = policy.String()
+		if err := h.userService.UpdateUser(ctx, user); err != nil {
+			logger.Errorf("更新用户关怀策略失败: %v", err)
+			return h.sendErrorMessage(bot, message.Chat.ID, "更新关怀策略失败，请稍后重试")
+		}
+		return h.sendMessage(bot, message.Chat.ID,
+			fmt.Sprintf("✅ 默认关怀策略已设置为 <b>%s</b>\n\n未单独设置的提醒都将按此策略关怀", user.FollowUpPolicy))
+	}
+
+	if len(fields) < 2 {
+		return h.sendMessage(bot, message.Chat.ID, "❓ 请指定关怀策略\n\n"+usage)
+	}
+
+	reminder, err := h.reminderService.GetReminderByID(ctx, uint(reminderID))
+	if err != nil {
+		logger.Errorf("获取提醒失败: %v", err)
+		return h.sendErrorMessage(bot, message.Chat.ID, "获取提醒失败，请稍后再试")
+	}
+	if reminder == nil {
+		return h.sendMessage(bot, message.Chat.ID, fmt.Sprintf("❌ 未找到ID为 %d 的提醒", reminderID))
+	}
+	if reminder.UserID != user.ID {
+		return h.sendMessage(bot, message.Chat.ID, "❌ 你没有权限修改此提醒")
+	}
+
+	spec := strings.Join(fields[1:], ";")
+	if strings.EqualFold(spec, "default") {
+		reminder.FollowUpPolicy = ""
+	} else {
+		policy, err := models.ParseFollowUpPolicy(spec)
+		if err != nil {
+			return h.sendMessage(bot, message.Chat.ID, fmt.Sprintf("❌ %v\n\n%s", err, usage))
+		}
+		reminder.FollowUpPolicy = policy.String()
+	}
+
+	if err := h.reminderService.UpdateReminder(ctx, reminder); err != nil {
+		logger.Errorf("更新提醒关怀策略失败 (ID: %d): %v", reminder.ID, err)
+		return h.sendErrorMessage(bot, message.Chat.ID, "更新关怀策略失败，请稍后重试")
+	}
+
+	current := reminder.FollowUpPolicy
+	if current == "" {
+		current = "沿用默认"
+	}
+	return h.sendMessage(bot, message.Chat.ID,
+		fmt.Sprintf("✅ 已更新关怀策略\n\n📝 %s\n💌 %s", reminder.Title, current))
+}
+
+func (h *MessageHandler) handleChannelCommand(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message, user *models.User) error {
+	usage := "用法：\n" +
+		"/channel use &lt;渠道[,渠道...]&gt; - 设置我的通知渠道，按顺序尝试，前一个失败时使用下一个\n" +
+		"/channel use default - 恢复系统默认\n" +
+		"/channel email &lt;邮箱|off&gt; - 设置邮件渠道的收件地址\n" +
+		"/channel webhook &lt;地址|off&gt; - 设置 Webhook 渠道的回调地址\n" +
+		"/channel &lt;提醒ID&gt; &lt;渠道[,渠道...]|default&gt; - 单独设置某个提醒\n\n" +
+		"渠道：telegram、email、webhook\n" +
+		"示例：/channel use email,telegram\n" +
+		"示例：/channel 3 webhook,telegram"
+
+	fields := strings.Fields(message.CommandArguments())
+	if len(fields) == 0 {
+		current := user.Channels
+		if current == "" {
+			current = "系统默认"
+		}
+		email, webhook := user.Email, user.WebhookURL
+		if email == "" {
+			email = "未设置"
+		}
+		if webhook == "" {
+			webhook = "未设置"
+		}
+		return h.sendMessage(bot, message.Chat.ID,
+			fmt.Sprintf("📮 当前通知渠道：<b>%s</b>\n📧 邮箱：%s\n🔗 Webhook：%s\n\n%s", current, email, webhook, usage))
+	}
+	if len(fields) < 2 {
+		return h.sendMessage(bot, message.Chat.ID, usage)
+	}
+
+	value := strings.Join(fields[1:], ",")
+	switch strings.ToLower(fields[0]) {
+	case "use":
+		if strings.EqualFold(value, "default") {
+			user.Channels = ""
+		} else {
+			channels, err := models.ParseChannels(value)
+			if err != nil {
+				return h.sendMessage(bot, message.Chat.ID, fmt.Sprintf("❌ %v\n\n%s", err, usage))
+			}
+			user.Channels = strings.Join(channels, ",")
+		}
+	case models.ChannelEmail:
+		if strings.EqualFold(fields[1], "off") {
+			user.Email = ""
+		} else {
+			address, err := mail.ParseAddress(fields[1])
+			if err != nil {
+				return h.sendMessage(bot, message.Chat.ID, fmt.Sprintf("❌ 无效的邮箱地址：%s", fields[1]))
+			}
+			user.Email = address.Address
+		}
+	case models.ChannelWebhook:
+		if strings.EqualFold(fields[1], "off") {
+			user.WebhookURL = ""
+		} else {
+			if err := service.ValidateWebhookURL(fields[1]); err != nil {
+				return h.sendMessage(bot, message.Chat.ID, fmt.Sprintf("❌ %v", err))
+			}
+			user.WebhookURL = fields[1]
+		}
+	default:
+		return h.handleReminderChannel(ctx, bot, message, user, fields[0], value, usage)
+	}
+
+	if err := h.userService.UpdateUser(ctx, user); err != nil {
+		logger.Errorf("更新用户通知渠道失败: %v", err)
+		return h.sendErrorMessage(bot, message.Chat.ID, "更新通知渠道失败，请稍后重试")
+	}
+	return h.sendMessage(bot, message.Chat.ID, "✅ 通知渠道设置已更新")
+}
+
+// handleReminderChannel 单独设置某个提醒的通知渠道
+func (h *MessageHandler) handleReminderChannel(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message, user *models.User, idText, value, usage string) error {
+	reminderID, err := strconv.ParseUint(idText, 10, 64)
+	if err != nil {
+		return h.sendMessage(bot, message.Chat.ID, "❓ 无法识别的设置项\n\n"+usage)
+	}
+
+	reminder, err := h.reminderService.GetReminderByID(ctx, uint(reminderID))
+	if err != nil {
+		logger.Errorf("获取提醒失败: %v", err)
+		return h.sendErrorMessage(bot, message.Chat.ID, "获取提醒失败，请稍后再试")
+	}
+	if reminder == nil {
+		return h.sendMessage(bot, message.Chat.ID, fmt.Sprintf("❌ 未找到ID为 %d 的提醒", reminderID))
+	}
+	if reminder.UserID != user.ID {
+		return h.sendMessage(bot, message.Chat.ID, "❌ 你没有权限修改此提醒")
+	}
+
+	if strings.EqualFold(value, "default") {
+		reminder.Channels = ""
+	} else {
+		channels, err := models.ParseChannels(value)
+		if err != nil {
+			return h.sendMessage(bot, message.Chat.ID, fmt.Sprintf("❌ %v\n\n%s", err, usage))
+		}
+		reminder.Channels = strings.Join(channels, ",")
+	}
+
+	if err := h.reminderService.UpdateReminder(ctx, reminder); err != nil {
+		logger.Errorf("更新提醒通知渠道失败 (ID: %d): %v", reminder.ID, err)
+		return h.sendErrorMessage(bot, message.Chat.ID, "更新通知渠道失败，请稍后重试")
+	}
+
+	current := reminder.Channels
+	if current == "" {
+		current = "沿用默认"
+	}
+	return h.sendMessage(bot, message.Chat.ID,
+		fmt.Sprintf("✅ 已更新通知渠道\n\n📝 %s\n📮 %s", reminder.Title, current))
+}
+
+// handleLeadCommand 设置提醒的提前通知，提前通知只作提示，不计入完成统计
+func (h *MessageHandler) handleLeadCommand(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message, user *models.User) error {
+	usage := "用法：\n" +
+		"/lead &lt;提醒ID&gt; &lt;提前量[,提前量...]&gt; - 在计划时间之前发送提前通知\n" +
+		"/lead &lt;提醒ID&gt; off - 取消提前通知\n\n" +
+		"提前量单位：m 分钟、h 小时、d 天，最多5个\n" +
+		"示例：/lead 3 1d,1h,10m\n" +
+		"也可以在创建时说：明天下午3点看牙医，提前半小时提醒我"
+
+	fields := strings.Fields(message.CommandArguments())
+	if len(fields) < 2 {
+		return h.sendMessage(bot, message.Chat.ID, usage)
+	}
+
+	reminderID, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return h.sendMessage(bot, message.Chat.ID, "❌ 无效的提醒ID\n\n"+usage)
+	}
+
+	offsets, err := models.ParseLeadTimes(strings.Join(fields[1:], ","))
+	if err != nil {
+		return h.sendMessage(bot, message.Chat.ID, fmt.Sprintf("❌ %v\n\n%s", err, usage))
+	}
+
+	reminder, err := h.reminderService.GetReminderByID(ctx, uint(reminderID))
+	if err != nil {
+		logger.Errorf("获取提醒失败: %v", err)
+		return h.sendErrorMessage(bot, message.Chat.ID, "获取提醒失败，请稍后再试")
+	}
+	if reminder == nil {
+		return h.sendMessage(bot, message.Chat.ID, fmt.Sprintf("❌ 未找到ID为 %d 的提醒", reminderID))
+	}
+	if reminder.UserID != user.ID {
+		return h.sendMessage(bot, message.Chat.ID, "❌ 你没有权限修改此提醒")
+	}
+
+	reminder.LeadTimes = models.FormatLeadTimes(offsets)
+	if err := h.reminderService.UpdateReminder(ctx, reminder); err != nil {
+		logger.Errorf("更新提前通知失败 (ID: %d): %v", reminder.ID, err)
+		return h.sendErrorMessage(bot, message.Chat.ID, "更新提前通知失败，请稍后重试")
+	}
+
+	if lead := formatLeadTimes(reminder); lead != "" {
+		return h.sendMessage(bot, message.Chat.ID, fmt.Sprintf("✅ 提醒 <b>%s</b> 将%s", reminder.Title, lead))
+	}
+	return h.sendMessage(bot, message.Chat.ID, fmt.Sprintf("✅ 已取消提醒 <b>%s</b> 的提前通知", reminder.Title))
+}
+
+func (h *MessageHandler) handleAgendaCommand(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message, user *models.User) error {
+	usage := "用法：\n" +
+		"/agenda HH:MM - 每天在该时间（按你的时区）推送今日日程\n" +
+		"/agenda off - 关闭每日日程\n\n" +
+		"日程列出当天待提醒的事项和昨天未完成的提醒，可直接跳过某一次或暂停到明天\n" +
+		"示例：/agenda 07:30"
+
+	args := strings.TrimSpace(message.CommandArguments())
+	if args == "" {
+		current := "未开启"
+		if user.AgendaTime != "" {
+			current = fmt.Sprintf("每天 %s（%s）", user.AgendaTime, user.Timezone)
+		}
+		return h.sendMessage(bot, message.Chat.ID,
+			fmt.Sprintf("☀️ 每日日程：<b>%s</b>\n\n%s", current, usage))
+	}
+
+	agendaTime, err := models.ParseAgendaTime(args)
+	if err != nil {
+		return h.sendMessage(bot, message.Chat.ID, fmt.Sprintf("❌ %v\n\n%s", err, usage))
+	}
+
+	user.AgendaTime = agendaTime
+	if err := h.userService.UpdateUser(ctx, user); err != nil {
+		logger.Errorf("更新用户日程推送时间失败: %v", err)
+		return h.sendErrorMessage(bot, message.Chat.ID, "更新每日日程失败，请稍后重试")
+	}
+	if h.schedulerService != nil {
+		h.schedulerService.ScheduleAgenda(user)
+	}
+
+	if user.AgendaTime == "" {
+		return h.sendMessage(bot, message.Chat.ID, "✅ 已关闭每日日程")
+	}
+	return h.sendMessage(bot, message.Chat.ID,
+		fmt.Sprintf("✅ 将在每天 <b>%s</b>（%s）推送今日日程\n\n当天没有待提醒事项且昨天没有未完成的提醒时不会打扰你", user.AgendaTime, user.Timezone))
+}
+
+func (h *MessageHandler) handleQuietCommand(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message, user *models.User) error {
+	usage := "用法：\n" +
+		"/quiet &lt;时段&gt; - 设置免打扰时段（按你的时区）\n" +
+		"/quiet off - 关闭免打扰\n" +
+		"/quiet &lt;提醒ID&gt; critical|normal - 标记重要提醒，重要提醒不受免打扰限制\n\n" +
+		"时段格式：HH:MM-HH:MM[;weekend=HH:MM-HH:MM][;followups=drop|defer]\n" +
+		"时段内的提醒推迟到时段结束后发送，关怀消息默认不发送（drop），也可推迟（defer）\n" +
+		"示例：/quiet 22:30-07:30;weekend=23:30-09:30\n" +
+		"示例：/quiet 3 critical"
+
+	fields := strings.Fields(message.CommandArguments())
+	if len(fields) == 0 {
+		current := "未设置"
+		if hours, err := models.ParseQuietHours(user.QuietHours); err == nil && hours.Enabled() {
+			current = hours.String()
+		}
+		return h.sendMessage(bot, message.Chat.ID,
+			fmt.Sprintf("🌙 当前免打扰时段：<b>%s</b>\n\n%s", current, usage))
+	}
+
+	reminderID, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		hours, err := models.ParseQuietHours(strings.Join(fields, ";"))
+		if err != nil {
+			return h.sendMessage(bot, message.Chat.ID, fmt.Sprintf("❌ %v\n\n%s", err, usage))
+		}
+
+		user.QuietHours = ""
+		if hours.Enabled() {
+			user.QuietHours = hours.String()
+		}
+		if err := h.userService.UpdateUser(ctx, user); err != nil {
+			logger.Errorf("更新用户免打扰时段失败: %v", err)
+			return h.sendErrorMessage(bot, message.Chat.ID, "更新免打扰时段失败，请稍后重试")
+		}
+
+		if user.QuietHours == "" {
+			return h.sendMessage(bot, message.Chat.ID, "✅ 已关闭免打扰，提醒将按时发送")
+		}
+		return h.sendMessage(bot, message.Chat.ID,
+			fmt.Sprintf("✅ 免打扰时段已设置为 <b>%s</b>（%s）\n\n时段内的提醒会在结束后发送，标记为重要的提醒不受影响", user.QuietHours, user.Timezone))
+	}
+
+	if len(fields) < 2 {
+		return h.sendMessage(bot, message.Chat.ID, "❓ 请指定 critical 或 normal\n\n"+usage)
+	}
+
+	var critical bool
+	switch strings.ToLower(fields[1]) {
+	case "critical":
+		critical = true
+	case "normal":
+		critical = false
+	default:
+		return h.sendMessage(bot, message.Chat.ID, "❓ 请指定 critical 或 normal\n\n"+usage)
+	}
+
+	reminder, err := h.reminderService.GetReminderByID(ctx, uint(reminderID))
+	if err != nil {
+		logger.Errorf("获取提醒失败: %v", err)
+		return h.sendErrorMessage(bot, message.Chat.ID, "获取提醒失败，请稍后再试")
+	}
+	if reminder == nil {
+		return h.sendMessage(bot, message.Chat.ID, fmt.Sprintf("❌ 未找到ID为 %d 的提醒", reminderID))
+	}
+	if reminder.UserID != user.ID {
+		return h.sendMessage(bot, message.Chat.ID, "❌ 你没有权限修改此提醒")
+	}
+
+	reminder.Critical = critical
+	if err := h.reminderService.UpdateReminder(ctx, reminder); err != nil {
+		logger.Errorf("更新提醒重要标记失败 (ID: %d): %v", reminder.ID, err)
+		return h.sendErrorMessage(bot, message.Chat.ID, "更新提醒失败，请稍后重试")
+	}
+
+	if critical {
+		return h.sendMessage(bot, message.Chat.ID,
+			fmt.Sprintf("❗ 已标记为重要提醒，免打扰时段内也会按时发送\n\n📝 %s", reminder.Title))
+	}
+	return h.sendMessage(bot, message.Chat.ID,
+		fmt.Sprintf("✅ 已取消重要标记，将遵循免打扰时段\n\n📝 %s", reminder.Title))
+}
+
+func (h *MessageHandler) handleRoutineCommand(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message, user *models.User) error {
+	if h.routineService == nil {
+		return h.sendMessage(bot, message.Chat.ID, "例程功能暂不可用")
+	}
+
+	usage := "用法：\n" +
+		"/routine - 查看我的例程\n" +
+		"/routine new &lt;名称&gt; &lt;提醒ID,提醒ID,...&gt; [间隔] - 按顺序组成例程\n" +
+		"/routine delete &lt;例程ID&gt; - 删除例程，提醒恢复为独立提醒\n\n" +
+		"第一步按原时间提醒，之后每完成一步再提醒下一步，间隔如 10m 表示完成后10分钟再提醒\n" +
+		"示例：/routine new 晨间 3,5,7 10m"
+
+	fields := strings.Fields(message.CommandArguments())
+	if len(fields) == 0 {
+		routines, err := h.routineService.GetUserRoutines(ctx, user.ID)
+		if err != nil {
+			logger.Errorf("获取用户例程失败: %v", err)
+			return h.sendErrorMessage(bot, message.Chat.ID, "获取例程失败，请稍后重试")
+		}
+		if len(routines) == 0 {
+			return h.sendMessage(bot, message.Chat.ID, "🔗 你还没有例程\n\n"+usage)
+		}
+
+		var builder strings.Builder
+		builder.WriteString("🔗 <b>我的例程</b>\n\n")
+		for _, routine := range routines {
+			titles := make([]string, 0, len(routine.Steps))
+			for _, step := range routine.OrderedSteps() {
+				titles = append(titles, step.Title)
+			}
+			builder.WriteString(fmt.Sprintf("#%d <b>%s</b>\n%s\n\n", routine.ID, routine.Name, strings.Join(titles, " → ")))
+		}
+		builder.WriteString(usage)
+		return h.sendMessage(bot, message.Chat.ID, builder.String())
+	}
+
+	switch strings.ToLower(fields[0]) {
+	case "new":
+		if len(fields) < 3 {
+			return h.sendMessage(bot, message.Chat.ID, "❓ 请指定例程名称和提醒ID\n\n"+usage)
+		}
+
+		var reminderIDs []uint
+		for _, item := range strings.Split(fields[2], ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(item), 10, 64)
+			if err != nil {
+				return h.sendMessage(bot, message.Chat.ID, fmt.Sprintf("❌ 无效的提醒ID: %s\n\n%s", item, usage))
+			}
+			reminderIDs = append(reminderIDs, uint(id))
+		}
+
+		var delay time.Duration
+		if len(fields) > 3 {
+			parsed, err := time.ParseDuration(fields[3])
+			if err != nil {
+				return h.sendMessage(bot, message.Chat.ID, fmt.Sprintf("❌ 无效的间隔: %s\n\n%s", fields[3], usage))
+			}
+			delay = parsed
+		}
+
+		routine, err := h.routineService.CreateRoutine(ctx, user.ID, fields[1], reminderIDs, delay)
+		if err != nil {
+			return h.sendMessage(bot, message.Chat.ID, fmt.Sprintf("❌ %v\n\n%s", err, usage))
+		}
+
+		titles := make([]string, 0, len(routine.Steps))
+		for _, step := range routine.OrderedSteps() {
+			titles = append(titles, step.Title)
+		}
+		return h.sendMessage(bot, message.Chat.ID,
+			fmt.Sprintf("✅ 例程 #%d <b>%s</b> 已创建\n\n%s\n\n完成一步后会自动提醒下一步", routine.ID, routine.Name, strings.Join(titles, " → ")))
+	case "delete":
+		if len(fields) < 2 {
+			return h.sendMessage(bot, message.Chat.ID, "❓ 请指定例程ID\n\n"+usage)
+		}
+		id, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return h.sendMessage(bot, message.Chat.ID, fmt.Sprintf("❌ 无效的例程ID: %s", fields[1]))
+		}
+		if err := h.routineService.DeleteRoutine(ctx, user.ID, uint(id)); err != nil {
+			return h.sendMessage(bot, message.Chat.ID, fmt.Sprintf("❌ %v", err))
+		}
+		return h.sendMessage(bot, message.Chat.ID, fmt.Sprintf("✅ 例程 #%d 已删除，其中的提醒恢复为按各自时间提醒", id))
+	default:
+		return h.sendMessage(bot, message.Chat.ID, usage)
+	}
+}
+
+// rruleCommand /rrule 命令的参数
+type rruleCommand struct {
+	save   bool
+	hour   int
+	minute int
+	set    *rrule.Set
+	title  string
+}
+
+// parseRRuleCommand 解析 /rrule 命令参数，格式: [save] HH:MM <RRULE> [标题]
+func parseRRuleCommand(args string) (*rruleCommand, error) {
+	fields := strings.Fields(args)
+	cmd := &rruleCommand{}
+	if len(fields) > 0 && strings.EqualFold(fields[0], "save") {
+		cmd.save = true
+		fields = fields[1:]
+	}
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("参数不足")
+	}
+
+	clock, err := time.Parse("15:04", fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("无效的时间 %s，请使用 HH:MM 格式", fields[0])
+	}
+	cmd.hour, cmd.minute = clock.Hour(), clock.Minute()
+
+	cmd.set, err = rrule.ParseSet(strings.TrimPrefix(fields[1], string(models.SchedulePatternRRule)))
+	if err != nil {
+		return nil, err
+	}
+
+	cmd.title = strings.Join(fields[2:], " ")
+	if cmd.save && cmd.title == "" {
+		return nil, fmt.Errorf("保存时需要提供提醒标题")
+	}
+	return cmd, nil
+}
+
+func (h *MessageHandler) handleRRuleCommand(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message, user *models.User) error {
+	usage := "用法：/rrule [save] HH:MM &lt;RRULE&gt; [标题]\n\n" +
+		"示例：/rrule 09:00 FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1 月末报表\n" +
+		"排除规则用 | 连接：FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR|EXRULE:FREQ=MONTHLY;BYDAY=FR;BYSETPOS=-1\n\n" +
+		"支持 FREQ(DAILY/WEEKLY/MONTHLY/YEARLY)、INTERVAL、BYDAY、BYMONTHDAY、BYMONTH、BYSETPOS、COUNT、UNTIL"
+
+	args := strings.TrimSpace(message.CommandArguments())
+	if args == "" {
+		return h.sendMessage(bot, message.Chat.ID, "🧩 自定义重复规则\n\n"+usage)
+	}
+
+	cmd, err := parseRRuleCommand(args)
+	if err != nil {
+		return h.sendMessage(bot, message.Chat.ID, fmt.Sprintf("❌ %v\n\n%s", err, usage))
+	}
+
+	timezone := reminderTimezone(user, "")
+	if timezone == "" {
+		timezone = ai.DefaultTimezone
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.Local
+	}
+
+	// 与调度器保持一致：DTSTART 为创建当天的目标时间
+	now := time.Now().In(loc)
+	dtstart := time.Date(now.Year(), now.Month(), now.Day(), cmd.hour, cmd.minute, 0, 0, loc)
+	occurrences := cmd.set.Occurrences(dtstart, now, 5)
+	if len(occurrences) == 0 {
+		return h.sendMessage(bot, message.Chat.ID, "⚠️ 该规则之后不会再触发，请检查 COUNT 或 UNTIL")
+	}
+
+	if !cmd.save {
+		title := cmd.title
+		if title == "" {
+			title = "&lt;标题&gt;"
+		}
+		return h.sendMessage(bot, message.Chat.ID, fmt.Sprintf(
+			"🔍 <b>规则预览</b>（%s）\n\n<code>%s</code>\n\n接下来 %d 次提醒：\n%s\n\n确认无误后发送：\n<code>/rrule save %02d:%02d %s %s</code>",
+			loc.String(), cmd.set.String(), len(occurrences), formatOccurrences(occurrences),
+			cmd.hour, cmd.minute, cmd.set.String(), title))
+	}
+
+	reminder := &models.Reminder{
+		UserID:          user.ID,
+		Title:           cmd.title,
+		Type:            models.ReminderTypeHabit,
+		SchedulePattern: string(models.SchedulePatternRRule) + cmd.set.String(),
+		TargetTime:      fmt.Sprintf("%02d:%02d:00", cmd.hour, cmd.minute),
+		Timezone:        loc.String(),
+		IsActive:        true,
+	}
+	if err := h.reminderService.CreateReminder(ctx, reminder); err != nil {
+		logger.Errorf("创建RRULE提醒失败: %v", err)
+		return h.sendErrorMessage(bot, message.Chat.ID, "创建提醒失败，请稍后重试")
+	}
+
+	return h.sendMessage(bot, message.Chat.ID, fmt.Sprintf(
+		"✅ 提醒已设置成功！\n\n📝 %s\n⏰ %s\n\n📅 下次提醒：%s",
+		reminder.Title, h.formatSchedule(reminder), formatOccurrence(occurrences[0])))
+}
+
+// previewOccurrences 创建提醒后展示的触发次数
+const previewOccurrences = 3
+
+// nextOccurrences 查询提醒接下来的触发时间，未设置调度服务或查询失败时返回空
+func (h *MessageHandler) nextOccurrences(reminder *models.Reminder, after time.Time, n int) []time.Time {
+	if h.schedulerService == nil {
+		return nil
+	}
+	occurrences, err := h.schedulerService.NextOccurrences(reminder, after, n)
+	if err != nil {
+		logger.Warnf("计算下次触发时间失败 (ID: %d): %v", reminder.ID, err)
+		return nil
+	}
+	return occurrences
+}
+
+// upcomingReminder 即将触发的一次提醒
+type upcomingReminder struct {
+	at       time.Time
+	reminder *models.Reminder
+}
+
+// upcomingReminders 返回 [now, now+window] 内将要触发的提醒，按时间排序
+func (h *MessageHandler) upcomingReminders(reminders []*models.Reminder, now time.Time, window time.Duration) []upcomingReminder {
+	var upcoming []upcomingReminder
+	end := now.Add(window)
+	for _, reminder := range reminders {
+		for _, at := range h.nextOccurrences(reminder, now, maxUpcomingPerReminder) {
+			if at.After(end) {
+				break
+			}
+			upcoming = append(upcoming, upcomingReminder{at: at, reminder: reminder})
+		}
+	}
+	sort.SliceStable(upcoming, func(i, j int) bool { return upcoming[i].at.Before(upcoming[j].at) })
+	return upcoming
+}
+
+// maxUpcomingPerReminder 单个提醒在 /next 中最多列出的次数（每小时提醒24小时内为24次）
+const maxUpcomingPerReminder = 48
+
+// handleNextCommand 列出未来24小时内将要触发的提醒
+func (h *MessageHandler) handleNextCommand(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message, user *models.User) error {
+	if h.schedulerService == nil {
+		return h.sendMessage(bot, message.Chat.ID, "⚠️ 调度服务未启用，暂时无法查看即将到来的提醒")
+	}
+
+	reminders, err := h.reminderService.GetUserReminders(ctx, user.ID)
+	if err != nil {
+		logger.Errorf("获取用户提醒列表失败: %v", err)
+		return h.sendErrorMessage(bot, message.Chat.ID, "获取提醒列表失败，请稍后重试")
+	}
+
+	upcoming := h.upcomingReminders(reminders, time.Now(), 24*time.Hour)
+	if len(upcoming) == 0 {
+		return h.sendMessage(bot, message.Chat.ID, "🌙 未来24小时内没有需要提醒的事项")
+	}
+
+	text := "⏭️ <b>未来24小时的提醒</b>\n\n"
+	for _, item := range upcoming {
+		text += fmt.Sprintf("• %s  <b>#%d</b> %s\n", formatOccurrence(item.at), item.reminder.ID, item.reminder.Title)
+	}
+	text += fmt.Sprintf("\n🔢 共 <b>%d</b> 次", len(upcoming))
+
+	return h.sendMessage(bot, message.Chat.ID, text)
+}
+
+// formatOccurrences 将触发时间列表格式化为多行文本
+func formatOccurrences(occurrences []time.Time) string {
+	lines := make([]string, 0, len(occurrences))
+	for _, occurrence := range occurrences {
+		lines = append(lines, "• "+formatOccurrence(occurrence))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatOccurrence 格式化单次触发时间，如 2026-05-01 周五 09:00
+func formatOccurrence(t time.Time) string {
+	return fmt.Sprintf("%s %s %s", t.Format("2006-01-02"), weekdayNames[t.Weekday()], t.Format("15:04"))
+}
+
 func (h *MessageHandler) handleListCommand(ctx context.Context, bot *tgbotapi.BotAPI, message *tgbotapi.Message, user *models.User) error {
 	reminders, err := h.reminderService.GetUserReminders(ctx, user.ID)
 	if err != nil {
@@ -194,6 +955,15 @@ func (h *MessageHandler) handleListCommand(ctx context.Context, bot *tgbotapi.Bo
 
 		listText += fmt.Sprintf("<b>#%d</b> %s <i>%s</i>\n", reminder.ID, typeIcon, reminder.Title)
 		listText += fmt.Sprintf("    ⏰ %s\n", h.formatSchedule(reminder))
+		if end := formatEndCondition(reminder); end != "" {
+			listText += fmt.Sprintf("    🏁 %s\n", end)
+		}
+		if lead := formatLeadTimes(reminder); lead != "" {
+			listText += fmt.Sprintf("    🔔 %s\n", lead)
+		}
+		if next := h.nextOccurrences(reminder, time.Now(), 1); len(next) > 0 {
+			listText += fmt.Sprintf("    ⏭️ 下次 %s\n", formatOccurrence(next[0]))
+		}
 		listText += fmt.Sprintf("    📊 %s %s\n\n", statusIcon, statusText)
 
 		// 三个按钮：编辑、删除、暂停/恢复
@@ -223,7 +993,7 @@ func (h *MessageHandler) handleListCommand(ctx context.Context, bot *tgbotapi.Bo
 	if len(keyboardRows) > 0 {
 		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboardRows...)
 	}
-	_, err = bot.Send(msg)
+	_, err = h.send(bot, msg)
 	return err
 }
 
@@ -266,6 +1036,14 @@ func (h *MessageHandler) handleStatsCommand(ctx context.Context, bot *tgbotapi.B
 		statsText += "  📊 完成率: 暂无数据\n\n"
 	}
 
+	// 例程统计
+	if stats.RoutinesCompletedMonth > 0 || stats.RoutineCompletionRate > 0 {
+		statsText += "🔗 <b>例程:</b>\n"
+		statsText += fmt.Sprintf("  ✅ 本周完成: %d 次\n", stats.RoutinesCompletedWeek)
+		statsText += fmt.Sprintf("  ✅ 本月完成: %d 次\n", stats.RoutinesCompletedMonth)
+		statsText += fmt.Sprintf("  📊 完成率: %d%%\n\n", stats.RoutineCompletionRate)
+	}
+
 	// 鼓励信息
 	if stats.CompletedToday > 0 {
 		statsText += "🌟 <i>今天做得很棒！继续保持！</i>"
@@ -349,6 +1127,10 @@ func (h *MessageHandler) handleWithLegacyParser(ctx context.Context, bot *tgbota
 		return h.sendMessage(bot, message.Chat.ID, "请告诉我你想要设置什么提醒？\n\n例如：\"每天19点提醒我复盘工作\"")
 	}
 
+	if reminder.Timezone == "" {
+		reminder.Timezone = reminderTimezone(user, "")
+	}
+
 	// 创建提醒
 	if err := h.reminderService.CreateReminder(ctx, reminder); err != nil {
 		logger.Errorf("创建提醒失败: %v", err)
@@ -368,20 +1150,20 @@ func (h *MessageHandler) handleReminderIntent(ctx context.Context, bot *tgbotapi
 
 	reminderInfo := parseResult.Reminder
 
-	// 构造时间字符串 HH:MM:SS
-	targetTime := fmt.Sprintf("%02d:%02d:00", reminderInfo.Time.Hour, reminderInfo.Time.Minute)
-
 	// 创建提醒对象
 	reminder := &models.Reminder{
 		UserID:          user.ID,
 		Title:           reminderInfo.Title,
 		Description:     reminderInfo.Description,
 		Type:            reminderInfo.Type,
-		TargetTime:      targetTime,
 		SchedulePattern: string(reminderInfo.SchedulePattern),
 		IsActive:        true,
-		Timezone:        reminderInfo.Time.Timezone,
+		Timezone:        reminderTimezone(user, reminderInfo.Time.Timezone),
+		UntilDate:       reminderInfo.UntilDate,
+		MaxOccurrences:  reminderInfo.MaxOccurrences,
+		LeadTimes:       reminderInfo.LeadTimes,
 	}
+	reminder.SetTimes(targetTimes(reminderInfo.Time))
 
 	// 保存提醒
 	if err := h.reminderService.CreateReminder(ctx, reminder); err != nil {
@@ -392,6 +1174,12 @@ func (h *MessageHandler) handleReminderIntent(ctx context.Context, bot *tgbotapi
 	// 构造成功消息
 	successText := fmt.Sprintf("✅ 提醒已设置成功！\n\n📝 %s\n⏰ %s",
 		reminder.Title, h.formatSchedule(reminder))
+	if lead := formatLeadTimes(reminder); lead != "" {
+		successText += "\n🔔 " + lead
+	}
+	if next := h.nextOccurrences(reminder, time.Now(), previewOccurrences); len(next) > 0 {
+		successText += "\n\n⏭️ 接下来将在：\n" + formatOccurrences(next)
+	}
 
 	// 如果置信度不是很高，添加提示
 	if parseResult.IsLowConfidence() {
@@ -493,6 +1281,9 @@ func (h *MessageHandler) handleEditIntent(ctx context.Context, bot *tgbotapi.Bot
 	if parseResult.Edit.NewTime != nil {
 		newTime := fmt.Sprintf("%02d:%02d:00", parseResult.Edit.NewTime.Hour, parseResult.Edit.NewTime.Minute)
 		params.NewTime = &newTime
+		if len(parseResult.Edit.NewTime.Times) > 0 {
+			params.NewTimes = targetTimes(*parseResult.Edit.NewTime)
+		}
 	}
 
 	// 处理新模式
@@ -581,7 +1372,7 @@ func (h *MessageHandler) handlePauseIntent(ctx context.Context, bot *tgbotapi.Bo
 		untilText = time.Now().Add(duration).Format("2006-01-02 15:04")
 	}
 
-	response := fmt.Sprintf("⏸️ 已暂停提醒\n\n📝 %s\n⏳ 暂停至 %s",
+	response := fmt.Sprintf("⏸️ 已暂停提醒\n\n📝 %s\n⏳ 暂停至 %s，到期后自动恢复",
 		target.Title, untilText)
 	if reason := strings.TrimSpace(parseResult.Pause.Reason); reason != "" {
 		response += fmt.Sprintf("\n💬 理由：%s", reason)
@@ -725,6 +1516,31 @@ func matchReminders(reminders []*models.Reminder, keywords []string) []reminderM
 	return matches
 }
 
+// targetTimes 将解析出的时间信息转换为 HH:MM:SS 列表，包含 Hour/Minute 和 Times 中的全部时间
+func targetTimes(info ai.TimeInfo) []string {
+	times := []string{fmt.Sprintf("%02d:%02d:00", info.Hour, info.Minute)}
+	for _, t := range info.Times {
+		clock, err := time.Parse("15:04", strings.TrimSpace(t))
+		if err != nil {
+			logger.Warnf("忽略无效的触发时间: %s", t)
+			continue
+		}
+		times = append(times, clock.Format("15:04:05"))
+	}
+	return times
+}
+
+// reminderTimezone 确定新提醒的时区：解析器未识别到明确时区时使用用户设置的时区
+func reminderTimezone(user *models.User, parsed string) string {
+	if parsed != "" && parsed != ai.DefaultTimezone {
+		return parsed
+	}
+	if user != nil && user.Timezone != "" {
+		return user.Timezone
+	}
+	return parsed
+}
+
 func filterKeywords(keywords []string) []string {
 	var result []string
 	for _, keyword := range keywords {
@@ -936,7 +1752,7 @@ func (h *MessageHandler) ensureUser(ctx context.Context, from *tgbotapi.User) (*
 func (h *MessageHandler) formatSchedule(reminder *models.Reminder) string {
 	switch {
 	case reminder.IsDaily():
-		return fmt.Sprintf("每天 %s", reminder.TargetTime[:5])
+		return fmt.Sprintf("每天 %s", formatTimes(reminder))
 	case reminder.IsWeekly():
 		// 解析周几
 		weekdayMap := map[string]string{
@@ -955,27 +1771,146 @@ func (h *MessageHandler) formatSchedule(reminder *models.Reminder) string {
 				}
 			}
 			if len(weekdays) > 0 {
-				return fmt.Sprintf("%s %s", strings.Join(weekdays, "、"), reminder.TargetTime[:5])
+				return fmt.Sprintf("%s %s", strings.Join(weekdays, "、"), formatTimes(reminder))
+			}
+		}
+		return fmt.Sprintf("每周指定时间 %s", formatTimes(reminder))
+	case reminder.IsMonthly(), reminder.IsYearly():
+		if desc := formatCalendarPattern(reminder.SchedulePattern); desc != "" {
+			return fmt.Sprintf("%s %s", desc, formatTimes(reminder))
+		}
+		return fmt.Sprintf("%s %s", reminder.SchedulePattern, formatTimes(reminder))
+	case reminder.IsInterval():
+		if desc := formatIntervalPattern(reminder.SchedulePattern); desc != "" {
+			if strings.Contains(reminder.SchedulePattern, "@") {
+				return desc
 			}
+			return fmt.Sprintf("%s %s", desc, formatTimes(reminder))
 		}
-		return fmt.Sprintf("每周指定时间 %s", reminder.TargetTime[:5])
+		return fmt.Sprintf("%s %s", reminder.SchedulePattern, formatTimes(reminder))
+	case reminder.IsWorkday():
+		return fmt.Sprintf("工作日（含调休） %s", formatTimes(reminder))
+	case reminder.IsRRule():
+		rule := strings.TrimPrefix(reminder.SchedulePattern, string(models.SchedulePatternRRule))
+		return fmt.Sprintf("自定义规则 %s（%s）", formatTimes(reminder), rule)
 	case reminder.IsOnce():
 		// 解析日期
 		pattern := reminder.SchedulePattern
 		if strings.HasPrefix(pattern, string(models.SchedulePatternOnce)) {
 			dateStr := strings.TrimPrefix(pattern, string(models.SchedulePatternOnce))
-			return fmt.Sprintf("%s %s", dateStr, reminder.TargetTime[:5])
+			return fmt.Sprintf("%s %s", dateStr, formatTimes(reminder))
 		}
-		return fmt.Sprintf("一次性提醒 %s", reminder.TargetTime[:5])
+		return fmt.Sprintf("一次性提醒 %s", formatTimes(reminder))
 	default:
 		return reminder.SchedulePattern
 	}
 }
 
+var weekdayNames = []string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"}
+
+// formatEndCondition 展示重复提醒的结束条件，如 "截至 2026-12-31 · 已提醒 5/21 次"
+func formatEndCondition(reminder *models.Reminder) string {
+	var parts []string
+	if reminder.UntilDate != "" {
+		parts = append(parts, "截至 "+reminder.UntilDate)
+	}
+	if reminder.MaxOccurrences > 0 {
+		parts = append(parts, fmt.Sprintf("已提醒 %d/%d 次", reminder.OccurrenceCount, reminder.MaxOccurrences))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// formatLeadTimes 展示提前通知，如 "提前 1天、30分钟 通知"
+func formatLeadTimes(reminder *models.Reminder) string {
+	offsets := reminder.LeadOffsets()
+	if len(offsets) == 0 {
+		return ""
+	}
+	items := make([]string, len(offsets))
+	for i, offset := range offsets {
+		items[i] = models.DescribeLeadTime(offset)
+	}
+	return fmt.Sprintf("提前 %s 通知", strings.Join(items, "、"))
+}
+
+// formatTimes 将提醒的全部触发时间格式化为 "08:00、13:00"
+func formatTimes(reminder *models.Reminder) string {
+	times := reminder.Times()
+	for i, t := range times {
+		if len(t) >= 5 {
+			times[i] = t[:5]
+		}
+	}
+	return strings.Join(times, "、")
+}
+
+// formatCalendarPattern 将每月/每年模式转换为可读描述，解析失败时返回空字符串
+func formatCalendarPattern(pattern string) string {
+	rule, err := models.ParseCalendarPattern(pattern)
+	if err != nil {
+		return ""
+	}
+
+	ordinalNames := []string{"", "第一个", "第二个", "第三个", "第四个", "第五个"}
+
+	var parts []string
+	for _, day := range rule.Days {
+		parts = append(parts, fmt.Sprintf("%d日", day))
+	}
+	if rule.LastDay {
+		parts = append(parts, "最后一天")
+	}
+	for _, nth := range rule.NthWeekdays {
+		ordinal := "最后一个"
+		if nth.N > 0 {
+			ordinal = ordinalNames[nth.N]
+		}
+		parts = append(parts, ordinal+weekdayNames[nth.Weekday])
+	}
+	for _, date := range rule.Dates {
+		parts = append(parts, fmt.Sprintf("%d月%d日", int(date.Month), date.Day))
+	}
+
+	if len(rule.Dates) > 0 {
+		return "每年" + strings.Join(parts, "、")
+	}
+	return "每月" + strings.Join(parts, "、")
+}
+
+// formatIntervalPattern 将间隔模式转换为可读描述，解析失败时返回空字符串
+func formatIntervalPattern(pattern string) string {
+	rule, err := models.ParseIntervalPattern(pattern)
+	if err != nil {
+		return ""
+	}
+
+	var desc string
+	switch rule.Unit {
+	case models.IntervalHour:
+		desc = fmt.Sprintf("每%d小时", rule.Every)
+	case models.IntervalDay:
+		desc = fmt.Sprintf("每%d天", rule.Every)
+		if rule.Every == 2 {
+			desc = "隔天"
+		}
+	case models.IntervalWeek:
+		desc = fmt.Sprintf("每%d周", rule.Every)
+		if rule.Every == 2 {
+			desc = "隔周"
+		}
+	}
+
+	if rule.HasWindow {
+		desc += fmt.Sprintf("（%02d:%02d-%02d:%02d）",
+			rule.WindowStart/60, rule.WindowStart%60, rule.WindowEnd/60, rule.WindowEnd%60)
+	}
+	return desc
+}
+
 func (h *MessageHandler) sendMessage(bot *tgbotapi.BotAPI, chatID int64, text string) error {
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ParseMode = tgbotapi.ModeHTML
-	_, err := bot.Send(msg)
+	_, err := h.send(bot, msg)
 	return err
 }
 
@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"mmemory/internal/models"
+	"mmemory/internal/service"
+	"mmemory/pkg/ai"
 )
 
 // TestMatchReminders 测试关键词匹配算法
@@ -124,6 +128,33 @@ func TestMatchReminders(t *testing.T) {
 }
 
 // TestFilterKeywords 测试关键词过滤
+func TestFollowTimezoneChange(t *testing.T) {
+	inherited := &models.Reminder{ID: 1}
+	explicitOld := &models.Reminder{ID: 2, Timezone: "Asia/Shanghai"}
+	explicitOther := &models.Reminder{ID: 3, Timezone: "America/New_York"}
+
+	changed := followTimezoneChange([]*models.Reminder{inherited, explicitOld, explicitOther}, "Asia/Shanghai", "Europe/Berlin")
+
+	if len(changed) != 2 || changed[0] != inherited || changed[1] != explicitOld {
+		t.Fatalf("应重新调度未设置时区和使用旧时区的提醒，实际 %v", changed)
+	}
+	if inherited.Timezone != "" || inherited.User.Timezone != "Europe/Berlin" {
+		t.Errorf("未设置时区的提醒应继续跟随用户时区: Timezone=%q User.Timezone=%q", inherited.Timezone, inherited.User.Timezone)
+	}
+	if explicitOld.Timezone != "Europe/Berlin" {
+		t.Errorf("使用旧时区的提醒应迁移到新时区，实际 %q", explicitOld.Timezone)
+	}
+	if explicitOther.Timezone != "America/New_York" {
+		t.Errorf("使用其他时区的提醒不应改变，实际 %q", explicitOther.Timezone)
+	}
+
+	// 用户此前未设置时区时，旧时区视为默认时区
+	defaulted := &models.Reminder{ID: 4, Timezone: "Asia/Shanghai"}
+	if changed := followTimezoneChange([]*models.Reminder{defaulted}, "", "Asia/Tokyo"); len(changed) != 1 || defaulted.Timezone != "Asia/Tokyo" {
+		t.Errorf("使用默认时区的提醒应迁移，实际 %q", defaulted.Timezone)
+	}
+}
+
 func TestFilterKeywords(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -175,6 +206,103 @@ func TestFilterKeywords(t *testing.T) {
 	}
 }
 
+// TestFormatCalendarPattern 测试每月/每年模式的展示文案
+func TestFormatCalendarPattern(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		expected string
+	}{
+		{"monthly:1,15", "每月1日、15日"},
+		{"monthly:L", "每月最后一天"},
+		{"monthly:1#1", "每月第一个周一"},
+		{"monthly:5#L", "每月最后一个周五"},
+		{"yearly:12-25", "每年12月25日"},
+		{"monthly:abc", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			if result := formatCalendarPattern(tt.pattern); result != tt.expected {
+				t.Errorf("formatCalendarPattern(%q) = %q，期望 %q", tt.pattern, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestFormatIntervalPattern 测试间隔模式的展示文案
+func TestFormatIntervalPattern(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		expected string
+	}{
+		{"every:2h", "每2小时"},
+		{"every:2h@09:00-18:00", "每2小时（09:00-18:00）"},
+		{"every:3d", "每3天"},
+		{"every:2d", "隔天"},
+		{"every:2w", "隔周"},
+		{"every:abc", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			if result := formatIntervalPattern(tt.pattern); result != tt.expected {
+				t.Errorf("formatIntervalPattern(%q) = %q，期望 %q", tt.pattern, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestParseRRuleCommand 测试 /rrule 命令参数解析
+func TestParseRRuleCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     string
+		wantErr  bool
+		wantSave bool
+		wantRule string
+		title    string
+	}{
+		{"预览", "09:00 FREQ=WEEKLY;BYDAY=MO", false, false, "FREQ=WEEKLY;BYDAY=MO", ""},
+		{"保存带标题", "save 18:30 rrule:freq=monthly;bymonthday=-1 交 房租", false, true, "FREQ=MONTHLY;BYMONTHDAY=-1", "交 房租"},
+		{"保存缺少标题", "save 18:30 FREQ=DAILY", true, false, "", ""},
+		{"时间格式错误", "9点 FREQ=DAILY", true, false, "", ""},
+		{"规则无效", "09:00 FREQ=SECONDLY", true, false, "", ""},
+		{"参数不足", "09:00", true, false, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := parseRRuleCommand(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseRRuleCommand(%q) 期望返回错误", tt.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseRRuleCommand(%q) 失败: %v", tt.args, err)
+			}
+			if cmd.save != tt.wantSave || cmd.set.String() != tt.wantRule || cmd.title != tt.title {
+				t.Errorf("parseRRuleCommand(%q) = save:%v rule:%s title:%q", tt.args, cmd.save, cmd.set.String(), cmd.title)
+			}
+		})
+	}
+}
+
+// TestTargetTimes 测试解析结果转换为多个触发时间
+func TestTargetTimes(t *testing.T) {
+	info := ai.TimeInfo{Hour: 8, Minute: 0, Times: []string{"08:00", "13:30", "bad", "20:00"}}
+	reminder := &models.Reminder{}
+	reminder.SetTimes(targetTimes(info))
+
+	if got := strings.Join(reminder.Times(), ","); got != "08:00:00,13:30:00,20:00:00" {
+		t.Errorf("Times() = %s", got)
+	}
+	if got := formatTimes(reminder); got != "08:00、13:30、20:00" {
+		t.Errorf("formatTimes() = %s", got)
+	}
+}
+
 // TestParsePauseDuration 测试暂停时长解析
 func TestParsePauseDuration(t *testing.T) {
 	tests := []struct {
@@ -400,3 +528,26 @@ func TestMatchReminders_Performance(t *testing.T) {
 
 	t.Logf("匹配1000个提醒耗时: %v, 匹配到: %d 个", duration, len(matches))
 }
+
+// TestUpcomingReminders 测试 /next 按时间合并未来24小时内的提醒
+func TestUpcomingReminders(t *testing.T) {
+	h := &MessageHandler{}
+	h.SetScheduler(service.NewSchedulerService(nil, nil, nil))
+
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	reminders := []*models.Reminder{
+		{ID: 1, Title: "喝水", SchedulePattern: "every:4h@08:00-20:00", TargetTime: "08:00:00", Timezone: "UTC", IsActive: true},
+		{ID: 2, Title: "写周报", SchedulePattern: "weekly:5", TargetTime: "17:00:00", Timezone: "UTC", IsActive: true},
+		{ID: 3, Title: "体检", SchedulePattern: "once:2026-05-03", TargetTime: "09:00:00", Timezone: "UTC", IsActive: true},
+	}
+
+	var got []string
+	for _, item := range h.upcomingReminders(reminders, now, 24*time.Hour) {
+		got = append(got, fmt.Sprintf("%s#%d", item.at.Format("01-02 15:04"), item.reminder.ID))
+	}
+
+	want := "05-01 12:00#1,05-01 16:00#1,05-01 17:00#2,05-01 20:00#1,05-02 08:00#1"
+	if strings.Join(got, ",") != want {
+		t.Errorf("upcomingReminders() = %s, want %s", strings.Join(got, ","), want)
+	}
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseAgendaTime 解析每日日程的推送时间，返回规范化的 HH:MM；空字符串或 off 表示不推送
+func ParseAgendaTime(spec string) (string, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" || strings.EqualFold(spec, "off") {
+		return "", nil
+	}
+
+	minutes, err := parseQuietClock(spec)
+	if err != nil {
+		return "", fmt.Errorf("日程推送时间格式应为 HH:MM: %s", spec)
+	}
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60), nil
+}
+
+// AgendaClock 返回每日日程的推送时刻，未开启或格式无效时 ok 为 false
+func (u *User) AgendaClock() (hour, minute int, ok bool) {
+	if u.AgendaTime == "" {
+		return 0, 0, false
+	}
+	minutes, err := parseQuietClock(u.AgendaTime)
+	if err != nil {
+		return 0, 0, false
+	}
+	return minutes / 60, minutes % 60, true
+}
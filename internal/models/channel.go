@@ -0,0 +1,65 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 通知渠道名称
+const (
+	ChannelTelegram = "telegram" // Telegram 消息，带操作按钮
+	ChannelEmail    = "email"    // SMTP 邮件，发送到 User.Email
+	ChannelWebhook  = "webhook"  // 带签名的 HTTP 回调，发送到 User.WebhookURL
+)
+
+// KnownChannels 支持的通知渠道
+var KnownChannels = []string{ChannelTelegram, ChannelEmail, ChannelWebhook}
+
+// ParseChannels 解析逗号分隔的渠道列表，按顺序依次尝试，前一个失败时使用下一个
+func ParseChannels(spec string) ([]string, error) {
+	var channels []string
+	seen := make(map[string]bool)
+	for _, item := range strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || r == ' ' || r == '，' }) {
+		name := strings.ToLower(strings.TrimSpace(item))
+		known := false
+		for _, channel := range KnownChannels {
+			if name == channel {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("不支持的通知渠道: %s（可选 %s）", item, strings.Join(KnownChannels, "、"))
+		}
+		if !seen[name] {
+			seen[name] = true
+			channels = append(channels, name)
+		}
+	}
+	if len(channels) == 0 {
+		return nil, fmt.Errorf("至少需要一个通知渠道")
+	}
+	return channels, nil
+}
+
+// ResolveChannels 按 提醒 → 用户 → fallback 的顺序返回生效的渠道列表，无效的设置会被忽略
+func (r *Reminder) ResolveChannels(fallback []string) []string {
+	return r.User.resolveChannels(r.Channels, fallback)
+}
+
+// ResolveChannels 返回用户生效的渠道列表，未设置或设置无效时使用 fallback
+func (u *User) ResolveChannels(fallback []string) []string {
+	return u.resolveChannels("", fallback)
+}
+
+func (u *User) resolveChannels(override string, fallback []string) []string {
+	for _, spec := range []string{override, u.Channels} {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		if channels, err := ParseChannels(spec); err == nil {
+			return channels
+		}
+	}
+	return fallback
+}
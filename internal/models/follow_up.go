@@ -0,0 +1,176 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FollowUpOff 关闭关怀消息的策略写法
+const FollowUpOff = "off"
+
+// FollowUpPolicy 提醒发送后未回复时的关怀策略
+// 文本格式: 15m,1h,3h;max=5;then=skipped，或 off 表示关闭；空字符串表示沿用上一级（提醒 → 用户 → 系统默认）
+type FollowUpPolicy struct {
+	Disabled  bool
+	Intervals []time.Duration // 第 n 次关怀距上一条消息的间隔，次数超过列表长度时沿用最后一个
+	Max       int             // 最多关怀次数，未指定时等于间隔个数
+	Then      ReminderStatus  // 最后一次关怀后仍未回复时记录的状态: overdue 或 skipped
+}
+
+// DefaultFollowUpPolicy 系统默认策略：发送1小时后未回复开始关怀，每小时一次，最多3次后记为已超时
+var DefaultFollowUpPolicy = FollowUpPolicy{
+	Intervals: []time.Duration{time.Hour},
+	Max:       3,
+	Then:      ReminderStatusOverdue,
+}
+
+// ParseFollowUpPolicy 解析关怀策略，各部分可用分号或空格分隔
+func ParseFollowUpPolicy(spec string) (FollowUpPolicy, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.EqualFold(spec, FollowUpOff) {
+		return FollowUpPolicy{Disabled: true}, nil
+	}
+
+	policy := FollowUpPolicy{Then: ReminderStatusOverdue}
+	fields := strings.FieldsFunc(spec, func(r rune) bool { return r == ';' || r == ' ' })
+	for _, field := range fields {
+		key, value, hasValue := strings.Cut(field, "=")
+		if !hasValue {
+			for _, item := range splitPatternItems(field) {
+				interval, err := parseFollowUpInterval(item)
+				if err != nil {
+					return FollowUpPolicy{}, err
+				}
+				policy.Intervals = append(policy.Intervals, interval)
+			}
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case "max":
+			max, err := strconv.Atoi(value)
+			if err != nil || max < 1 || max > 10 {
+				return FollowUpPolicy{}, fmt.Errorf("关怀次数必须在1到10之间: %s", value)
+			}
+			policy.Max = max
+		case "then":
+			switch ReminderStatus(strings.ToLower(value)) {
+			case ReminderStatusOverdue:
+				policy.Then = ReminderStatusOverdue
+			case ReminderStatusSkipped:
+				policy.Then = ReminderStatusSkipped
+			default:
+				return FollowUpPolicy{}, fmt.Errorf("最后一次关怀后的处理只支持 overdue 或 skipped: %s", value)
+			}
+		default:
+			return FollowUpPolicy{}, fmt.Errorf("无效的关怀策略参数: %s", key)
+		}
+	}
+
+	if len(policy.Intervals) == 0 {
+		return FollowUpPolicy{}, fmt.Errorf("关怀策略缺少间隔: %s", spec)
+	}
+	if policy.Max == 0 {
+		policy.Max = len(policy.Intervals)
+	}
+	return policy, nil
+}
+
+// parseFollowUpInterval 解析单个间隔，支持 m/h/d 单位，最短5分钟、最长7天
+func parseFollowUpInterval(item string) (time.Duration, error) {
+	if len(item) < 2 {
+		return 0, fmt.Errorf("无效的关怀间隔: %s", item)
+	}
+	n, err := strconv.Atoi(item[:len(item)-1])
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("无效的关怀间隔: %s", item)
+	}
+
+	var interval time.Duration
+	switch strings.ToLower(item[len(item)-1:]) {
+	case "m":
+		interval = time.Duration(n) * time.Minute
+	case "h":
+		interval = time.Duration(n) * time.Hour
+	case "d":
+		interval = time.Duration(n) * 24 * time.Hour
+	default:
+		return 0, fmt.Errorf("无效的关怀间隔单位: %s", item)
+	}
+
+	if interval < 5*time.Minute || interval > 7*24*time.Hour {
+		return 0, fmt.Errorf("关怀间隔需在5分钟到7天之间: %s", item)
+	}
+	return interval, nil
+}
+
+// String 返回策略的文本格式，可由 ParseFollowUpPolicy 还原
+func (p FollowUpPolicy) String() string {
+	if p.Disabled {
+		return FollowUpOff
+	}
+
+	items := make([]string, 0, len(p.Intervals))
+	for _, interval := range p.Intervals {
+		items = append(items, formatFollowUpInterval(interval))
+	}
+	spec := strings.Join(items, ",")
+	if p.Max != len(p.Intervals) {
+		spec += fmt.Sprintf(";max=%d", p.Max)
+	}
+	if p.Then != "" && p.Then != ReminderStatusOverdue {
+		spec += ";then=" + string(p.Then)
+	}
+	return spec
+}
+
+func formatFollowUpInterval(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	default:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+}
+
+// Interval 返回第 n 次（从0开始）关怀距上一条消息的间隔
+// n 等于 Max 时返回最后一次关怀后等待回复的时间，之后按 Then 处理
+func (p FollowUpPolicy) Interval(n int) time.Duration {
+	if len(p.Intervals) == 0 {
+		return 0
+	}
+	if n >= len(p.Intervals) {
+		n = len(p.Intervals) - 1
+	}
+	return p.Intervals[n]
+}
+
+// NextAt 返回记录下一次需要处理（关怀或按 Then 结束）的时间，记录未发送或策略关闭时返回 false
+func (p FollowUpPolicy) NextAt(log *ReminderLog) (time.Time, bool) {
+	if p.Disabled || log.Status != ReminderStatusSent || log.SentTime == nil {
+		return time.Time{}, false
+	}
+
+	last := *log.SentTime
+	if log.LastFollowUpAt != nil && log.LastFollowUpAt.After(last) {
+		last = *log.LastFollowUpAt
+	}
+	return last.Add(p.Interval(log.FollowUpCount)), true
+}
+
+// ResolveFollowUpPolicy 按 提醒 → 用户 → fallback 的顺序返回生效的关怀策略，无效的设置会被忽略
+func (r *Reminder) ResolveFollowUpPolicy(fallback FollowUpPolicy) FollowUpPolicy {
+	for _, spec := range []string{r.FollowUpPolicy, r.User.FollowUpPolicy} {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		if policy, err := ParseFollowUpPolicy(spec); err == nil {
+			return policy
+		}
+	}
+	return fallback
+}
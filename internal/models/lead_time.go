@@ -0,0 +1,95 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxLeadTime 提前通知最多提前的时长
+const MaxLeadTime = 30 * 24 * time.Hour
+
+// maxLeadTimes 每个提醒最多设置的提前通知数量
+const maxLeadTimes = 5
+
+// ParseLeadTimes 解析逗号分隔的提前量（如 1d,1h,10m），按从早到晚（提前量从大到小）排序并去重
+// 空字符串或 off 返回空列表
+func ParseLeadTimes(spec string) ([]time.Duration, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" || strings.EqualFold(spec, "off") {
+		return nil, nil
+	}
+
+	seen := make(map[time.Duration]bool)
+	var offsets []time.Duration
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		offset, err := parseFollowUpInterval(item)
+		if err != nil {
+			return nil, fmt.Errorf("无效的提前量: %s", item)
+		}
+		if offset > MaxLeadTime {
+			return nil, fmt.Errorf("提前量不能超过30天: %s", item)
+		}
+		if !seen[offset] {
+			seen[offset] = true
+			offsets = append(offsets, offset)
+		}
+	}
+	if len(offsets) > maxLeadTimes {
+		return nil, fmt.Errorf("最多设置%d个提前通知", maxLeadTimes)
+	}
+
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] > offsets[j] })
+	return offsets, nil
+}
+
+// FormatLeadTimes 将提前量格式化为 ParseLeadTimes 可解析的文本
+func FormatLeadTimes(offsets []time.Duration) string {
+	sorted := append([]time.Duration(nil), offsets...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] > sorted[j] })
+
+	items := make([]string, 0, len(sorted))
+	for _, offset := range sorted {
+		if offset <= 0 {
+			continue
+		}
+		items = append(items, formatFollowUpInterval(offset))
+	}
+	return strings.Join(items, ",")
+}
+
+// LeadOffsets 返回提醒的提前量，格式无效时返回空列表
+func (r *Reminder) LeadOffsets() []time.Duration {
+	offsets, err := ParseLeadTimes(r.LeadTimes)
+	if err != nil {
+		return nil
+	}
+	return offsets
+}
+
+// DescribeLeadTime 返回提前量的中文描述，如 1天、1小时30分钟
+func DescribeLeadTime(offset time.Duration) string {
+	days := int(offset / (24 * time.Hour))
+	hours := int(offset % (24 * time.Hour) / time.Hour)
+	minutes := int(offset % time.Hour / time.Minute)
+
+	var b strings.Builder
+	if days > 0 {
+		fmt.Fprintf(&b, "%d天", days)
+	}
+	if hours > 0 {
+		fmt.Fprintf(&b, "%d小时", hours)
+	}
+	if minutes > 0 {
+		fmt.Fprintf(&b, "%d分钟", minutes)
+	}
+	if b.Len() == 0 {
+		return "0分钟"
+	}
+	return b.String()
+}
@@ -0,0 +1,179 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// QuietFollowUpAction 免打扰时段内到期的关怀消息的处理方式
+type QuietFollowUpAction string
+
+const (
+	QuietFollowUpDrop  QuietFollowUpAction = "drop"  // 不发送，计入关怀次数
+	QuietFollowUpDefer QuietFollowUpAction = "defer" // 推迟到时段结束后发送
+)
+
+// QuietWindow 一天内的免打扰时段，结束时间不晚于开始时间时表示跨过午夜
+type QuietWindow struct {
+	Start int // 开始时间，当天第几分钟
+	End   int // 结束时间，当天第几分钟
+}
+
+// QuietHours 用户的免打扰设置，按用户时区计算；时段归属于开始的那一天
+// 文本格式: 22:00-07:00;weekend=23:00-09:00;followups=defer，或 off 表示关闭
+// 时段内到期的提醒推迟到时段结束后发送，关怀消息按 FollowUps 丢弃或推迟，重要提醒不受限制
+type QuietHours struct {
+	Weekday   *QuietWindow // 周一到周五开始的时段，为空表示不限制
+	Weekend   *QuietWindow // 周六、周日开始的时段，为空表示不限制
+	FollowUps QuietFollowUpAction
+}
+
+// ParseQuietHours 解析免打扰设置，各部分可用分号或空格分隔；空字符串或 off 返回未启用的设置
+func ParseQuietHours(spec string) (QuietHours, error) {
+	hours := QuietHours{FollowUps: QuietFollowUpDrop}
+
+	spec = strings.TrimSpace(spec)
+	if spec == "" || strings.EqualFold(spec, "off") {
+		return hours, nil
+	}
+
+	fields := strings.FieldsFunc(spec, func(r rune) bool { return r == ';' || r == ' ' })
+	for _, field := range fields {
+		key, value, hasValue := strings.Cut(field, "=")
+		if !hasValue {
+			window, err := parseQuietWindow(field)
+			if err != nil {
+				return QuietHours{}, err
+			}
+			hours.Weekday, hours.Weekend = window, window
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case "weekday", "weekend":
+			var window *QuietWindow
+			if !strings.EqualFold(value, "off") {
+				parsed, err := parseQuietWindow(value)
+				if err != nil {
+					return QuietHours{}, err
+				}
+				window = parsed
+			}
+			if strings.EqualFold(key, "weekday") {
+				hours.Weekday = window
+			} else {
+				hours.Weekend = window
+			}
+		case "followups":
+			switch QuietFollowUpAction(strings.ToLower(value)) {
+			case QuietFollowUpDrop:
+				hours.FollowUps = QuietFollowUpDrop
+			case QuietFollowUpDefer:
+				hours.FollowUps = QuietFollowUpDefer
+			default:
+				return QuietHours{}, fmt.Errorf("免打扰时段内的关怀只支持 drop 或 defer: %s", value)
+			}
+		default:
+			return QuietHours{}, fmt.Errorf("无效的免打扰参数: %s", key)
+		}
+	}
+
+	return hours, nil
+}
+
+// parseQuietWindow 解析 HH:MM-HH:MM 格式的时段
+func parseQuietWindow(value string) (*QuietWindow, error) {
+	from, to, ok := strings.Cut(value, "-")
+	if !ok {
+		return nil, fmt.Errorf("免打扰时段格式应为 HH:MM-HH:MM: %s", value)
+	}
+
+	start, err := parseQuietClock(from)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseQuietClock(to)
+	if err != nil {
+		return nil, err
+	}
+	if start == end {
+		return nil, fmt.Errorf("免打扰时段的开始和结束时间不能相同: %s", value)
+	}
+	return &QuietWindow{Start: start, End: end}, nil
+}
+
+func parseQuietClock(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("无效的时间: %s", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Enabled 是否设置了任一免打扰时段
+func (q QuietHours) Enabled() bool {
+	return q.Weekday != nil || q.Weekend != nil
+}
+
+// String 返回设置的文本格式，可由 ParseQuietHours 还原
+func (q QuietHours) String() string {
+	if !q.Enabled() {
+		return "off"
+	}
+
+	var parts []string
+	if q.Weekday != nil && q.Weekend != nil && *q.Weekday == *q.Weekend {
+		parts = append(parts, q.Weekday.String())
+	} else {
+		for _, item := range []struct {
+			key    string
+			window *QuietWindow
+		}{{"weekday", q.Weekday}, {"weekend", q.Weekend}} {
+			if item.window == nil {
+				parts = append(parts, item.key+"=off")
+			} else {
+				parts = append(parts, item.key+"="+item.window.String())
+			}
+		}
+	}
+	if q.FollowUps == QuietFollowUpDefer {
+		parts = append(parts, "followups="+string(QuietFollowUpDefer))
+	}
+	return strings.Join(parts, ";")
+}
+
+// String 返回 HH:MM-HH:MM 格式
+func (w QuietWindow) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", w.Start/60, w.Start%60, w.End/60, w.End%60)
+}
+
+// windowFor 返回在指定星期开始的时段
+func (q QuietHours) windowFor(day time.Weekday) *QuietWindow {
+	if day == time.Saturday || day == time.Sunday {
+		return q.Weekend
+	}
+	return q.Weekday
+}
+
+// Until 判断 t 是否处于免打扰时段内并返回时段结束时间，时段按 t 所在时区计算
+// 前一天开始、跨过午夜的时段同样生效
+func (q QuietHours) Until(t time.Time) (time.Time, bool) {
+	for _, offset := range []int{-1, 0} {
+		day := time.Date(t.Year(), t.Month(), t.Day()+offset, 0, 0, 0, 0, t.Location())
+		window := q.windowFor(day.Weekday())
+		if window == nil {
+			continue
+		}
+
+		from := time.Date(day.Year(), day.Month(), day.Day(), window.Start/60, window.Start%60, 0, 0, t.Location())
+		to := time.Date(day.Year(), day.Month(), day.Day(), window.End/60, window.End%60, 0, 0, t.Location())
+		if window.End <= window.Start {
+			to = time.Date(day.Year(), day.Month(), day.Day()+1, window.End/60, window.End%60, 0, 0, t.Location())
+		}
+		if !t.Before(from) && t.Before(to) {
+			return to, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"sort"
 	"strings"
 	"time"
 )
@@ -28,8 +29,12 @@ type SchedulePattern string
 const (
 	SchedulePatternDaily   SchedulePattern = "daily"   // 每天
 	SchedulePatternWeekly  SchedulePattern = "weekly"  // 每周，格式: weekly:1,3,5
-	SchedulePatternMonthly SchedulePattern = "monthly" // 每月，格式: monthly:1,15
+	SchedulePatternMonthly SchedulePattern = "monthly" // 每月，格式: monthly:1,15 / monthly:L / monthly:1#1
+	SchedulePatternYearly  SchedulePattern = "yearly"  // 每年，格式: yearly:12-25
+	SchedulePatternEvery   SchedulePattern = "every"   // 间隔，格式: every:2h@09:00-18:00 / every:3d / every:2w
 	SchedulePatternOnce    SchedulePattern = "once:"   // 一次性前缀，格式: once:2024-10-01
+	SchedulePatternRRule   SchedulePattern = "rrule:"  // RRULE 前缀，格式: rrule:FREQ=WEEKLY;BYDAY=MO,WE,FR
+	SchedulePatternWorkday SchedulePattern = "workday" // 法定工作日（跳过节假日、含调休上班日），格式: workday / workday:CN
 )
 
 // Reminder 提醒配置模型
@@ -39,12 +44,24 @@ type Reminder struct {
 	Title           string       `gorm:"size:500;not null" json:"title"`
 	Description     string       `gorm:"type:text" json:"description"`
 	Type            ReminderType `gorm:"size:20;not null" json:"type"`
-	SchedulePattern string       `gorm:"size:100;not null" json:"schedule_pattern"`
-	TargetTime      string       `gorm:"size:8;not null" json:"target_time"` // HH:MM:SS 格式
+	SchedulePattern string       `gorm:"size:255;not null" json:"schedule_pattern"`
+	TargetTime      string       `gorm:"size:8;not null" json:"target_time"`    // HH:MM:SS 格式
+	ExtraTimes      string       `gorm:"size:255" json:"extra_times,omitempty"` // 额外触发时间，逗号分隔的 HH:MM:SS
 	Timezone        string       `gorm:"size:50" json:"timezone"`
+	UntilDate       string       `gorm:"size:10" json:"until_date,omitempty"`        // 截止日期 YYYY-MM-DD（含当天，按提醒时区）
+	MaxOccurrences  int          `gorm:"default:0" json:"max_occurrences,omitempty"` // 最多触发次数，0 表示不限
+	OccurrenceCount int          `gorm:"default:0" json:"occurrence_count"`          // 已触发次数
 	IsActive        bool         `gorm:"default:true" json:"is_active"`
 	PausedUntil     *time.Time   `gorm:"index" json:"paused_until,omitempty"`
 	PauseReason     string       `gorm:"type:text" json:"pause_reason,omitempty"`
+	QuietResume     bool         `gorm:"default:false" json:"quiet_resume,omitempty"` // 暂停到期后静默恢复，不发送恢复通知
+	FollowUpPolicy  string       `gorm:"size:100" json:"follow_up_policy,omitempty"`  // 关怀策略，为空时沿用用户设置，格式见 FollowUpPolicy
+	Critical        bool         `gorm:"default:false" json:"critical"`               // 重要提醒，不受免打扰时段限制
+	RoutineID       *uint        `gorm:"index" json:"routine_id,omitempty"`           // 所属例程，为空表示独立提醒
+	RoutineStep     int          `gorm:"default:0" json:"routine_step,omitempty"`     // 在例程中的顺序，从1开始
+	RoutineDelay    int          `gorm:"default:0" json:"routine_delay,omitempty"`    // 上一步完成后延迟多少分钟发送
+	LeadTimes       string       `gorm:"size:100" json:"lead_times,omitempty"`        // 提前通知，逗号分隔的提前量，如 1d,1h,10m
+	Channels        string       `gorm:"size:100" json:"channels,omitempty"`          // 通知渠道，为空时沿用用户设置
 	CreatedAt       time.Time    `json:"created_at"`
 	UpdatedAt       time.Time    `json:"updated_at"`
 
@@ -68,16 +85,117 @@ func (r *Reminder) IsWeekly() bool {
 	return len(r.SchedulePattern) > 7 && r.SchedulePattern[:7] == "weekly:"
 }
 
+// IsMonthly 检查是否为每月提醒
+func (r *Reminder) IsMonthly() bool {
+	return len(r.SchedulePattern) > 8 && r.SchedulePattern[:8] == "monthly:"
+}
+
+// IsYearly 检查是否为每年提醒
+func (r *Reminder) IsYearly() bool {
+	return len(r.SchedulePattern) > 7 && r.SchedulePattern[:7] == "yearly:"
+}
+
+// IsInterval 检查是否为间隔提醒
+func (r *Reminder) IsInterval() bool {
+	return len(r.SchedulePattern) > 6 && r.SchedulePattern[:6] == "every:"
+}
+
+// IsRRule 检查是否为 RRULE 自定义规则提醒
+func (r *Reminder) IsRRule() bool {
+	return strings.HasPrefix(r.SchedulePattern, string(SchedulePatternRRule)) &&
+		len(r.SchedulePattern) > len(string(SchedulePatternRRule))
+}
+
+// IsWorkday 检查是否为法定工作日提醒
+func (r *Reminder) IsWorkday() bool {
+	return r.SchedulePattern == string(SchedulePatternWorkday) ||
+		strings.HasPrefix(r.SchedulePattern, string(SchedulePatternWorkday)+":")
+}
+
+// WorkdayRegion 返回工作日提醒指定的节假日地区代码，未指定时返回空字符串
+func (r *Reminder) WorkdayRegion() string {
+	if !r.IsWorkday() {
+		return ""
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(r.SchedulePattern, string(SchedulePatternWorkday)), ":")
+}
+
+// IsRoutineStep 检查是否属于某个例程
+func (r *Reminder) IsRoutineStep() bool {
+	return r.RoutineID != nil
+}
+
+// IsChainedStep 检查是否为例程中由上一步完成触发的步骤，这类提醒不按自身计划调度
+func (r *Reminder) IsChainedStep() bool {
+	return r.RoutineID != nil && r.RoutineStep > 1
+}
+
 // IsOnce 检查是否为一次性提醒
 func (r *Reminder) IsOnce() bool {
 	return strings.HasPrefix(r.SchedulePattern, string(SchedulePatternOnce)) &&
 		len(r.SchedulePattern) > len(string(SchedulePatternOnce))
 }
 
+// Times 返回提醒的全部触发时间（HH:MM:SS），TargetTime 在前
+func (r *Reminder) Times() []string {
+	times := []string{r.TargetTime}
+	for _, t := range strings.Split(r.ExtraTimes, ",") {
+		if t = strings.TrimSpace(t); t != "" && t != r.TargetTime {
+			times = append(times, t)
+		}
+	}
+	return times
+}
+
+// SetTimes 设置触发时间：去重并按时间先后排序，最早的写入 TargetTime，其余写入 ExtraTimes
+func (r *Reminder) SetTimes(times []string) {
+	seen := make(map[string]bool, len(times))
+	var sorted []string
+	for _, t := range times {
+		if t = strings.TrimSpace(t); t != "" && !seen[t] {
+			seen[t] = true
+			sorted = append(sorted, t)
+		}
+	}
+	if len(sorted) == 0 {
+		return
+	}
+	sort.Strings(sorted)
+
+	r.TargetTime = sorted[0]
+	r.ExtraTimes = strings.Join(sorted[1:], ",")
+}
+
+// HasEndCondition 检查是否设置了截止日期或最多触发次数
+func (r *Reminder) HasEndCondition() bool {
+	return r.UntilDate != "" || r.MaxOccurrences > 0
+}
+
+// ReachedMaxOccurrences 检查是否已达到最多触发次数
+func (r *Reminder) ReachedMaxOccurrences() bool {
+	return r.MaxOccurrences > 0 && r.OccurrenceCount >= r.MaxOccurrences
+}
+
+// RemainingOccurrences 返回剩余可触发次数，-1 表示不限
+func (r *Reminder) RemainingOccurrences() int {
+	if r.MaxOccurrences <= 0 {
+		return -1
+	}
+	if remaining := r.MaxOccurrences - r.OccurrenceCount; remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
 // IsPaused 检查是否处于暂停状态
 func (r *Reminder) IsPaused() bool {
+	return r.IsPausedAt(time.Now())
+}
+
+// IsPausedAt 检查在指定时间是否处于暂停状态
+func (r *Reminder) IsPausedAt(now time.Time) bool {
 	if r.PausedUntil == nil {
 		return false
 	}
-	return time.Now().Before(*r.PausedUntil)
+	return now.Before(*r.PausedUntil)
 }
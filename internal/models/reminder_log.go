@@ -19,13 +19,15 @@ const (
 // ReminderLog 提醒记录模型
 type ReminderLog struct {
 	ID             uint           `gorm:"primaryKey;autoIncrement" json:"id"`
-	ReminderID     uint           `gorm:"not null;index" json:"reminder_id"`
+	ReminderID     uint           `gorm:"not null;index;uniqueIndex:idx_reminder_occurrence" json:"reminder_id"`
 	ScheduledTime  time.Time      `gorm:"not null" json:"scheduled_time"`
+	OccurrenceAt   *time.Time     `gorm:"uniqueIndex:idx_reminder_occurrence" json:"occurrence_at"` // 计划触发时刻，每次触发只有一条记录；延期产生的记录为空
 	SentTime       *time.Time     `json:"sent_time"`
 	Status         ReminderStatus `gorm:"size:20;default:'pending'" json:"status"`
 	UserResponse   string         `gorm:"type:text" json:"user_response"`
 	ResponseTime   *time.Time     `json:"response_time"`
 	FollowUpCount  int            `gorm:"default:0" json:"follow_up_count"`
+	LastFollowUpAt *time.Time     `json:"last_follow_up_at"` // 最近一次关怀时间，下一次关怀从此时起算
 	CreatedAt      time.Time      `json:"created_at"`
 
 	// 关联关系
@@ -37,6 +39,12 @@ func (ReminderLog) TableName() string {
 	return "reminder_logs"
 }
 
+// OccurrenceKey 规范化计划触发时刻：统一为 UTC 并截断到秒，保证同一次触发得到相同的唯一键
+func OccurrenceKey(t time.Time) *time.Time {
+	key := t.UTC().Truncate(time.Second)
+	return &key
+}
+
 // IsCompleted 检查是否已完成
 func (rl *ReminderLog) IsCompleted() bool {
 	return rl.Status == ReminderStatusCompleted
@@ -49,9 +57,13 @@ func (rl *ReminderLog) IsOverdue() bool {
 
 // MarkAsSent 标记为已发送
 func (rl *ReminderLog) MarkAsSent() {
-	now := time.Now()
+	rl.MarkAsSentAt(time.Now())
+}
+
+// MarkAsSentAt 标记为在指定时间已发送
+func (rl *ReminderLog) MarkAsSentAt(sentAt time.Time) {
 	rl.Status = ReminderStatusSent
-	rl.SentTime = &now
+	rl.SentTime = &sentAt
 }
 
 // MarkAsCompleted 标记为已完成
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// ReminderMessage 为某次提醒发送的 Telegram 消息，用户响应后据此更新消息并移除按钮
+type ReminderMessage struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	ReminderLogID uint      `gorm:"not null;index" json:"reminder_log_id"`
+	ChatID        int64     `gorm:"not null" json:"chat_id"`
+	MessageID     int       `gorm:"not null" json:"message_id"`
+	Kind          string    `gorm:"size:20" json:"kind"` // 消息类型: reminder 或 follow_up
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (ReminderMessage) TableName() string {
+	return "reminder_messages"
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// Routine 例程：按顺序执行的一组提醒，如 喝水 → 拉伸 → 写日记
+// 第一步按自身的计划触发，之后每一步在上一步完成后发送（可设置延迟），最后一步完成即完成一次例程
+type Routine struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	Name      string    `gorm:"size:255;not null" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// 关联关系
+	Steps []Reminder `gorm:"foreignKey:RoutineID" json:"steps,omitempty"`
+}
+
+// TableName 指定表名
+func (Routine) TableName() string {
+	return "routines"
+}
+
+// OrderedSteps 返回按步骤顺序排列的提醒
+func (r *Routine) OrderedSteps() []*Reminder {
+	steps := make([]*Reminder, 0, len(r.Steps))
+	for i := range r.Steps {
+		steps = append(steps, &r.Steps[i])
+	}
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].RoutineStep < steps[j].RoutineStep
+	})
+	return steps
+}
+
+// NextStep 返回 step 之后第一个仍然有效的步骤，没有时返回 nil
+func (r *Routine) NextStep(step int) *Reminder {
+	for _, reminder := range r.OrderedSteps() {
+		if reminder.RoutineStep > step && reminder.IsActive {
+			return reminder
+		}
+	}
+	return nil
+}
+
+// LastStep 返回最后一个有效步骤，完成该步骤即完成一次例程
+func (r *Routine) LastStep() *Reminder {
+	steps := r.OrderedSteps()
+	for i := len(steps) - 1; i >= 0; i-- {
+		if steps[i].IsActive {
+			return steps[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,270 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MonthlyLastDay 每月最后一天的标记，格式: monthly:L
+const MonthlyLastDay = "L"
+
+// NthWeekday 每月第 N 个星期几，N 为 -1 表示最后一个
+type NthWeekday struct {
+	Weekday time.Weekday
+	N       int
+}
+
+// MonthDay 每年的某月某日
+type MonthDay struct {
+	Month time.Month
+	Day   int
+}
+
+// CalendarRule 按日历日期匹配的调度规则（每月/每年模式）
+type CalendarRule struct {
+	Days        []int        // 每月的第几天
+	LastDay     bool         // 每月最后一天
+	NthWeekdays []NthWeekday // 每月第 N 个星期几
+	Dates       []MonthDay   // 每年的指定日期
+}
+
+// IntervalUnit 间隔单位
+type IntervalUnit string
+
+const (
+	IntervalHour IntervalUnit = "h" // 小时
+	IntervalDay  IntervalUnit = "d" // 天
+	IntervalWeek IntervalUnit = "w" // 周
+)
+
+// IntervalRule 间隔调度规则
+type IntervalRule struct {
+	Every       int
+	Unit        IntervalUnit
+	HasWindow   bool
+	WindowStart int // 时间窗口开始，距零点的分钟数
+	WindowEnd   int // 时间窗口结束，距零点的分钟数
+}
+
+// ParseIntervalPattern 解析间隔模式
+// 支持: every:2h（每2小时）、every:2h@09:00-18:00（9点到18点之间每2小时）、
+// every:3d（每3天）、every:2w（每2周，即隔周）
+func ParseIntervalPattern(pattern string) (*IntervalRule, error) {
+	prefix := string(SchedulePatternEvery) + ":"
+	if !strings.HasPrefix(pattern, prefix) {
+		return nil, fmt.Errorf("无效的间隔模式: %s", pattern)
+	}
+
+	spec := strings.TrimPrefix(pattern, prefix)
+	window := ""
+	if idx := strings.Index(spec, "@"); idx >= 0 {
+		spec, window = spec[:idx], spec[idx+1:]
+	}
+
+	if len(spec) < 2 {
+		return nil, fmt.Errorf("无效的间隔模式: %s", pattern)
+	}
+
+	rule := &IntervalRule{Unit: IntervalUnit(spec[len(spec)-1:])}
+	every, err := strconv.Atoi(spec[:len(spec)-1])
+	if err != nil || every < 1 {
+		return nil, fmt.Errorf("无效的间隔: %s", spec)
+	}
+	rule.Every = every
+
+	switch rule.Unit {
+	case IntervalHour:
+		if every > 23 {
+			return nil, fmt.Errorf("小时间隔不能超过23: %s", spec)
+		}
+	case IntervalDay, IntervalWeek:
+		if window != "" {
+			return nil, fmt.Errorf("按天或按周的间隔不支持时间窗口: %s", pattern)
+		}
+	default:
+		return nil, fmt.Errorf("无效的间隔单位: %s", spec)
+	}
+
+	if window != "" {
+		bounds := strings.Split(window, "-")
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("无效的时间窗口: %s", window)
+		}
+		start, err := parseClock(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseClock(bounds[1])
+		if err != nil {
+			return nil, err
+		}
+		if end < start {
+			return nil, fmt.Errorf("时间窗口结束时间早于开始时间: %s", window)
+		}
+		rule.HasWindow = true
+		rule.WindowStart = start
+		rule.WindowEnd = end
+	}
+
+	return rule, nil
+}
+
+// ParseMonthlyPattern 解析每月模式
+// 支持: monthly:1,15（每月1日和15日）、monthly:L（每月最后一天）、
+// monthly:1#1（每月第一个周一）、monthly:5#L（每月最后一个周五），可用逗号组合
+func ParseMonthlyPattern(pattern string) (*CalendarRule, error) {
+	prefix := string(SchedulePatternMonthly) + ":"
+	if !strings.HasPrefix(pattern, prefix) {
+		return nil, fmt.Errorf("无效的每月模式: %s", pattern)
+	}
+
+	rule := &CalendarRule{}
+	for _, item := range splitPatternItems(strings.TrimPrefix(pattern, prefix)) {
+		switch {
+		case strings.EqualFold(item, MonthlyLastDay):
+			rule.LastDay = true
+
+		case strings.Contains(item, "#"):
+			parts := strings.SplitN(item, "#", 2)
+			weekday, err := strconv.Atoi(parts[0])
+			if err != nil || weekday < 0 || weekday > 7 {
+				return nil, fmt.Errorf("无效的星期数字: %s", item)
+			}
+
+			n := -1
+			if !strings.EqualFold(parts[1], MonthlyLastDay) {
+				n, err = strconv.Atoi(parts[1])
+				if err != nil || n < 1 || n > 5 {
+					return nil, fmt.Errorf("无效的周序号: %s", item)
+				}
+			}
+			rule.NthWeekdays = append(rule.NthWeekdays, NthWeekday{Weekday: time.Weekday(weekday % 7), N: n})
+
+		default:
+			day, err := strconv.Atoi(item)
+			if err != nil || day < 1 || day > 31 {
+				return nil, fmt.Errorf("无效的日期: %s", item)
+			}
+			rule.Days = append(rule.Days, day)
+		}
+	}
+
+	if rule.isEmpty() {
+		return nil, fmt.Errorf("无效的每月模式: %s", pattern)
+	}
+	return rule, nil
+}
+
+// ParseYearlyPattern 解析每年模式，格式: yearly:12-25 或 yearly:02-14,12-25
+func ParseYearlyPattern(pattern string) (*CalendarRule, error) {
+	prefix := string(SchedulePatternYearly) + ":"
+	if !strings.HasPrefix(pattern, prefix) {
+		return nil, fmt.Errorf("无效的每年模式: %s", pattern)
+	}
+
+	rule := &CalendarRule{}
+	for _, item := range splitPatternItems(strings.TrimPrefix(pattern, prefix)) {
+		parts := strings.Split(item, "-")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("无效的日期: %s", item)
+		}
+
+		month, err := strconv.Atoi(parts[0])
+		if err != nil || month < 1 || month > 12 {
+			return nil, fmt.Errorf("无效的月份: %s", item)
+		}
+		day, err := strconv.Atoi(parts[1])
+		if err != nil || day < 1 || day > daysIn(time.Month(month), 2000) {
+			return nil, fmt.Errorf("无效的日期: %s", item)
+		}
+
+		rule.Dates = append(rule.Dates, MonthDay{Month: time.Month(month), Day: day})
+	}
+
+	if rule.isEmpty() {
+		return nil, fmt.Errorf("无效的每年模式: %s", pattern)
+	}
+	return rule, nil
+}
+
+// ParseCalendarPattern 解析每月或每年模式
+func ParseCalendarPattern(pattern string) (*CalendarRule, error) {
+	if strings.HasPrefix(pattern, string(SchedulePatternYearly)+":") {
+		return ParseYearlyPattern(pattern)
+	}
+	return ParseMonthlyPattern(pattern)
+}
+
+// Matches 判断给定日期是否满足规则（只看年月日）
+func (r *CalendarRule) Matches(date time.Time) bool {
+	year, month, day := date.Date()
+	lastDay := daysIn(month, year)
+
+	for _, d := range r.Days {
+		if d == day {
+			return true
+		}
+	}
+
+	if r.LastDay && day == lastDay {
+		return true
+	}
+
+	for _, nth := range r.NthWeekdays {
+		if date.Weekday() != nth.Weekday {
+			continue
+		}
+		if nth.N == -1 && day+7 > lastDay {
+			return true
+		}
+		if nth.N > 0 && (day-1)/7+1 == nth.N {
+			return true
+		}
+	}
+
+	for _, md := range r.Dates {
+		if md.Month == month && md.Day == day {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *CalendarRule) isEmpty() bool {
+	return len(r.Days) == 0 && !r.LastDay && len(r.NthWeekdays) == 0 && len(r.Dates) == 0
+}
+
+func splitPatternItems(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseClock 解析 "HH:MM" 为距零点的分钟数
+func parseClock(clock string) (int, error) {
+	parts := strings.Split(strings.TrimSpace(clock), ":")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("无效的时间: %s", clock)
+	}
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, fmt.Errorf("无效的时间: %s", clock)
+	}
+	minute, err := strconv.Atoi(parts[1])
+	if err != nil || minute < 0 || minute > 59 {
+		return 0, fmt.Errorf("无效的时间: %s", clock)
+	}
+	return hour*60 + minute, nil
+}
+
+// daysIn 返回指定年月的天数
+func daysIn(month time.Month, year int) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// SchedulerLease 调度租约，多实例部署时只有持有租约的实例触发提醒
+type SchedulerLease struct {
+	Name      string    `gorm:"primaryKey;size:64" json:"name"`
+	Holder    string    `gorm:"size:128;not null" json:"holder"` // 持有者实例ID
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (SchedulerLease) TableName() string {
+	return "scheduler_leases"
+}
+
+// IsExpired 检查租约是否已过期
+func (l *SchedulerLease) IsExpired(now time.Time) bool {
+	return !now.Before(l.ExpiresAt)
+}
@@ -6,16 +6,22 @@ import (
 
 // User 用户模型
 type User struct {
-	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	TelegramID   int64     `gorm:"uniqueIndex;not null" json:"telegram_id"`
-	Username     string    `gorm:"size:255" json:"username"`
-	FirstName    string    `gorm:"size:255" json:"first_name"`
-	LastName     string    `gorm:"size:255" json:"last_name"`
-	Timezone     string    `gorm:"size:50;default:'Asia/Shanghai'" json:"timezone"`
-	LanguageCode string    `gorm:"size:10;default:'zh-CN'" json:"language_code"`
-	IsActive     bool      `gorm:"default:true" json:"is_active"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TelegramID     int64     `gorm:"uniqueIndex;not null" json:"telegram_id"`
+	Username       string    `gorm:"size:255" json:"username"`
+	FirstName      string    `gorm:"size:255" json:"first_name"`
+	LastName       string    `gorm:"size:255" json:"last_name"`
+	Timezone       string    `gorm:"size:50;default:'Asia/Shanghai'" json:"timezone"`
+	LanguageCode   string    `gorm:"size:10;default:'zh-CN'" json:"language_code"`
+	FollowUpPolicy string    `gorm:"size:100" json:"follow_up_policy,omitempty"` // 默认关怀策略，为空时使用系统默认
+	QuietHours     string    `gorm:"size:100" json:"quiet_hours,omitempty"`      // 免打扰时段，为空表示不限制，格式见 QuietHours
+	Channels       string    `gorm:"size:100" json:"channels,omitempty"`         // 通知渠道，逗号分隔，依次尝试，为空时使用系统默认
+	Email          string    `gorm:"size:255" json:"email,omitempty"`            // 邮件渠道的收件地址
+	WebhookURL     string    `gorm:"size:500" json:"webhook_url,omitempty"`      // Webhook 渠道的回调地址
+	AgendaTime     string    `gorm:"size:5" json:"agenda_time,omitempty"`        // 每日日程推送时间 HH:MM（用户时区），为空表示不推送
+	IsActive       bool      `gorm:"default:true" json:"is_active"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 
 	// 关联关系
 	Reminders     []Reminder     `gorm:"foreignKey:UserID" json:"reminders,omitempty"`
@@ -2,6 +2,8 @@ package interfaces
 
 import (
 	"context"
+	"time"
+
 	"mmemory/internal/models"
 )
 
@@ -22,6 +24,8 @@ type ReminderRepository interface {
 	GetByUserID(ctx context.Context, userID uint) ([]*models.Reminder, error)
 	GetActiveReminders(ctx context.Context) ([]*models.Reminder, error)
 	Update(ctx context.Context, reminder *models.Reminder) error
+	// UpdateOccurrenceCount 只更新已触发次数，不改动 updated_at，避免调度器把每次触发当作计划变更
+	UpdateOccurrenceCount(ctx context.Context, id uint, count int) error
 	Delete(ctx context.Context, id uint) error
 	CountByStatus(ctx context.Context, status models.ReminderStatStatus) (int64, error)
 }
@@ -29,13 +33,27 @@ type ReminderRepository interface {
 // ReminderLogRepository 提醒记录仓储接口
 type ReminderLogRepository interface {
 	Create(ctx context.Context, log *models.ReminderLog) error
+	// Claim 认领一次触发：(reminder_id, occurrence_at) 不存在时写入记录并返回 true，已被认领时返回 false
+	Claim(ctx context.Context, log *models.ReminderLog) (bool, error)
+	// Complete 记录尚未完成时标记为已完成并返回 true，已完成时不修改并返回 false
+	Complete(ctx context.Context, id uint, response string, at time.Time) (bool, error)
 	GetByID(ctx context.Context, id uint) (*models.ReminderLog, error)
 	GetByReminderID(ctx context.Context, reminderID uint, limit, offset int) ([]*models.ReminderLog, error)
+	// GetByUserBetween 返回用户全部提醒中计划时间或计划触发时刻落在 [from, to) 内的记录，按计划时间排序
+	GetByUserBetween(ctx context.Context, userID uint, from, to time.Time) ([]*models.ReminderLog, error)
 	GetPendingLogs(ctx context.Context) ([]*models.ReminderLog, error)
 	Update(ctx context.Context, log *models.ReminderLog) error
 	Delete(ctx context.Context, id uint) error
 }
 
+// ReminderMessageRepository 提醒消息仓储接口，记录每次提醒发出的 Telegram 消息
+type ReminderMessageRepository interface {
+	Create(ctx context.Context, message *models.ReminderMessage) error
+	// GetByLogID 按发送顺序返回提醒记录的全部消息
+	GetByLogID(ctx context.Context, logID uint) ([]*models.ReminderMessage, error)
+	DeleteByLogID(ctx context.Context, logID uint) error
+}
+
 // ConversationRepository 对话仓储接口
 type ConversationRepository interface {
 	Create(ctx context.Context, conversation *models.Conversation) error
@@ -43,4 +61,22 @@ type ConversationRepository interface {
 	Update(ctx context.Context, conversation *models.Conversation) error
 	Delete(ctx context.Context, id uint) error
 	DeleteExpired(ctx context.Context) error
-}
\ No newline at end of file
+}
+
+// LeaseRepository 调度租约仓储接口
+type LeaseRepository interface {
+	// Acquire 获取或续期租约：租约不存在、已过期或本就由 holder 持有时成功
+	Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	// Release 释放 holder 持有的租约
+	Release(ctx context.Context, name, holder string) error
+	Get(ctx context.Context, name string) (*models.SchedulerLease, error)
+}
+
+// RoutineRepository 例程仓储接口
+type RoutineRepository interface {
+	Create(ctx context.Context, routine *models.Routine) error
+	// GetByID 返回例程及其全部步骤
+	GetByID(ctx context.Context, id uint) (*models.Routine, error)
+	GetByUserID(ctx context.Context, userID uint) ([]*models.Routine, error)
+	Delete(ctx context.Context, id uint) error
+}
@@ -53,7 +53,10 @@ func (d *Database) AutoMigrate() error {
 		&models.User{},
 		&models.Reminder{},
 		&models.ReminderLog{},
+		&models.ReminderMessage{},
 		&models.Conversation{},
+		&models.SchedulerLease{},
+		&models.Routine{},
 	)
 }
 
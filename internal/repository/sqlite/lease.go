@@ -0,0 +1,68 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"mmemory/internal/models"
+	"mmemory/internal/repository/interfaces"
+)
+
+type leaseRepository struct {
+	db *gorm.DB
+}
+
+func NewLeaseRepository(db *gorm.DB) interfaces.LeaseRepository {
+	return &leaseRepository{db: db}
+}
+
+// Acquire 先尝试续期或抢占过期租约，租约不存在时再插入，两步均为单条语句保证原子性
+func (r *leaseRepository) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	result := r.db.WithContext(ctx).
+		Model(&models.SchedulerLease{}).
+		Where("name = ? AND (holder = ? OR expires_at <= ?)", name, holder, now).
+		Updates(map[string]interface{}{
+			"holder":     holder,
+			"expires_at": expiresAt,
+			"updated_at": now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+
+	result = r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.SchedulerLease{Name: name, Holder: holder, ExpiresAt: expiresAt, UpdatedAt: now})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *leaseRepository) Release(ctx context.Context, name, holder string) error {
+	return r.db.WithContext(ctx).
+		Where("name = ? AND holder = ?", name, holder).
+		Delete(&models.SchedulerLease{}).Error
+}
+
+func (r *leaseRepository) Get(ctx context.Context, name string) (*models.SchedulerLease, error) {
+	var lease models.SchedulerLease
+	err := r.db.WithContext(ctx).Where("name = ?", name).First(&lease).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &lease, nil
+}
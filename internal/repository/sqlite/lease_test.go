@@ -0,0 +1,57 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"mmemory/internal/models"
+)
+
+func TestLeaseRepository(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.SchedulerLease{}))
+
+	repo := NewLeaseRepository(db)
+	ctx := context.Background()
+	ttl := 200 * time.Millisecond
+
+	acquired, err := repo.Acquire(ctx, "scheduler", "a", ttl)
+	require.NoError(t, err)
+	assert.True(t, acquired, "租约不存在时应获取成功")
+
+	acquired, err = repo.Acquire(ctx, "scheduler", "b", ttl)
+	require.NoError(t, err)
+	assert.False(t, acquired, "租约未过期时其他实例不能获取")
+
+	acquired, err = repo.Acquire(ctx, "scheduler", "a", ttl)
+	require.NoError(t, err)
+	assert.True(t, acquired, "持有者应能续期")
+
+	time.Sleep(ttl + 50*time.Millisecond)
+	acquired, err = repo.Acquire(ctx, "scheduler", "b", ttl)
+	require.NoError(t, err)
+	assert.True(t, acquired, "租约过期后其他实例应能接管")
+
+	lease, err := repo.Get(ctx, "scheduler")
+	require.NoError(t, err)
+	require.NotNil(t, lease)
+	assert.Equal(t, "b", lease.Holder)
+
+	// 非持有者释放无效
+	require.NoError(t, repo.Release(ctx, "scheduler", "a"))
+	acquired, err = repo.Acquire(ctx, "scheduler", "a", ttl)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	require.NoError(t, repo.Release(ctx, "scheduler", "b"))
+	acquired, err = repo.Acquire(ctx, "scheduler", "a", ttl)
+	require.NoError(t, err)
+	assert.True(t, acquired, "释放后应能立即获取")
+}
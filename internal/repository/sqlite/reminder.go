@@ -50,6 +50,10 @@ func (r *reminderRepository) Update(ctx context.Context, reminder *models.Remind
 	return r.db.WithContext(ctx).Save(reminder).Error
 }
 
+func (r *reminderRepository) UpdateOccurrenceCount(ctx context.Context, id uint, count int) error {
+	return r.db.WithContext(ctx).Model(&models.Reminder{}).Where("id = ?", id).UpdateColumn("occurrence_count", count).Error
+}
+
 func (r *reminderRepository) Delete(ctx context.Context, id uint) error {
 	return r.db.WithContext(ctx).Delete(&models.Reminder{}, id).Error
 }
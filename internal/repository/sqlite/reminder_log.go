@@ -3,8 +3,12 @@ package sqlite
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"mmemory/internal/models"
 	"mmemory/internal/repository/interfaces"
@@ -22,6 +26,37 @@ func (r *reminderLogRepository) Create(ctx context.Context, log *models.Reminder
 	return r.db.WithContext(ctx).Create(log).Error
 }
 
+func (r *reminderLogRepository) Claim(ctx context.Context, log *models.ReminderLog) (bool, error) {
+	if log.OccurrenceAt == nil {
+		return false, fmt.Errorf("缺少计划触发时刻")
+	}
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "reminder_id"}, {Name: "occurrence_at"}},
+			DoNothing: true,
+		}).
+		Create(log)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *reminderLogRepository) Complete(ctx context.Context, id uint, response string, at time.Time) (bool, error) {
+	// 条件更新保证重复点击或并发回调只有一次生效
+	result := r.db.WithContext(ctx).Model(&models.ReminderLog{}).
+		Where("id = ? AND status <> ?", id, models.ReminderStatusCompleted).
+		Updates(map[string]interface{}{
+			"status":        models.ReminderStatusCompleted,
+			"user_response": response,
+			"response_time": at,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
 func (r *reminderLogRepository) GetByID(ctx context.Context, id uint) (*models.ReminderLog, error) {
 	var log models.ReminderLog
 	err := r.db.WithContext(ctx).
@@ -52,6 +87,32 @@ func (r *reminderLogRepository) GetByReminderID(ctx context.Context, reminderID
 	return logs, err
 }
 
+func (r *reminderLogRepository) GetByUserBetween(ctx context.Context, userID uint, from, to time.Time) ([]*models.ReminderLog, error) {
+	// SQLite 按文本比较时间，以不同时区偏移写入的记录可能错位，先按放宽一天的范围查询，再精确过滤和排序
+	lo, hi := from.UTC().AddDate(0, 0, -1), to.UTC().AddDate(0, 0, 1)
+
+	var candidates []*models.ReminderLog
+	err := r.db.WithContext(ctx).
+		Where("reminder_id IN (?)", r.db.Model(&models.Reminder{}).Select("id").Where("user_id = ?", userID)).
+		Where("(scheduled_time >= ? AND scheduled_time < ?) OR (occurrence_at >= ? AND occurrence_at < ?)", lo, hi, lo, hi).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	within := func(t time.Time) bool { return !t.Before(from) && t.Before(to) }
+	logs := make([]*models.ReminderLog, 0, len(candidates))
+	for _, log := range candidates {
+		if within(log.ScheduledTime) || (log.OccurrenceAt != nil && within(*log.OccurrenceAt)) {
+			logs = append(logs, log)
+		}
+	}
+	sort.SliceStable(logs, func(i, j int) bool {
+		return logs[i].ScheduledTime.Before(logs[j].ScheduledTime)
+	})
+	return logs, nil
+}
+
 func (r *reminderLogRepository) GetPendingLogs(ctx context.Context) ([]*models.ReminderLog, error) {
 	var logs []*models.ReminderLog
 	err := r.db.WithContext(ctx).
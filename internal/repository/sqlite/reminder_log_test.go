@@ -0,0 +1,142 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"mmemory/internal/models"
+)
+
+func TestReminderLogRepository_Claim(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Reminder{}, &models.ReminderLog{}))
+
+	repo := NewReminderLogRepository(db)
+	ctx := context.Background()
+
+	shanghai := time.FixedZone("UTC+8", 8*3600)
+	occurrence := time.Date(2026, 5, 1, 9, 0, 0, 0, shanghai)
+
+	claimed, err := repo.Claim(ctx, &models.ReminderLog{
+		ReminderID:    1,
+		ScheduledTime: occurrence,
+		OccurrenceAt:  models.OccurrenceKey(occurrence),
+		Status:        models.ReminderStatusPending,
+	})
+	require.NoError(t, err)
+	assert.True(t, claimed, "首次认领应成功")
+
+	// 同一时刻以其他时区和亚秒差异表示，仍视为同一次触发
+	duplicate := &models.ReminderLog{
+		ReminderID:    1,
+		ScheduledTime: occurrence,
+		OccurrenceAt:  models.OccurrenceKey(occurrence.UTC().Add(300 * time.Millisecond)),
+		Status:        models.ReminderStatusPending,
+	}
+	claimed, err = repo.Claim(ctx, duplicate)
+	require.NoError(t, err)
+	assert.False(t, claimed, "同一次触发不能重复认领")
+
+	claimed, err = repo.Claim(ctx, &models.ReminderLog{
+		ReminderID:    2,
+		ScheduledTime: occurrence,
+		OccurrenceAt:  models.OccurrenceKey(occurrence),
+		Status:        models.ReminderStatusPending,
+	})
+	require.NoError(t, err)
+	assert.True(t, claimed, "不同提醒的同一时刻互不影响")
+
+	// 延期记录没有计划触发时刻，不受唯一约束限制
+	for i := 0; i < 2; i++ {
+		require.NoError(t, repo.Create(ctx, &models.ReminderLog{ReminderID: 1, ScheduledTime: occurrence.Add(time.Hour)}))
+	}
+
+	_, err = repo.Claim(ctx, &models.ReminderLog{ReminderID: 1, ScheduledTime: occurrence})
+	assert.Error(t, err, "缺少计划触发时刻应返回错误")
+
+	var count int64
+	require.NoError(t, db.Model(&models.ReminderLog{}).Where("reminder_id = ?", 1).Count(&count).Error)
+	assert.Equal(t, int64(3), count)
+}
+
+func TestReminderLogRepository_Complete(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Reminder{}, &models.ReminderLog{}))
+
+	repo := NewReminderLogRepository(db)
+	ctx := context.Background()
+
+	log := &models.ReminderLog{ReminderID: 1, ScheduledTime: time.Now(), Status: models.ReminderStatusSent}
+	require.NoError(t, repo.Create(ctx, log))
+
+	at := time.Date(2026, 5, 1, 9, 5, 0, 0, time.UTC)
+	completed, err := repo.Complete(ctx, log.ID, "用户确认完成", at)
+	require.NoError(t, err)
+	assert.True(t, completed, "未完成的记录应标记成功")
+
+	// 重复点击不再生效，也不覆盖第一次的回复
+	completed, err = repo.Complete(ctx, log.ID, "再次确认", at.Add(time.Minute))
+	require.NoError(t, err)
+	assert.False(t, completed, "已完成的记录不应重复标记")
+
+	var stored models.ReminderLog
+	require.NoError(t, db.First(&stored, log.ID).Error)
+	assert.Equal(t, models.ReminderStatusCompleted, stored.Status)
+	assert.Equal(t, "用户确认完成", stored.UserResponse)
+	require.NotNil(t, stored.ResponseTime)
+	assert.True(t, stored.ResponseTime.Equal(at))
+
+	completed, err = repo.Complete(ctx, 999, "不存在", at)
+	require.NoError(t, err)
+	assert.False(t, completed)
+}
+
+func TestReminderLogRepository_GetByUserBetween(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Reminder{}, &models.ReminderLog{}))
+
+	repo := NewReminderLogRepository(db)
+	ctx := context.Background()
+
+	mine := &models.Reminder{UserID: 1, Title: "站会", SchedulePattern: "daily", TargetTime: "09:00:00"}
+	others := &models.Reminder{UserID: 2, Title: "跑步", SchedulePattern: "daily", TargetTime: "09:00:00"}
+	require.NoError(t, db.Create(mine).Error)
+	require.NoError(t, db.Create(others).Error)
+
+	shanghai := time.FixedZone("UTC+8", 8*3600)
+	from := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 2)
+
+	create := func(reminderID uint, scheduled time.Time, occurrence *time.Time) *models.ReminderLog {
+		log := &models.ReminderLog{ReminderID: reminderID, ScheduledTime: scheduled, OccurrenceAt: occurrence, Status: models.ReminderStatusSent}
+		require.NoError(t, repo.Create(ctx, log))
+		return log
+	}
+	inRange := create(mine.ID, from.Add(9*time.Hour), models.OccurrenceKey(from.Add(9*time.Hour)))
+	// 以其他时区写入：本地 5月1日 07:00 即 UTC 4月30日 23:00，不在范围内
+	create(mine.ID, time.Date(2026, 5, 1, 7, 0, 0, 0, shanghai), nil)
+	// 本地 5月3日 07:00 即 UTC 5月2日 23:00，在范围内
+	lateLocal := create(mine.ID, time.Date(2026, 5, 3, 7, 0, 0, 0, shanghai), nil)
+	// 免打扰推迟到范围之后，但计划触发时刻在范围内
+	deferred := create(mine.ID, to.Add(time.Hour), models.OccurrenceKey(to.Add(-time.Hour)))
+	create(mine.ID, to, models.OccurrenceKey(to))
+	create(others.ID, from.Add(9*time.Hour), models.OccurrenceKey(from.Add(9*time.Hour)))
+
+	logs, err := repo.GetByUserBetween(ctx, 1, from, to)
+	require.NoError(t, err)
+
+	var ids []uint
+	for _, log := range logs {
+		ids = append(ids, log.ID)
+	}
+	assert.Equal(t, []uint{inRange.ID, lateLocal.ID, deferred.ID}, ids)
+}
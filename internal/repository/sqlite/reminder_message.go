@@ -0,0 +1,35 @@
+package sqlite
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"mmemory/internal/models"
+	"mmemory/internal/repository/interfaces"
+)
+
+type reminderMessageRepository struct {
+	db *gorm.DB
+}
+
+func NewReminderMessageRepository(db *gorm.DB) interfaces.ReminderMessageRepository {
+	return &reminderMessageRepository{db: db}
+}
+
+func (r *reminderMessageRepository) Create(ctx context.Context, message *models.ReminderMessage) error {
+	return r.db.WithContext(ctx).Create(message).Error
+}
+
+func (r *reminderMessageRepository) GetByLogID(ctx context.Context, logID uint) ([]*models.ReminderMessage, error) {
+	var messages []*models.ReminderMessage
+	err := r.db.WithContext(ctx).
+		Where("reminder_log_id = ?", logID).
+		Order("id ASC").
+		Find(&messages).Error
+	return messages, err
+}
+
+func (r *reminderMessageRepository) DeleteByLogID(ctx context.Context, logID uint) error {
+	return r.db.WithContext(ctx).Where("reminder_log_id = ?", logID).Delete(&models.ReminderMessage{}).Error
+}
@@ -0,0 +1,45 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"mmemory/internal/models"
+)
+
+func TestReminderMessageRepository(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.ReminderMessage{}))
+
+	repo := NewReminderMessageRepository(db)
+	ctx := context.Background()
+
+	for _, message := range []*models.ReminderMessage{
+		{ReminderLogID: 1, ChatID: 100, MessageID: 10, Kind: "reminder"},
+		{ReminderLogID: 2, ChatID: 100, MessageID: 11, Kind: "reminder"},
+		{ReminderLogID: 1, ChatID: 100, MessageID: 12, Kind: "follow_up"},
+	} {
+		require.NoError(t, repo.Create(ctx, message))
+	}
+
+	messages, err := repo.GetByLogID(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, 10, messages[0].MessageID, "应按发送顺序返回")
+	assert.Equal(t, 12, messages[1].MessageID)
+
+	require.NoError(t, repo.DeleteByLogID(ctx, 1))
+	messages, err = repo.GetByLogID(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, messages)
+
+	messages, err = repo.GetByLogID(ctx, 2)
+	require.NoError(t, err)
+	assert.Len(t, messages, 1, "不应删除其他记录的消息")
+}
@@ -3,6 +3,7 @@ package sqlite
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
@@ -10,6 +11,8 @@ import (
 
 	"mmemory/internal/models"
 	"mmemory/internal/repository/interfaces"
+	"mmemory/pkg/logger"
+	"mmemory/pkg/rrule"
 )
 
 // OptimizedReminderRepository 优化的提醒仓储
@@ -72,7 +75,7 @@ func (r *OptimizedReminderRepository) GetByID(ctx context.Context, id uint) (*mo
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
 		}
-		fmt.Errorf("获取提醒失败 (ID: %d): %v", id, err)
+		logger.Errorf("获取提醒失败 (ID: %d): %v", id, err)
 		return nil, fmt.Errorf("获取提醒失败: %w", err)
 	}
 
@@ -93,7 +96,7 @@ func (r *OptimizedReminderRepository) GetByUserID(ctx context.Context, userID ui
 		Find(&reminders).Error
 
 	if err != nil {
-		fmt.Errorf("获取用户提醒失败 (UserID: %d): %v", userID, err)
+		logger.Errorf("获取用户提醒失败 (UserID: %d): %v", userID, err)
 		return nil, fmt.Errorf("获取用户提醒失败: %w", err)
 	}
 
@@ -111,7 +114,7 @@ func (r *OptimizedReminderRepository) GetActiveReminders(ctx context.Context) ([
 		Find(&reminders).Error
 
 	if err != nil {
-		fmt.Errorf("获取活跃提醒失败: %v", err)
+		logger.Errorf("获取活跃提醒失败: %v", err)
 		return nil, fmt.Errorf("获取活跃提醒失败: %w", err)
 	}
 
@@ -137,12 +140,26 @@ func (r *OptimizedReminderRepository) Update(ctx context.Context, reminder *mode
 			"type":             reminder.Type,
 			"schedule_pattern": reminder.SchedulePattern,
 			"target_time":      reminder.TargetTime,
+			"extra_times":      reminder.ExtraTimes,
 			"timezone":         reminder.Timezone,
+			"until_date":       reminder.UntilDate,
+			"max_occurrences":  reminder.MaxOccurrences,
+			"occurrence_count": reminder.OccurrenceCount,
 			"is_active":        reminder.IsActive,
+			"paused_until":     reminder.PausedUntil,
+			"pause_reason":     reminder.PauseReason,
+			"quiet_resume":     reminder.QuietResume,
+			"follow_up_policy": reminder.FollowUpPolicy,
+			"critical":         reminder.Critical,
+			"routine_id":       reminder.RoutineID,
+			"routine_step":     reminder.RoutineStep,
+			"routine_delay":    reminder.RoutineDelay,
+			"lead_times":       reminder.LeadTimes,
+			"channels":         reminder.Channels,
 			"updated_at":       time.Now(),
 		})
 		if result.Error != nil {
-			fmt.Errorf("更新提醒失败 (ID: %d): %v", reminder.ID, result.Error)
+			logger.Errorf("更新提醒失败 (ID: %d): %v", reminder.ID, result.Error)
 			return fmt.Errorf("更新提醒失败: %w", result.Error)
 		}
 
@@ -155,6 +172,19 @@ func (r *OptimizedReminderRepository) Update(ctx context.Context, reminder *mode
 	})
 }
 
+// UpdateOccurrenceCount 只更新已触发次数，UpdateColumn 不会刷新 updated_at
+func (r *OptimizedReminderRepository) UpdateOccurrenceCount(ctx context.Context, id uint, count int) error {
+	result := r.db.WithContext(ctx).Model(&models.Reminder{}).Where("id = ?", id).UpdateColumn("occurrence_count", count)
+	if result.Error != nil {
+		logger.Errorf("更新提醒触发次数失败 (ID: %d): %v", id, result.Error)
+		return fmt.Errorf("更新提醒触发次数失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("提醒不存在 (ID: %d)", id)
+	}
+	return nil
+}
+
 // Delete 删除提醒（优化版）
 func (r *OptimizedReminderRepository) Delete(ctx context.Context, id uint) error {
 	if id == 0 {
@@ -164,14 +194,14 @@ func (r *OptimizedReminderRepository) Delete(ctx context.Context, id uint) error
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// 首先删除相关的提醒记录
 		if err := tx.Where("reminder_id = ?", id).Delete(&models.ReminderLog{}).Error; err != nil {
-			fmt.Errorf("删除提醒记录失败 (ReminderID: %d): %v", id, err)
+			logger.Errorf("删除提醒记录失败 (ReminderID: %d): %v", id, err)
 			return fmt.Errorf("删除提醒记录失败: %w", err)
 		}
 
 		// 然后删除提醒本身
 		result := tx.Delete(&models.Reminder{}, id)
 		if result.Error != nil {
-			fmt.Errorf("删除提醒失败 (ID: %d): %v", id, result.Error)
+			logger.Errorf("删除提醒失败 (ID: %d): %v", id, result.Error)
 			return fmt.Errorf("删除提醒失败: %w", result.Error)
 		}
 
@@ -231,6 +261,29 @@ func (r *OptimizedReminderRepository) validateReminder(reminder *models.Reminder
 		return fmt.Errorf("无效的调度模式")
 	}
 
+	// 验证额外触发时间
+	times := reminder.Times()
+	for _, t := range times[1:] {
+		if !r.isValidTimeFormat(t) {
+			return fmt.Errorf("无效的时间格式: %s，应为 HH:MM:SS 格式", t)
+		}
+	}
+	if len(times) > 1 && reminder.IsInterval() {
+		if rule, err := models.ParseIntervalPattern(reminder.SchedulePattern); err == nil && rule.Unit == models.IntervalHour {
+			return fmt.Errorf("按小时间隔的提醒不支持多个触发时间")
+		}
+	}
+
+	// 验证结束条件
+	if reminder.UntilDate != "" {
+		if _, err := time.Parse("2006-01-02", reminder.UntilDate); err != nil {
+			return fmt.Errorf("无效的截止日期: %s，应为 YYYY-MM-DD 格式", reminder.UntilDate)
+		}
+	}
+	if reminder.MaxOccurrences < 0 {
+		return fmt.Errorf("最多触发次数不能为负数")
+	}
+
 	return nil
 }
 
@@ -261,18 +314,33 @@ func (r *OptimizedReminderRepository) isValidTimeFormat(timeStr string) bool {
 	return true
 }
 
+// workdayPattern 工作日模式，可指定节假日地区代码，如 workday:CN
+var workdayPattern = regexp.MustCompile(`^workday(:[A-Za-z]{2,8})?$`)
+
 // isValidSchedulePattern 验证调度模式
 func (r *OptimizedReminderRepository) isValidSchedulePattern(pattern string) bool {
-	// 支持的模式：daily, weekly:1,3,5, monthly:1,15, once:2024-01-01
+	// 支持的模式：daily, weekly:1,3,5, workday|workday:CN, monthly:1,15|L|1#1, yearly:12-25, every:2h@09:00-18:00|3d|2w, rrule:FREQ=..., once:2024-01-01
 	if pattern == "daily" {
 		return true
 	}
-	if len(pattern) > 7 && pattern[:7] == "weekly:" {
+	if workdayPattern.MatchString(pattern) {
 		return true
 	}
-	if len(pattern) > 8 && pattern[:8] == "monthly:" {
+	if len(pattern) > 7 && pattern[:7] == "weekly:" {
 		return true
 	}
+	if strings.HasPrefix(pattern, "monthly:") || strings.HasPrefix(pattern, "yearly:") {
+		_, err := models.ParseCalendarPattern(pattern)
+		return err == nil
+	}
+	if strings.HasPrefix(pattern, "every:") {
+		_, err := models.ParseIntervalPattern(pattern)
+		return err == nil
+	}
+	if strings.HasPrefix(pattern, string(models.SchedulePatternRRule)) {
+		_, err := rrule.ParseSet(strings.TrimPrefix(pattern, string(models.SchedulePatternRRule)))
+		return err == nil
+	}
 	if strings.HasPrefix(pattern, string(models.SchedulePatternOnce)) &&
 		len(pattern) > len(string(models.SchedulePatternOnce)) {
 		return true
@@ -290,7 +358,7 @@ func (r *OptimizedReminderRepository) GetBySchedulePattern(ctx context.Context,
 		Find(&reminders).Error
 
 	if err != nil {
-		fmt.Errorf("获取调度模式提醒失败 (Pattern: %s): %v", pattern, err)
+		logger.Errorf("获取调度模式提醒失败 (Pattern: %s): %v", pattern, err)
 		return nil, fmt.Errorf("获取调度模式提醒失败: %w", err)
 	}
 
@@ -307,7 +375,7 @@ func (r *OptimizedReminderRepository) GetByTimeRange(ctx context.Context, startT
 		Find(&reminders).Error
 
 	if err != nil {
-		fmt.Errorf("获取时间范围提醒失败 (%s - %s): %v", startTime, endTime, err)
+		logger.Errorf("获取时间范围提醒失败 (%s - %s): %v", startTime, endTime, err)
 		return nil, fmt.Errorf("获取时间范围提醒失败: %w", err)
 	}
 
@@ -327,7 +395,7 @@ func (r *OptimizedReminderRepository) CountByUserID(ctx context.Context, userID
 		Count(&count).Error
 
 	if err != nil {
-		fmt.Errorf("统计用户提醒数量失败 (UserID: %d): %v", userID, err)
+		logger.Errorf("统计用户提醒数量失败 (UserID: %d): %v", userID, err)
 		return 0, fmt.Errorf("统计用户提醒数量失败: %w", err)
 	}
 
@@ -346,7 +414,7 @@ func (r *OptimizedReminderRepository) BatchUpdateStatus(ctx context.Context, rem
 		Update("is_active", isActive)
 
 	if result.Error != nil {
-		fmt.Errorf("批量更新提醒状态失败: %v", result.Error)
+		logger.Errorf("批量更新提醒状态失败: %v", result.Error)
 		return fmt.Errorf("批量更新提醒状态失败: %w", result.Error)
 	}
 
@@ -198,6 +198,30 @@ func TestOptimizedReminderRepository(t *testing.T) {
 		assert.False(t, updatedReminder.IsActive)
 	})
 
+	t.Run("更新触发次数不改变更新时间", func(t *testing.T) {
+		reminder := &models.Reminder{
+			UserID:          user.ID,
+			Title:           "计数提醒",
+			Type:            models.ReminderTypeHabit,
+			SchedulePattern: "daily",
+			TargetTime:      "07:00:00",
+			IsActive:        true,
+		}
+		require.NoError(t, repo.Create(ctx, reminder))
+		before, err := repo.GetByID(ctx, reminder.ID)
+		require.NoError(t, err)
+
+		time.Sleep(10 * time.Millisecond)
+		require.NoError(t, repo.UpdateOccurrenceCount(ctx, reminder.ID, 3))
+
+		after, err := repo.GetByID(ctx, reminder.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 3, after.OccurrenceCount)
+		assert.True(t, before.UpdatedAt.Equal(after.UpdatedAt), "只更新触发次数时不应刷新 updated_at")
+
+		assert.Error(t, repo.UpdateOccurrenceCount(ctx, 99999, 1))
+	})
+
 	t.Run("删除提醒 - 级联删除", func(t *testing.T) {
 		reminder := &models.Reminder{
 			UserID:          user.ID,
@@ -262,4 +286,50 @@ func TestOptimizedReminderRepository(t *testing.T) {
 			assert.Error(t, err, "时间 %s 应该是无效的", invalidTime)
 		}
 	})
+
+	t.Run("验证多个触发时间", func(t *testing.T) {
+		reminder := &models.Reminder{
+			UserID:          user.ID,
+			Title:           "一天三次",
+			Type:            models.ReminderTypeHabit,
+			SchedulePattern: "daily",
+			TargetTime:      "08:00:00",
+			ExtraTimes:      "13:00:00,20:00:00",
+			IsActive:        true,
+		}
+		require.NoError(t, repo.Create(ctx, reminder))
+
+		reminder.ExtraTimes = "13:00:00,25:00:00"
+		assert.Error(t, repo.Update(ctx, reminder), "额外时间格式错误应被拒绝")
+
+		reminder.ExtraTimes = "20:00:00"
+		require.NoError(t, repo.Update(ctx, reminder))
+		updated, err := repo.GetByID(ctx, reminder.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"08:00:00", "20:00:00"}, updated.Times())
+
+		hourly := &models.Reminder{
+			UserID:          user.ID,
+			Title:           "按小时间隔",
+			Type:            models.ReminderTypeHabit,
+			SchedulePattern: "every:2h",
+			TargetTime:      "08:00:00",
+			ExtraTimes:      "09:00:00",
+			IsActive:        true,
+		}
+		assert.Error(t, repo.Create(ctx, hourly), "按小时间隔不支持多个触发时间")
+	})
+
+	t.Run("验证每月、每年和间隔调度模式", func(t *testing.T) {
+		validPatterns := []string{"monthly:1,15", "monthly:L", "monthly:1#1", "monthly:5#L", "monthly:1,L", "yearly:12-25", "yearly:02-29,10-01", "every:2h", "every:2h@09:00-18:00", "every:3d", "every:2w", "rrule:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR|EXRULE:FREQ=MONTHLY;BYDAY=FR;BYSETPOS=-1", "workday", "workday:CN"}
+		invalidPatterns := []string{"monthly:", "monthly:32", "monthly:8#1", "monthly:1#6", "yearly:13-01", "yearly:02-30", "yearly:1225", "every:", "every:0d", "every:2x", "every:3d@09:00-18:00", "every:2h@18:00-09:00", "rrule:", "rrule:FREQ=HOURLY", "workday:", "workdays", "workday:C1"}
+		optimizedRepo := repo.(*OptimizedReminderRepository)
+
+		for _, pattern := range validPatterns {
+			assert.True(t, optimizedRepo.isValidSchedulePattern(pattern), "模式 %s 应该是有效的", pattern)
+		}
+		for _, pattern := range invalidPatterns {
+			assert.False(t, optimizedRepo.isValidSchedulePattern(pattern), "模式 %s 应该是无效的", pattern)
+		}
+	})
 }
\ No newline at end of file
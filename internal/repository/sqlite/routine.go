@@ -0,0 +1,61 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"mmemory/internal/models"
+	"mmemory/internal/repository/interfaces"
+)
+
+type routineRepository struct {
+	db *gorm.DB
+}
+
+func NewRoutineRepository(db *gorm.DB) interfaces.RoutineRepository {
+	return &routineRepository{db: db}
+}
+
+func (r *routineRepository) Create(ctx context.Context, routine *models.Routine) error {
+	return r.db.WithContext(ctx).Omit("Steps").Create(routine).Error
+}
+
+func (r *routineRepository) GetByID(ctx context.Context, id uint) (*models.Routine, error) {
+	var routine models.Routine
+	err := r.db.WithContext(ctx).
+		Preload("Steps", func(db *gorm.DB) *gorm.DB { return db.Order("routine_step ASC") }).
+		First(&routine, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &routine, nil
+}
+
+func (r *routineRepository) GetByUserID(ctx context.Context, userID uint) ([]*models.Routine, error) {
+	var routines []*models.Routine
+	err := r.db.WithContext(ctx).
+		Preload("Steps", func(db *gorm.DB) *gorm.DB { return db.Order("routine_step ASC") }).
+		Where("user_id = ?", userID).
+		Order("id ASC").
+		Find(&routines).Error
+	return routines, err
+}
+
+// Delete 删除例程，其中的提醒保留并恢复为独立提醒
+func (r *routineRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Reminder{}).Where("routine_id = ?", id).Updates(map[string]interface{}{
+			"routine_id":    nil,
+			"routine_step":  0,
+			"routine_delay": 0,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Routine{}, id).Error
+	})
+}
@@ -0,0 +1,66 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"mmemory/internal/models"
+)
+
+func TestRoutineRepository(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Reminder{}, &models.Routine{}))
+
+	repo := NewRoutineRepository(db)
+	ctx := context.Background()
+
+	routine := &models.Routine{UserID: 1, Name: "晨间"}
+	require.NoError(t, repo.Create(ctx, routine))
+
+	// 步骤按 routine_step 排序返回，与创建顺序无关
+	for _, step := range []struct {
+		title string
+		order int
+	}{{"写日记", 3}, {"喝水", 1}, {"拉伸", 2}} {
+		require.NoError(t, db.Create(&models.Reminder{
+			UserID:          1,
+			Title:           step.title,
+			Type:            models.ReminderTypeHabit,
+			SchedulePattern: "daily",
+			TargetTime:      "07:00:00",
+			IsActive:        true,
+			RoutineID:       &routine.ID,
+			RoutineStep:     step.order,
+		}).Error)
+	}
+
+	loaded, err := repo.GetByID(ctx, routine.ID)
+	require.NoError(t, err)
+	require.NotNil(t, loaded)
+	require.Len(t, loaded.Steps, 3)
+	assert.Equal(t, []string{"喝水", "拉伸", "写日记"}, []string{loaded.Steps[0].Title, loaded.Steps[1].Title, loaded.Steps[2].Title})
+
+	routines, err := repo.GetByUserID(ctx, 1)
+	require.NoError(t, err)
+	assert.Len(t, routines, 1)
+
+	// 删除例程后提醒保留并恢复为独立提醒
+	require.NoError(t, repo.Delete(ctx, routine.ID))
+	deleted, err := repo.GetByID(ctx, routine.ID)
+	require.NoError(t, err)
+	assert.Nil(t, deleted)
+
+	var reminders []models.Reminder
+	require.NoError(t, db.Find(&reminders).Error)
+	assert.Len(t, reminders, 3)
+	for _, reminder := range reminders {
+		assert.Nil(t, reminder.RoutineID)
+		assert.Zero(t, reminder.RoutineStep)
+	}
+}
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"mmemory/internal/models"
+	"mmemory/pkg/clock"
+	"mmemory/pkg/logger"
+)
+
+const maxAgendaOccurrences = 96 // 单个提醒一天内最多列出的触发次数（每15分钟一次）
+
+// Agenda 用户一天的日程，由调度器的触发时间计算得出
+type Agenda struct {
+	User     *models.User
+	Date     time.Time             // 当天零点，用户时区
+	Upcoming []AgendaItem          // 当天尚未到来的触发，按时间排序
+	Missed   []*models.ReminderLog // 前一天错过、跳过或未回复的提醒，按时间排序
+}
+
+// AgendaItem 日程中的一次触发
+type AgendaItem struct {
+	Reminder   *models.Reminder
+	Occurrence time.Time // 计划时刻，用户时区
+}
+
+// Empty 当天没有待提醒事项且前一天没有未完成的提醒
+func (a *Agenda) Empty() bool {
+	return len(a.Upcoming) == 0 && len(a.Missed) == 0
+}
+
+// End 当天结束（次日零点），"今天暂停"暂停到此刻
+func (a *Agenda) End() time.Time {
+	return a.Date.AddDate(0, 0, 1)
+}
+
+// agendaJob 用户的每日日程推送
+type agendaJob struct {
+	user  models.User // 安排推送时的用户设置，定期同步时更新
+	spec  string      // 推送时间和时区，变化后重新安排
+	timer clock.Timer
+}
+
+func agendaSpec(user *models.User) string {
+	return user.AgendaTime + "@" + user.Timezone
+}
+
+// ScheduleAgenda 按用户的设置安排每日日程推送，AgendaTime 为空时取消
+func (s *schedulerService) ScheduleAgenda(user *models.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.clearAgendaLocked(user.ID)
+	if user.AgendaTime != "" {
+		s.armAgendaLocked(*user)
+	}
+}
+
+// syncAgendas 根据有效提醒关联的用户同步日程推送，没有有效提醒的用户没有可推送的日程
+func (s *schedulerService) syncAgendas(reminders []*models.Reminder) {
+	users := make(map[uint]models.User)
+	for _, reminder := range reminders {
+		if reminder.User.ID == reminder.UserID && reminder.User.AgendaTime != "" {
+			users[reminder.UserID] = reminder.User
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id := range s.agendas {
+		if _, ok := users[id]; !ok {
+			s.clearAgendaLocked(id)
+		}
+	}
+	for id, user := range users {
+		if job, ok := s.agendas[id]; ok && job.spec == agendaSpec(&user) {
+			job.user = user
+			continue
+		}
+		s.clearAgendaLocked(id)
+		s.armAgendaLocked(user)
+	}
+}
+
+// clearAgendaLocked 取消用户的日程推送，调用方需持有 mu
+func (s *schedulerService) clearAgendaLocked(userID uint) {
+	if job, ok := s.agendas[userID]; ok {
+		job.timer.Stop()
+		delete(s.agendas, userID)
+	}
+}
+
+// armAgendaLocked 安排下一次日程推送，调用方需持有 mu
+func (s *schedulerService) armAgendaLocked(user models.User) {
+	hour, minute, ok := user.AgendaClock()
+	if !ok {
+		logger.Warnf("用户的日程推送时间无效，跳过 (UserID: %d, 时间: %s)", user.ID, user.AgendaTime)
+		return
+	}
+
+	now := s.now().In(s.userLocation(&user))
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	if !next.After(now) {
+		next = time.Date(now.Year(), now.Month(), now.Day()+1, hour, minute, 0, 0, now.Location())
+	}
+
+	job := &agendaJob{user: user, spec: agendaSpec(&user)}
+	userID := user.ID
+	job.timer = s.afterFunc(next.Sub(s.now()), func() {
+		s.dispatch(fmt.Sprintf("每日日程 %d", userID), func() {
+			s.sendAgenda(job)
+		})
+	})
+	s.agendas[userID] = job
+
+	logger.Debugf("☀️ 已安排每日日程: UserID=%d, 下次推送=%s", userID, next.Format(time.RFC3339))
+}
+
+// sendAgenda 由主实例生成并发送日程，然后安排次日的推送；没有任何事项时不打扰用户
+func (s *schedulerService) sendAgenda(job *agendaJob) {
+	ctx := context.Background()
+
+	s.mu.RLock()
+	user := job.user
+	s.mu.RUnlock()
+
+	if s.isLeader() {
+		agenda, err := s.BuildAgenda(ctx, &user, s.now())
+		switch {
+		case err != nil:
+			logger.Errorf("生成每日日程失败 (UserID: %d): %v", user.ID, err)
+		case agenda.Empty():
+			logger.Debugf("☀️ 今日没有日程，跳过推送 (UserID: %d)", user.ID)
+		default:
+			if err := s.notificationService.SendAgenda(ctx, agenda); err != nil {
+				logger.Errorf("发送每日日程失败 (UserID: %d): %v", user.ID, err)
+			}
+		}
+	}
+
+	// 期间被取消或重新安排时不再继续
+	s.mu.Lock()
+	if s.agendas[user.ID] == job {
+		delete(s.agendas, user.ID)
+		s.armAgendaLocked(job.user)
+	}
+	s.mu.Unlock()
+}
+
+// BuildAgenda 生成用户在 now 所在当天（用户时区）的日程：当天尚未到来的触发和前一天未完成的提醒
+// 触发时间与实际调度一致，已暂停和已跳过的触发不列出
+func (s *schedulerService) BuildAgenda(ctx context.Context, user *models.User, now time.Time) (*Agenda, error) {
+	loc := s.userLocation(user)
+	local := now.In(loc)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	yesterday := today.AddDate(0, 0, -1)
+	agenda := &Agenda{User: user, Date: today}
+
+	reminders, err := s.reminderRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取用户提醒失败: %w", err)
+	}
+	byID := make(map[uint]*models.Reminder, len(reminders))
+	for _, reminder := range reminders {
+		if reminder.User.ID == 0 {
+			reminder.User = *user
+		}
+		byID[reminder.ID] = reminder
+	}
+
+	// 一次取出昨天和今天的全部记录：昨天的用于列出未完成的提醒，今天已认领的触发（如已跳过）不再列出
+	logs, err := s.reminderLogRepo.GetByUserBetween(ctx, user.ID, yesterday, agenda.End())
+	if err != nil {
+		return nil, fmt.Errorf("获取提醒记录失败: %w", err)
+	}
+	claimed := make(map[uint]map[int64]bool)
+	for _, log := range logs {
+		reminder := byID[log.ReminderID]
+		if reminder == nil {
+			continue
+		}
+		if log.OccurrenceAt != nil {
+			if claimed[log.ReminderID] == nil {
+				claimed[log.ReminderID] = make(map[int64]bool)
+			}
+			claimed[log.ReminderID][log.OccurrenceAt.Unix()] = true
+		}
+		if log.ScheduledTime.Before(yesterday) || !log.ScheduledTime.Before(today) {
+			continue
+		}
+		switch log.Status {
+		case models.ReminderStatusSent, models.ReminderStatusOverdue, models.ReminderStatusSkipped:
+			log.Reminder = *reminder
+			agenda.Missed = append(agenda.Missed, log)
+		}
+	}
+
+	for _, reminder := range reminders {
+		occurrences, err := s.NextOccurrences(reminder, local, maxAgendaOccurrences)
+		if err != nil {
+			logger.Warnf("计算日程中的触发时间失败 (ID: %d): %v", reminder.ID, err)
+			continue
+		}
+		for _, occurrence := range occurrences {
+			if !occurrence.Before(agenda.End()) {
+				break
+			}
+			if claimed[reminder.ID][occurrence.Unix()] {
+				continue
+			}
+			agenda.Upcoming = append(agenda.Upcoming, AgendaItem{Reminder: reminder, Occurrence: occurrence.In(loc)})
+		}
+	}
+
+	sort.SliceStable(agenda.Upcoming, func(i, j int) bool {
+		return agenda.Upcoming[i].Occurrence.Before(agenda.Upcoming[j].Occurrence)
+	})
+	sort.SliceStable(agenda.Missed, func(i, j int) bool {
+		return agenda.Missed[i].ScheduledTime.Before(agenda.Missed[j].ScheduledTime)
+	})
+	return agenda, nil
+}
+
+// SkipOccurrence 跳过提醒尚未到来的一次触发：预先认领该次触发并记为已跳过，到时不再提醒
+func (s *schedulerService) SkipOccurrence(ctx context.Context, reminderID uint, occurrence time.Time) error {
+	reminder, err := s.reminderRepo.GetByID(ctx, reminderID)
+	if err != nil {
+		return fmt.Errorf("获取提醒失败: %w", err)
+	}
+	if reminder == nil || !reminder.IsActive {
+		return fmt.Errorf("提醒不存在或已停用")
+	}
+	if !occurrence.After(s.now()) {
+		return fmt.Errorf("该次提醒已经发送")
+	}
+
+	// 只接受调度计划中的触发时间，避免认领到不存在的时刻
+	next, err := s.NextOccurrences(reminder, occurrence.Add(-time.Second), 1)
+	if err != nil {
+		return fmt.Errorf("计算触发时间失败: %w", err)
+	}
+	if len(next) == 0 || !next[0].Equal(occurrence) {
+		return fmt.Errorf("该时间不是提醒的计划触发时间")
+	}
+
+	now := s.now()
+	log := &models.ReminderLog{
+		ReminderID:    reminderID,
+		ScheduledTime: occurrence,
+		OccurrenceAt:  models.OccurrenceKey(occurrence),
+		Status:        models.ReminderStatusSkipped,
+		UserResponse:  "在每日日程中跳过",
+		ResponseTime:  &now,
+	}
+	claimed, err := s.reminderLogRepo.Claim(ctx, log)
+	if err != nil {
+		return fmt.Errorf("创建提醒记录失败: %w", err)
+	}
+	if !claimed {
+		return fmt.Errorf("该次提醒已经处理过")
+	}
+
+	logger.Infof("⏭️ 已跳过提醒的一次触发 (ID: %d, 计划时间: %s)", reminderID, occurrence.Format(time.RFC3339))
+	return nil
+}
+
+// occurrenceClaimed 检查某次触发是否已有记录（如已在日程中跳过）
+func (s *schedulerService) occurrenceClaimed(ctx context.Context, reminderID uint, occurrence time.Time) bool {
+	logs, err := s.reminderLogRepo.GetByReminderID(ctx, reminderID, 10, 0)
+	if err != nil {
+		logger.Warnf("获取提醒记录失败 (ID: %d): %v", reminderID, err)
+		return false
+	}
+	key := models.OccurrenceKey(occurrence)
+	for _, log := range logs {
+		if log.OccurrenceAt != nil && log.OccurrenceAt.Equal(*key) {
+			return true
+		}
+	}
+	return false
+}
+
+// userLocation 用户的时区，未设置或无效时使用默认时区
+func (s *schedulerService) userLocation(user *models.User) *time.Location {
+	if user.Timezone != "" {
+		if loc, err := time.LoadLocation(user.Timezone); err == nil {
+			return loc
+		}
+	}
+	return s.location
+}
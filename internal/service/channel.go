@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"mmemory/internal/models"
+)
+
+// ErrChannelUnavailable 用户没有配置该渠道（如没有邮箱地址），直接尝试下一个渠道
+var ErrChannelUnavailable = errors.New("用户未配置该通知渠道")
+
+// 通知类型
+const (
+	NotificationKindReminder     = "reminder"
+	NotificationKindFollowUp     = "follow_up"
+	NotificationKindMissedDigest = "missed_digest"
+	NotificationKindResumed      = "resumed"
+	NotificationKindLeadNotice   = "lead_notice"
+	NotificationKindAgenda       = "agenda"
+)
+
+// Notification 与渠道无关的通知内容，由各渠道转换为自己的消息格式
+type Notification struct {
+	Kind       string               // 通知类型
+	Subject    string               // 纯文本标题，用作邮件主题
+	Body       string               // 正文，Telegram HTML 格式
+	Actions    []NotificationAction // 可选的操作，Telegram 显示为按钮
+	ReminderID uint
+	LogID      uint
+	ReplyTo    int // 回复的 Telegram 消息ID，其他渠道忽略
+	MessageID  int // Telegram 渠道发送成功后的消息ID
+}
+
+// NotificationAction 通知上的操作，Data 与 Telegram 回调数据一致
+type NotificationAction struct {
+	Label string `json:"label"`
+	Data  string `json:"data"`
+}
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]+>`)
+
+// PlainText 返回去掉 HTML 标记的正文
+func (n *Notification) PlainText() string {
+	return html.UnescapeString(htmlTagPattern.ReplaceAllString(n.Body, ""))
+}
+
+// newNotification 以正文第一行（去掉标记）和提醒标题作为通知标题
+func newNotification(kind, body, title string) *Notification {
+	heading, _, _ := strings.Cut(body, "\n")
+	subject := strings.TrimSpace(html.UnescapeString(htmlTagPattern.ReplaceAllString(heading, "")))
+	if title != "" {
+		subject += " · " + title
+	}
+	return &Notification{Kind: kind, Subject: subject, Body: body}
+}
+
+// NotificationChannel 通知渠道，NotificationService 按用户偏好依次尝试
+type NotificationChannel interface {
+	// Name 渠道名称，对应 models.ChannelTelegram 等
+	Name() string
+	// Send 向用户发送通知，用户未配置该渠道时返回 ErrChannelUnavailable
+	Send(ctx context.Context, user *models.User, notification *Notification) error
+}
+
+type telegramChannel struct {
+	bot BotAPI
+}
+
+// NewTelegramChannel 创建 Telegram 渠道，操作显示为每行两个的按钮
+func NewTelegramChannel(bot BotAPI) NotificationChannel {
+	return &telegramChannel{bot: bot}
+}
+
+func (c *telegramChannel) Name() string {
+	return models.ChannelTelegram
+}
+
+func (c *telegramChannel) Send(ctx context.Context, user *models.User, notification *Notification) error {
+	if user.TelegramID == 0 {
+		return ErrChannelUnavailable
+	}
+
+	msg := tgbotapi.NewMessage(user.TelegramID, notification.Body)
+	msg.ParseMode = tgbotapi.ModeHTML
+	if len(notification.Actions) > 0 {
+		msg.ReplyMarkup = actionKeyboard(notification.Actions)
+	}
+	if notification.ReplyTo != 0 {
+		// 原消息被用户删除时照常发送
+		msg.ReplyToMessageID = notification.ReplyTo
+		msg.AllowSendingWithoutReply = true
+	}
+
+	sent, err := c.bot.Send(msg)
+	if err != nil {
+		return fmt.Errorf("发送Telegram消息失败: %w", err)
+	}
+	notification.MessageID = sent.MessageID
+	return nil
+}
+
+// actionKeyboard 将操作转换为每行两个按钮的内联键盘
+func actionKeyboard(actions []NotificationAction) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := 0; i < len(actions); i += 2 {
+		var row []tgbotapi.InlineKeyboardButton
+		for _, action := range actions[i:min(i+2, len(actions))] {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(action.Label, action.Data))
+		}
+		rows = append(rows, row)
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
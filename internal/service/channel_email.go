@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"mmemory/internal/models"
+)
+
+// EmailConfig SMTP 邮件渠道配置
+type EmailConfig struct {
+	Host     string
+	Port     int
+	Username string // 为空时不认证
+	Password string
+	From     string // 发件人，如 "MMemory <bot@example.com>"
+}
+
+type emailChannel struct {
+	config EmailConfig
+}
+
+// NewEmailChannel 创建邮件渠道，发送纯文本邮件到 User.Email
+func NewEmailChannel(config EmailConfig) (NotificationChannel, error) {
+	if config.Host == "" || config.Port <= 0 {
+		return nil, fmt.Errorf("邮件渠道需要配置 SMTP 地址和端口")
+	}
+	if _, err := mail.ParseAddress(config.From); err != nil {
+		return nil, fmt.Errorf("无效的发件人地址 %q: %w", config.From, err)
+	}
+	return &emailChannel{config: config}, nil
+}
+
+func (c *emailChannel) Name() string {
+	return models.ChannelEmail
+}
+
+func (c *emailChannel) Send(ctx context.Context, user *models.User, notification *Notification) error {
+	if user.Email == "" {
+		return ErrChannelUnavailable
+	}
+	to, err := mail.ParseAddress(user.Email)
+	if err != nil {
+		return fmt.Errorf("无效的收件地址 %q: %w", user.Email, err)
+	}
+	from, _ := mail.ParseAddress(c.config.From)
+
+	var auth smtp.Auth
+	if c.config.Username != "" {
+		auth = smtp.PlainAuth("", c.config.Username, c.config.Password, c.config.Host)
+	}
+
+	addr := net.JoinHostPort(c.config.Host, strconv.Itoa(c.config.Port))
+	if err := smtp.SendMail(addr, auth, from.Address, []string{to.Address}, c.buildMessage(from, to, notification)); err != nil {
+		return fmt.Errorf("发送邮件失败: %w", err)
+	}
+	return nil
+}
+
+// buildMessage 构建 UTF-8 纯文本邮件，正文使用 base64 编码
+func (c *emailChannel) buildMessage(from, to *mail.Address, notification *Notification) []byte {
+	body := notification.PlainText()
+	if len(notification.Actions) > 0 {
+		body += "\n\n请在 Telegram 中回复机器人记录完成情况。"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from.String())
+	fmt.Fprintf(&b, "To: %s\r\n", to.String())
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", notification.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(body))
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+	return []byte(b.String())
+}
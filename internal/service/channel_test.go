@@ -0,0 +1,288 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"mmemory/internal/models"
+)
+
+// fakeSMTPServer 最小的 SMTP 服务器，记录收到的邮件
+type fakeSMTPServer struct {
+	listener net.Listener
+	messages chan smtpMessage
+}
+
+type smtpMessage struct {
+	to   []string
+	data string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("启动 SMTP 服务失败: %v", err)
+	}
+	server := &fakeSMTPServer{listener: listener, messages: make(chan smtpMessage, 10)}
+	go server.serve()
+	t.Cleanup(func() { listener.Close() })
+	return server
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	var msg smtpMessage
+	reply("220 localhost ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM"):
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO"):
+			msg.to = append(msg.to, strings.Trim(strings.TrimSpace(line)[len("RCPT TO:"):], "<>"))
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			msg.data = data.String()
+			s.messages <- msg
+			msg = smtpMessage{}
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func testNotification() *Notification {
+	notification := newNotification(NotificationKindReminder, "⏰ <b>习惯提醒</b>\n\n📝 喝水\n\n已经到了约定的时间，完成了吗？", "喝水")
+	notification.ReminderID, notification.LogID = 3, 7
+	notification.Actions = []NotificationAction{{Label: "✅ 完成了", Data: "reminder_complete_7"}}
+	return notification
+}
+
+func TestEmailChannel_Send(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	channel, err := NewEmailChannel(EmailConfig{Host: "127.0.0.1", Port: server.port(), From: "MMemory <bot@example.com>"})
+	if err != nil {
+		t.Fatalf("NewEmailChannel() error = %v", err)
+	}
+
+	if err := channel.Send(context.Background(), &models.User{ID: 1}, testNotification()); err != ErrChannelUnavailable {
+		t.Errorf("未设置邮箱时应返回 ErrChannelUnavailable，实际: %v", err)
+	}
+
+	if err := channel.Send(context.Background(), &models.User{ID: 1, Email: "alice@example.com"}, testNotification()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	msg := <-server.messages
+	if len(msg.to) != 1 || msg.to[0] != "alice@example.com" {
+		t.Errorf("收件人 = %v", msg.to)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(msg.data))
+	if err != nil {
+		t.Fatalf("解析邮件失败: %v", err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if subject != "⏰ 习惯提醒 · 喝水" {
+		t.Errorf("邮件主题 = %q", subject)
+	}
+	encoded, _ := io.ReadAll(parsed.Body)
+	body, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(encoded), "\r\n", ""))
+	if err != nil {
+		t.Fatalf("解码邮件正文失败: %v", err)
+	}
+	if strings.Contains(string(body), "<b>") || !strings.Contains(string(body), "📝 喝水") {
+		t.Errorf("邮件正文应为纯文本，实际: %s", body)
+	}
+}
+
+func TestWebhookChannel_Send(t *testing.T) {
+	var payload WebhookPayload
+	var verified bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		verified = VerifyWebhookSignature("s3cret", r.Header.Get(WebhookTimestampHeader), body, r.Header.Get(WebhookSignatureHeader))
+		json.Unmarshal(body, &payload)
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	// 测试服务器监听在本机，需显式允许
+	channel, err := NewWebhookChannel(WebhookConfig{Secret: "s3cret", AllowPrivate: true})
+	if err != nil {
+		t.Fatalf("NewWebhookChannel() error = %v", err)
+	}
+
+	user := &models.User{ID: 1, WebhookURL: server.URL + "/hook"}
+	if err := channel.Send(context.Background(), user, testNotification()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if !verified {
+		t.Error("请求签名校验失败")
+	}
+	if payload.Event != NotificationKindReminder || payload.UserID != 1 || payload.ReminderID != 3 || payload.LogID != 7 {
+		t.Errorf("请求内容 = %+v", payload)
+	}
+	if strings.Contains(payload.Text, "<b>") || len(payload.Actions) != 1 || payload.Actions[0].Data != "reminder_complete_7" {
+		t.Errorf("请求内容 = %+v", payload)
+	}
+
+	user.WebhookURL = server.URL + "/fail"
+	if err := channel.Send(context.Background(), user, testNotification()); err == nil {
+		t.Error("非 2xx 响应应返回错误")
+	}
+}
+
+func TestWebhookChannel_RejectsInternalAddresses(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+	}))
+	defer server.Close()
+
+	channel, err := NewWebhookChannel(WebhookConfig{Secret: "s3cret", Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("NewWebhookChannel() error = %v", err)
+	}
+
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	for _, target := range []string{
+		server.URL,
+		"http://localhost:" + port,
+		"http://169.254.169.254/latest/meta-data/",
+		"http://10.0.0.1/",
+		"http://[::1]:" + port,
+		"http://[::ffff:127.0.0.1]:" + port,
+	} {
+		user := &models.User{ID: 1, WebhookURL: target}
+		if err := channel.Send(context.Background(), user, testNotification()); err == nil || !strings.Contains(err.Error(), "内部地址") {
+			t.Errorf("Send(%s) error = %v, 应拒绝内部地址", target, err)
+		}
+	}
+	if hits != 0 {
+		t.Errorf("不应有请求到达本机服务，实际 %d 次", hits)
+	}
+}
+
+func TestIsInternalAddress(t *testing.T) {
+	tests := map[string]bool{
+		"127.0.0.1":       true,
+		"10.1.2.3":        true,
+		"172.16.0.1":      true,
+		"192.168.1.1":     true,
+		"169.254.169.254": true,
+		"100.100.100.200": true,
+		"0.0.0.0":         true,
+		"::1":             true,
+		"fe80::1":         true,
+		"fd00::1":         true,
+		"::ffff:10.0.0.1": true,
+		"8.8.8.8":         false,
+		"2001:4860::8888": false,
+	}
+	for raw, want := range tests {
+		if got := isInternalAddress(netip.MustParseAddr(raw)); got != want {
+			t.Errorf("isInternalAddress(%s) = %v, want %v", raw, got, want)
+		}
+	}
+}
+
+func TestNotificationService_ChannelFallback(t *testing.T) {
+	var hooks int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hooks++
+	}))
+	defer server.Close()
+
+	mockBot := &mockBotAPI{shouldError: true}
+	service := NewNotificationService(mockBot).(*notificationService)
+	webhook, _ := NewWebhookChannel(WebhookConfig{Secret: "s3cret", AllowPrivate: true})
+	service.RegisterChannel(webhook)
+
+	user := models.User{ID: 1, TelegramID: 123456789, WebhookURL: server.URL, Channels: "telegram,webhook"}
+	log := &models.ReminderLog{ID: 1, ReminderID: 1, Reminder: models.Reminder{ID: 1, Title: "喝水", User: user}}
+
+	// Telegram 发送失败时改用 Webhook
+	if err := service.SendReminder(context.Background(), log); err != nil {
+		t.Fatalf("SendReminder() error = %v", err)
+	}
+	if hooks != 1 {
+		t.Errorf("Webhook 调用次数 = %d, want 1", hooks)
+	}
+
+	// 提醒单独设置的渠道优先于用户设置
+	mockBot.shouldError = false
+	log.Reminder.Channels = "webhook"
+	if err := service.SendReminder(context.Background(), log); err != nil {
+		t.Fatalf("SendReminder() error = %v", err)
+	}
+	if hooks != 2 || len(mockBot.sentMessages) != 0 {
+		t.Errorf("Webhook 调用次数 = %d, Telegram 消息 = %d", hooks, len(mockBot.sentMessages))
+	}
+
+	// 没有 Telegram ID 且未启用邮件渠道时全部失败
+	log.Reminder.Channels = "email"
+	log.Reminder.User.TelegramID = 0
+	if err := service.SendReminder(context.Background(), log); err == nil || !strings.Contains(err.Error(), "email") {
+		t.Errorf("未启用的渠道应返回错误，实际: %v", err)
+	}
+	if err := service.SetDefaultChannels("sms"); err == nil {
+		t.Error("不支持的默认渠道应返回错误")
+	}
+	if len(service.defaultChannels) != 1 {
+		t.Errorf("默认渠道 = %v, want [telegram]", service.defaultChannels)
+	}
+}
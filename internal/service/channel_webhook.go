@@ -0,0 +1,188 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	"mmemory/internal/models"
+)
+
+// Webhook 请求头
+const (
+	WebhookTimestampHeader = "X-MMemory-Timestamp"
+	WebhookSignatureHeader = "X-MMemory-Signature"
+)
+
+// WebhookConfig HTTP 回调渠道配置
+type WebhookConfig struct {
+	Secret  string        // 签名密钥，接收方用它校验请求来自本服务
+	Timeout time.Duration // 单次请求超时
+	// AllowPrivate 允许回调到本机、内网、链路本地等地址，仅用于测试或所有用户都可信的部署
+	AllowPrivate bool
+}
+
+// WebhookPayload 回调请求体
+type WebhookPayload struct {
+	Event      string               `json:"event"`
+	UserID     uint                 `json:"user_id"`
+	ReminderID uint                 `json:"reminder_id,omitempty"`
+	LogID      uint                 `json:"log_id,omitempty"`
+	Subject    string               `json:"subject"`
+	Text       string               `json:"text"`
+	HTML       string               `json:"html"`
+	Actions    []NotificationAction `json:"actions,omitempty"`
+	SentAt     time.Time            `json:"sent_at"`
+}
+
+type webhookChannel struct {
+	config WebhookConfig
+	client *http.Client
+}
+
+// NewWebhookChannel 创建 Webhook 渠道，以 JSON POST 到 User.WebhookURL
+// 请求带 X-MMemory-Timestamp 和 X-MMemory-Signature 头，签名见 SignWebhookPayload
+func NewWebhookChannel(config WebhookConfig) (NotificationChannel, error) {
+	if config.Secret == "" {
+		return nil, fmt.Errorf("Webhook 渠道需要配置签名密钥")
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	// 回调地址由用户设置，连接时校验解析出的 IP，重定向和 DNS 重绑定同样无法访问内部地址
+	dialer := &net.Dialer{Timeout: config.Timeout}
+	if !config.AllowPrivate {
+		dialer.Control = rejectInternalAddress
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &webhookChannel{config: config, client: &http.Client{Timeout: config.Timeout, Transport: transport}}, nil
+}
+
+// internalPrefixes 不允许回调的地址段，其余的回环、私有、链路本地地址由 netip 判断
+var internalPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"), // 运营商级 NAT
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"), // 基准测试
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"), // NAT64，可映射到任意 IPv4
+}
+
+// isInternalAddress 判断是否为本机、内网、链路本地（含云厂商元数据地址）等不允许回调的地址
+func isInternalAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() {
+		return true
+	}
+	for _, prefix := range internalPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// rejectInternalAddress 作为 net.Dialer.Control 在建立连接前检查实际连接的 IP
+func rejectInternalAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("无效的 Webhook 连接地址: %s", address)
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return fmt.Errorf("无效的 Webhook 连接地址: %s", address)
+	}
+	if isInternalAddress(addr) {
+		return fmt.Errorf("不允许回调到内部地址: %s", host)
+	}
+	return nil
+}
+
+// SignWebhookPayload 计算签名: sha256=HEX(HMAC-SHA256(secret, timestamp + "." + body))
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature 供接收方校验签名
+func VerifyWebhookSignature(secret, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(SignWebhookPayload(secret, timestamp, body)), []byte(signature))
+}
+
+// ValidateWebhookURL 检查回调地址，只允许 http/https
+func ValidateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("无效的 Webhook 地址: %s", raw)
+	}
+	return nil
+}
+
+func (c *webhookChannel) Name() string {
+	return models.ChannelWebhook
+}
+
+func (c *webhookChannel) Send(ctx context.Context, user *models.User, notification *Notification) error {
+	if user.WebhookURL == "" {
+		return ErrChannelUnavailable
+	}
+	if err := ValidateWebhookURL(user.WebhookURL); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	body, err := json.Marshal(WebhookPayload{
+		Event:      notification.Kind,
+		UserID:     user.ID,
+		ReminderID: notification.ReminderID,
+		LogID:      notification.LogID,
+		Subject:    notification.Subject,
+		Text:       notification.PlainText(),
+		HTML:       notification.Body,
+		Actions:    notification.Actions,
+		SentAt:     now.UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("序列化 Webhook 请求失败: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, user.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("创建 Webhook 请求失败: %w", err)
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(c.config.Secret, timestamp, body))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送 Webhook 请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Webhook 返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -17,6 +17,10 @@ type UserStatistics struct {
 	CompletionRate  int `json:"completion_rate"`  // 完成率 (百分比)
 	LongestStreak   int `json:"longest_streak"`   // 最长连续完成天数
 	CurrentStreak   int `json:"current_streak"`   // 当前连续完成天数
+
+	RoutinesCompletedWeek  int `json:"routines_completed_week"`  // 本周完成的例程次数
+	RoutinesCompletedMonth int `json:"routines_completed_month"` // 本月完成的例程次数
+	RoutineCompletionRate  int `json:"routine_completion_rate"`  // 本月例程完成率 (百分比)，按第一步发送次数计算
 }
 
 // UserService 用户服务接口
@@ -37,13 +41,27 @@ type ReminderService interface {
 	EditReminder(ctx context.Context, params EditReminderParams) error
 	DeleteReminder(ctx context.Context, id uint) error
 	PauseReminder(ctx context.Context, id uint, duration time.Duration, reason string) error
+	// PauseReminderQuietly 同 PauseReminder，但到期后静默恢复，不通知用户
+	PauseReminderQuietly(ctx context.Context, id uint, duration time.Duration, reason string) error
 	ResumeReminder(ctx context.Context, id uint) error
 }
 
+// RoutineService 例程服务接口
+type RoutineService interface {
+	// CreateRoutine 将用户的多个提醒按顺序组成例程，delay 为上一步完成后发送下一步的间隔
+	CreateRoutine(ctx context.Context, userID uint, name string, reminderIDs []uint, delay time.Duration) (*models.Routine, error)
+	GetUserRoutines(ctx context.Context, userID uint) ([]*models.Routine, error)
+	// DeleteRoutine 删除例程，其中的提醒恢复为独立提醒
+	DeleteRoutine(ctx context.Context, userID, id uint) error
+	// AdvanceRoutine 例程中的一步完成后安排下一步，返回下一步的提醒；不属于例程或已是最后一步时返回 nil
+	AdvanceRoutine(ctx context.Context, log *models.ReminderLog) (*models.Reminder, error)
+}
+
 // ReminderLogService 提醒记录服务接口
 type ReminderLogService interface {
 	GetByID(ctx context.Context, id uint) (*models.ReminderLog, error)
-	MarkAsCompleted(ctx context.Context, id uint, response string) error
+	// MarkAsCompleted 标记提醒记录为已完成，记录此前已完成时返回 false（如重复点击）
+	MarkAsCompleted(ctx context.Context, id uint, response string) (bool, error)
 	MarkAsSkipped(ctx context.Context, id uint, response string) error
 	CreateDelayReminder(ctx context.Context, originalLogID uint, delayTime time.Time, hours int) error
 	GetOverdueReminders(ctx context.Context) ([]*models.ReminderLog, error)
@@ -58,12 +76,33 @@ type SchedulerService interface {
 	AddReminder(reminder *models.Reminder) error
 	RemoveReminder(reminderID uint) error
 	RefreshSchedules() error
+	// ScheduleDelivery 按提醒记录的 ScheduledTime 投递待发送的记录（如延期提醒）
+	ScheduleDelivery(log *models.ReminderLog) error
+	// NextOccurrences 返回提醒在 after 之后最多 n 次触发时间（提醒时区），已考虑暂停、结束条件和节假日
+	NextOccurrences(reminder *models.Reminder, after time.Time, n int) ([]time.Time, error)
+	// ScheduleAgenda 按用户设置的时间安排每日日程推送，AgendaTime 为空时取消
+	ScheduleAgenda(user *models.User)
+	// BuildAgenda 生成用户在 now 所在当天的日程
+	BuildAgenda(ctx context.Context, user *models.User, now time.Time) (*Agenda, error)
+	// SkipOccurrence 跳过提醒尚未到来的一次触发
+	SkipOccurrence(ctx context.Context, reminderID uint, occurrence time.Time) error
 }
 
 // NotificationService 通知服务接口
 type NotificationService interface {
 	SendReminder(ctx context.Context, log *models.ReminderLog) error
-	SendFollowUp(ctx context.Context, log *models.ReminderLog) error
+	// SendFollowUp 发送关怀消息，final 表示按策略这是最后一次关怀
+	SendFollowUp(ctx context.Context, log *models.ReminderLog, final bool) error
+	// SendMissedDigest 将同一用户错过的多条提醒汇总为一条消息发送
+	SendMissedDigest(ctx context.Context, logs []*models.ReminderLog) error
+	// SendResumed 通知用户暂停已到期、提醒已恢复
+	SendResumed(ctx context.Context, reminder *models.Reminder) error
+	// SendLeadNotice 在计划时刻 occurrence 之前 offset 发送提前通知
+	SendLeadNotice(ctx context.Context, reminder *models.Reminder, occurrence time.Time, offset time.Duration) error
+	// CloseMessages 用户响应后将该次提醒的其他消息更新为最终状态并移除按钮
+	CloseMessages(ctx context.Context, logID uint, text string, skipMessageID int) error
+	// SendAgenda 发送每日日程，附带跳过单次触发和当天暂停的按钮
+	SendAgenda(ctx context.Context, agenda *Agenda) error
 }
 
 // ConversationService 对话服务接口
@@ -89,3 +128,13 @@ type ConversationService interface {
 	// GetContextData 获取上下文数据
 	GetContextData(ctx context.Context, userID uint, contextType models.ContextType, target interface{}) error
 }
+
+// SendQueue Telegram 发送队列，按全局和单个会话的速率限制发送并重试临时失败
+// 实现 BotAPI，可直接替代 Bot 传给通知服务和消息处理器
+type SendQueue interface {
+	BotAPI
+	Start()
+	Stop()
+	// QueueDepth 返回排队中的消息数量
+	QueueDepth() int
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"mmemory/internal/repository/interfaces"
+	"mmemory/pkg/logger"
+	"mmemory/pkg/metrics"
+)
+
+const (
+	schedulerLeaseName = "scheduler"
+	defaultLeaseTTL    = 30 * time.Second
+)
+
+// leaderElector 基于数据库租约的主实例选举
+// 主实例每 ttl/3 续期一次；主实例宕机后，备用实例最迟在 ttl + ttl/3 内接管
+type leaderElector struct {
+	repo     interfaces.LeaseRepository
+	holder   string
+	ttl      time.Duration
+	interval time.Duration
+
+	mu       sync.RWMutex
+	leader   bool
+	validTil time.Time // 本地认定的租约有效期，按发起续期前的时间计算，偏保守
+
+	onElected func()
+	onDemoted func()
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newLeaderElector(repo interfaces.LeaseRepository, holder string, ttl time.Duration) *leaderElector {
+	if ttl <= 0 {
+		ttl = defaultLeaseTTL
+	}
+	return &leaderElector{
+		repo:     repo,
+		holder:   holder,
+		ttl:      ttl,
+		interval: ttl / 3,
+	}
+}
+
+// IsLeader 当前实例是否持有未过期的租约
+func (e *leaderElector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leader && time.Now().Before(e.validTil)
+}
+
+// tryAcquire 获取或续期租约，返回当前是否为主实例以及身份是否发生变化
+func (e *leaderElector) tryAcquire(ctx context.Context) (leader, changed bool) {
+	started := time.Now()
+	acquired, err := e.repo.Acquire(ctx, schedulerLeaseName, e.holder, e.ttl)
+	if err != nil {
+		logger.Errorf("获取调度租约失败 (实例: %s): %v", e.holder, err)
+		acquired = false
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// 续期失败但租约尚未到期时继续担任主实例，避免偶发的数据库错误导致频繁切换
+	if !acquired && e.leader && err != nil && started.Before(e.validTil) {
+		return true, false
+	}
+
+	changed = acquired != e.leader
+	e.leader = acquired
+	if acquired {
+		e.validTil = started.Add(e.ttl)
+		metrics.SetSchedulerLeader(1)
+	} else {
+		metrics.SetSchedulerLeader(0)
+	}
+	return acquired, changed
+}
+
+// Start 启动后台续期循环
+func (e *leaderElector) Start() {
+	e.stop = make(chan struct{})
+	e.done = make(chan struct{})
+
+	go func() {
+		defer close(e.done)
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-e.stop:
+				return
+			case <-ticker.C:
+				leader, changed := e.tryAcquire(context.Background())
+				if !changed {
+					continue
+				}
+				if leader {
+					logger.Infof("👑 已成为调度主实例 (实例: %s)", e.holder)
+					if e.onElected != nil {
+						e.onElected()
+					}
+				} else {
+					logger.Warnf("🪑 已失去调度租约，转为备用实例 (实例: %s)", e.holder)
+					if e.onDemoted != nil {
+						e.onDemoted()
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Stop 停止续期并释放租约，便于备用实例立即接管
+func (e *leaderElector) Stop() {
+	if e.stop != nil {
+		close(e.stop)
+		<-e.done
+		e.stop = nil
+	}
+
+	e.mu.Lock()
+	wasLeader := e.leader
+	e.leader = false
+	e.mu.Unlock()
+	metrics.SetSchedulerLeader(0)
+
+	if wasLeader {
+		if err := e.repo.Release(context.Background(), schedulerLeaseName, e.holder); err != nil {
+			logger.Errorf("释放调度租约失败 (实例: %s): %v", e.holder, err)
+		}
+	}
+}
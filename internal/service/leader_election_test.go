@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"mmemory/internal/models"
+)
+
+// mockLeaseRepository 内存中的租约仓储，多个调度器共用以模拟多实例
+type mockLeaseRepository struct {
+	mu     sync.Mutex
+	leases map[string]*models.SchedulerLease
+	err    error
+}
+
+func newMockLeaseRepository() *mockLeaseRepository {
+	return &mockLeaseRepository{leases: make(map[string]*models.SchedulerLease)}
+}
+
+func (m *mockLeaseRepository) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return false, m.err
+	}
+	now := time.Now()
+	if lease, ok := m.leases[name]; ok && lease.Holder != holder && !lease.IsExpired(now) {
+		return false, nil
+	}
+	m.leases[name] = &models.SchedulerLease{Name: name, Holder: holder, ExpiresAt: now.Add(ttl), UpdatedAt: now}
+	return true, nil
+}
+
+func (m *mockLeaseRepository) Release(ctx context.Context, name, holder string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if lease, ok := m.leases[name]; ok && lease.Holder == holder {
+		delete(m.leases, name)
+	}
+	return nil
+}
+
+func (m *mockLeaseRepository) Get(ctx context.Context, name string) (*models.SchedulerLease, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.leases[name], nil
+}
+
+func (m *mockLeaseRepository) setErr(err error) {
+	m.mu.Lock()
+	m.err = err
+	m.mu.Unlock()
+}
+
+func TestScheduler_LeaderElectionFailover(t *testing.T) {
+	reminderRepo := newMockReminderRepository()
+	leaseRepo := newMockLeaseRepository()
+	ctx := context.Background()
+
+	reminder := &models.Reminder{
+		UserID:          1,
+		Title:           "喝水",
+		SchedulePattern: "daily",
+		TargetTime:      "09:00:00",
+		IsActive:        true,
+		CreatedAt:       time.Now(),
+	}
+	reminderRepo.Create(ctx, reminder)
+
+	primaryNotification := newMockNotificationService()
+	primary := NewSchedulerService(reminderRepo, newMockReminderLogRepository(), primaryNotification).(*schedulerService)
+	primary.EnableLeaderElection(leaseRepo, "primary", 300*time.Millisecond)
+
+	standbyNotification := newMockNotificationService()
+	standby := NewSchedulerService(reminderRepo, newMockReminderLogRepository(), standbyNotification).(*schedulerService)
+	standby.EnableLeaderElection(leaseRepo, "standby", 300*time.Millisecond)
+
+	if err := primary.Start(); err != nil {
+		t.Fatalf("primary.Start() 失败: %v", err)
+	}
+	if err := standby.Start(); err != nil {
+		t.Fatalf("standby.Start() 失败: %v", err)
+	}
+	defer standby.Stop()
+
+	if !primary.isLeader() || standby.isLeader() {
+		t.Fatalf("先启动的实例应为主实例: primary=%v standby=%v", primary.isLeader(), standby.isLeader())
+	}
+
+	// 同一次触发只有主实例发送
+	occurrence := time.Now().Truncate(time.Minute)
+	primary.executeReminder(reminder.ID, occurrence)
+	standby.executeReminder(reminder.ID, occurrence)
+	if len(primaryNotification.sentReminders) != 1 || len(standbyNotification.sentReminders) != 0 {
+		t.Fatalf("只有主实例应发送提醒: primary=%d standby=%d",
+			len(primaryNotification.sentReminders), len(standbyNotification.sentReminders))
+	}
+
+	// 主实例停止后备用实例在有限时间内接管
+	primary.Stop()
+	deadline := time.Now().Add(time.Second)
+	for !standby.isLeader() && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if !standby.isLeader() {
+		t.Fatal("主实例停止后备用实例应接管")
+	}
+	time.Sleep(50 * time.Millisecond) // 等待接管流程完成
+
+	standby.executeReminder(reminder.ID, occurrence.Add(24*time.Hour))
+	if len(standbyNotification.sentReminders) != 1 {
+		t.Errorf("接管后应由新的主实例发送提醒，实际 %d", len(standbyNotification.sentReminders))
+	}
+}
+
+func TestLeaderElector_KeepsLeadershipOnTransientError(t *testing.T) {
+	leaseRepo := newMockLeaseRepository()
+	elector := newLeaderElector(leaseRepo, "a", time.Second)
+	ctx := context.Background()
+
+	if leader, changed := elector.tryAcquire(ctx); !leader || !changed {
+		t.Fatalf("首次获取应成为主实例: leader=%v changed=%v", leader, changed)
+	}
+
+	leaseRepo.setErr(errors.New("database is locked"))
+	if leader, changed := elector.tryAcquire(ctx); !leader || changed {
+		t.Errorf("租约未到期时偶发错误不应切换身份: leader=%v changed=%v", leader, changed)
+	}
+
+	// 本地租约到期后不再认为自己是主实例
+	elector.mu.Lock()
+	elector.validTil = time.Now().Add(-time.Millisecond)
+	elector.mu.Unlock()
+	if elector.IsLeader() {
+		t.Error("本地租约到期后不应继续作为主实例")
+	}
+	if leader, changed := elector.tryAcquire(ctx); leader || !changed {
+		t.Errorf("租约到期且续期失败应转为备用实例: leader=%v changed=%v", leader, changed)
+	}
+}
@@ -2,12 +2,17 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
 	"mmemory/internal/models"
+	"mmemory/internal/repository/interfaces"
 	"mmemory/pkg/logger"
+	"mmemory/pkg/msgtemplate"
 )
 
 // BotAPI 接口（用于测试）
@@ -15,125 +20,446 @@ type BotAPI interface {
 	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
 }
 
+// defaultChannels 未设置偏好时使用的渠道
+var defaultChannels = []string{models.ChannelTelegram}
+
 type notificationService struct {
-	bot BotAPI
+	bot             BotAPI
+	channels        map[string]NotificationChannel
+	defaultChannels []string
+	templates       *msgtemplate.Set
+	logRepo         interfaces.ReminderLogRepository
+	messageRepo     interfaces.ReminderMessageRepository
+	location        *time.Location // 默认时区，与调度器一致，提醒和用户均未设置时区时用于展示时间
 }
 
+// NewNotificationService 创建通知服务，默认只有 Telegram 渠道，其他渠道通过 RegisterChannel 添加
 func NewNotificationService(bot BotAPI) NotificationService {
-	return &notificationService{
-		bot: bot,
+	// 与调度器相同，默认使用北京时区，可通过 SetDefaultTimezone 覆盖
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		loc = time.Local
+	}
+
+	s := &notificationService{
+		bot:             bot,
+		channels:        make(map[string]NotificationChannel),
+		defaultChannels: defaultChannels,
+		templates:       msgtemplate.NewSet(""),
+		location:        loc,
+	}
+	s.RegisterChannel(NewTelegramChannel(bot))
+	return s
+}
+
+// RegisterChannel 注册通知渠道，同名渠道会被替换；需在开始发送前调用
+func (s *notificationService) RegisterChannel(channel NotificationChannel) {
+	s.channels[channel.Name()] = channel
+}
+
+// SetDefaultChannels 设置用户和提醒都未指定偏好时依次尝试的渠道
+func (s *notificationService) SetDefaultChannels(spec string) error {
+	channels, err := models.ParseChannels(spec)
+	if err != nil {
+		return err
+	}
+	s.defaultChannels = channels
+	return nil
+}
+
+// SetDefaultTimezone 设置默认时区（对应 SchedulerConfig.Timezone），保证展示的时间与调度使用的时区一致
+func (s *notificationService) SetDefaultTimezone(name string) error {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("无效的时区: %s", name)
+	}
+	s.location = loc
+	return nil
+}
+
+// SetTemplates 设置消息模板，未设置时只使用内置的中文模板
+func (s *notificationService) SetTemplates(templates *msgtemplate.Set) {
+	s.templates = templates
+}
+
+// SetReminderLogRepository 设置提醒记录仓储，用于在习惯提醒中显示连续完成次数
+func (s *notificationService) SetReminderLogRepository(repo interfaces.ReminderLogRepository) {
+	s.logRepo = repo
+}
+
+// SetMessageRepository 设置提醒消息仓储：记录发出的 Telegram 消息，关怀以回复原提醒的形式发送，
+// 用户响应后通过 CloseMessages 更新全部相关消息
+func (s *notificationService) SetMessageRepository(repo interfaces.ReminderMessageRepository) {
+	s.messageRepo = repo
+}
+
+// deliver 按顺序尝试各渠道，任一渠道成功即返回；全部失败时返回汇总的错误
+func (s *notificationService) deliver(ctx context.Context, user *models.User, channels []string, notification *Notification) error {
+	var failures []string
+	for _, name := range channels {
+		channel, ok := s.channels[name]
+		if !ok {
+			failures = append(failures, fmt.Sprintf("%s: 渠道未启用", name))
+			continue
+		}
+
+		err := channel.Send(ctx, user, notification)
+		if err == nil {
+			if len(failures) > 0 {
+				logger.Infof("📮 已改用 %s 渠道发送通知: 用户=%d, 之前失败: %s", name, user.ID, strings.Join(failures, "; "))
+			}
+			return nil
+		}
+		if !errors.Is(err, ErrChannelUnavailable) {
+			logger.Warnf("通知渠道 %s 发送失败，尝试下一个渠道 (用户: %d): %v", name, user.ID, err)
+		}
+		failures = append(failures, fmt.Sprintf("%s: %v", name, err))
 	}
+	return fmt.Errorf("所有通知渠道均发送失败: %s", strings.Join(failures, "; "))
 }
 
 func (s *notificationService) SendReminder(ctx context.Context, log *models.ReminderLog) error {
-	if log.Reminder.User.TelegramID == 0 {
-		return fmt.Errorf("用户Telegram ID为空")
+	// 构建提醒消息
+	message, err := s.buildReminderMessage(ctx, log)
+	if err != nil {
+		return fmt.Errorf("构建提醒消息失败: %w", err)
 	}
+	notification := newNotification(NotificationKindReminder, message, log.Reminder.Title)
+	notification.Actions = s.buildReminderActions(log.ID)
+	notification.ReminderID, notification.LogID = log.ReminderID, log.ID
 	
-	// 构建提醒消息
-	message := s.buildReminderMessage(&log.Reminder)
+	if err := s.deliver(ctx, &log.Reminder.User, log.Reminder.ResolveChannels(s.defaultChannels), notification); err != nil {
+		return fmt.Errorf("发送提醒消息失败: %w", err)
+	}
+	s.trackMessage(ctx, log, notification)
+	
+	logger.Infof("📤 提醒消息已发送: 用户=%d, 提醒=%s", 
+		log.Reminder.User.ID, log.Reminder.Title)
 	
-	// 创建键盘按钮
-	keyboard := s.buildReminderKeyboard(log.ID)
+	return nil
+}
+
+func (s *notificationService) SendFollowUp(ctx context.Context, log *models.ReminderLog, final bool) error {
+	// 构建关怀消息
+	message, err := s.buildFollowUpMessage(log, final)
+	if err != nil {
+		return fmt.Errorf("构建关怀消息失败: %w", err)
+	}
+	notification := newNotification(NotificationKindFollowUp, message, log.Reminder.Title)
+	notification.Actions = s.buildReminderActions(log.ID)
+	notification.ReminderID, notification.LogID = log.ReminderID, log.ID
+	notification.ReplyTo = s.originalMessageID(ctx, log.ID)
+	
+	if err := s.deliver(ctx, &log.Reminder.User, log.Reminder.ResolveChannels(s.defaultChannels), notification); err != nil {
+		return fmt.Errorf("发送关怀消息失败: %w", err)
+	}
+	s.trackMessage(ctx, log, notification)
+	
+	logger.Infof("💌 关怀消息已发送: 用户=%d, 次数=%d", 
+		log.Reminder.User.ID, log.FollowUpCount+1)
 	
-	// 发送消息
-	msg := tgbotapi.NewMessage(log.Reminder.User.TelegramID, message)
-	msg.ReplyMarkup = keyboard
-	msg.ParseMode = tgbotapi.ModeHTML
+	return nil
+}
+
+func (s *notificationService) SendMissedDigest(ctx context.Context, logs []*models.ReminderLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
 	
-	_, err := s.bot.Send(msg)
+	user := logs[0].Reminder.User
+	message, err := s.buildMissedDigestMessage(logs)
 	if err != nil {
-		return fmt.Errorf("发送Telegram消息失败: %w", err)
+		return fmt.Errorf("构建错过提醒汇总失败: %w", err)
 	}
+	notification := newNotification(NotificationKindMissedDigest, message, "")
 	
-	logger.Infof("📤 提醒消息已发送: 用户=%d, 提醒=%s", 
-		log.Reminder.User.TelegramID, log.Reminder.Title)
+	if err := s.deliver(ctx, &user, user.ResolveChannels(s.defaultChannels), notification); err != nil {
+		return fmt.Errorf("发送错过提醒汇总失败: %w", err)
+	}
+	
+	logger.Infof("📨 错过提醒汇总已发送: 用户=%d, 条数=%d", user.ID, len(logs))
 	
 	return nil
 }
 
-func (s *notificationService) SendFollowUp(ctx context.Context, log *models.ReminderLog) error {
-	if log.Reminder.User.TelegramID == 0 {
-		return fmt.Errorf("用户Telegram ID为空")
+func (s *notificationService) SendResumed(ctx context.Context, reminder *models.Reminder) error {
+	message, err := s.render(reminder, msgtemplate.Resumed, reminderData(reminder))
+	if err != nil {
+		return fmt.Errorf("构建恢复通知失败: %w", err)
 	}
+	notification := newNotification(NotificationKindResumed, message, reminder.Title)
+	notification.ReminderID = reminder.ID
 	
-	// 构建关怀消息
-	message := s.buildFollowUpMessage(&log.Reminder, log.FollowUpCount)
+	if err := s.deliver(ctx, &reminder.User, reminder.ResolveChannels(s.defaultChannels), notification); err != nil {
+		return fmt.Errorf("发送恢复通知失败: %w", err)
+	}
 	
-	// 创建键盘按钮
-	keyboard := s.buildReminderKeyboard(log.ID)
+	logger.Infof("▶️ 恢复通知已发送: 用户=%d, 提醒=%s", reminder.User.ID, reminder.Title)
 	
-	// 发送消息
-	msg := tgbotapi.NewMessage(log.Reminder.User.TelegramID, message)
-	msg.ReplyMarkup = keyboard
-	msg.ParseMode = tgbotapi.ModeHTML
+	return nil
+}
+
+// SendLeadNotice 在计划时刻之前发送提前通知，仅作提示，不带操作按钮
+func (s *notificationService) SendLeadNotice(ctx context.Context, reminder *models.Reminder, occurrence time.Time, offset time.Duration) error {
+	data := reminderData(reminder)
+	data.ScheduledTime = s.inReminderZone(reminder, occurrence)
+	data.Lead = offset
 	
-	_, err := s.bot.Send(msg)
+	message, err := s.render(reminder, msgtemplate.LeadNotice, data)
 	if err != nil {
-		return fmt.Errorf("发送关怀消息失败: %w", err)
+		return fmt.Errorf("构建提前通知失败: %w", err)
 	}
+	notification := newNotification(NotificationKindLeadNotice, message, reminder.Title)
+	notification.ReminderID = reminder.ID
 	
-	logger.Infof("💌 关怀消息已发送: 用户=%d, 次数=%d", 
-		log.Reminder.User.TelegramID, log.FollowUpCount+1)
+	if err := s.deliver(ctx, &reminder.User, reminder.ResolveChannels(s.defaultChannels), notification); err != nil {
+		return fmt.Errorf("发送提前通知失败: %w", err)
+	}
+	
+	logger.Infof("🔔 提前通知已发送: 用户=%d, 提醒=%s, 提前=%s", reminder.User.ID, reminder.Title, models.DescribeLeadTime(offset))
 	
 	return nil
 }
 
-// buildReminderMessage 构建提醒消息
-func (s *notificationService) buildReminderMessage(reminder *models.Reminder) string {
-	var message string
+// maxAgendaActions 每日日程中最多为多少个事项提供按钮，Telegram 单条消息最多 100 个按钮
+const maxAgendaActions = 20
+
+// SendAgenda 发送每日日程，每个待提醒事项附带"跳过这次"和"今天暂停"按钮
+func (s *notificationService) SendAgenda(ctx context.Context, agenda *Agenda) error {
+	data := msgtemplate.Data{Date: agenda.Date}
+	for _, item := range agenda.Upcoming {
+		data.Agenda = append(data.Agenda, msgtemplate.AgendaItem{Title: item.Reminder.Title, ScheduledTime: item.Occurrence})
+	}
+	loc := agenda.Date.Location()
+	for _, log := range agenda.Missed {
+		data.Missed = append(data.Missed, msgtemplate.MissedItem{
+			Title:         log.Reminder.Title,
+			ScheduledTime: log.ScheduledTime.In(loc),
+			Skipped:       log.Status == models.ReminderStatusSkipped,
+		})
+	}
 	
-	// 根据提醒类型使用不同的emoji和措辞
-	switch reminder.Type {
-	case models.ReminderTypeHabit:
-		message = fmt.Sprintf("⏰ <b>习惯提醒</b>\n\n"+
-			"📝 %s\n\n"+
-			"已经到了约定的时间，完成了吗？", reminder.Title)
-	case models.ReminderTypeTask:
-		message = fmt.Sprintf("📋 <b>任务提醒</b>\n\n"+
-			"📝 %s\n\n"+
-			"该处理这个任务了，准备好了吗？", reminder.Title)
-	default:
-		message = fmt.Sprintf("🔔 <b>提醒</b>\n\n"+
-			"📝 %s\n\n"+
-			"时间到了，请查看！", reminder.Title)
+	message, err := s.templates.Render(agenda.User.LanguageCode, msgtemplate.Agenda, "", data)
+	if err != nil {
+		return fmt.Errorf("构建每日日程失败: %w", err)
 	}
+	notification := newNotification(NotificationKindAgenda, message, "")
+	notification.Actions = s.buildAgendaActions(agenda)
 	
-	return message
+	if err := s.deliver(ctx, agenda.User, agenda.User.ResolveChannels(s.defaultChannels), notification); err != nil {
+		return fmt.Errorf("发送每日日程失败: %w", err)
+	}
+	
+	logger.Infof("☀️ 每日日程已发送: 用户=%d, 待提醒=%d, 昨天未完成=%d", agenda.User.ID, len(agenda.Upcoming), len(agenda.Missed))
+	
+	return nil
+}
+
+// buildAgendaActions 为日程中的事项构建按钮，每个事项一行：跳过这次、今天暂停
+func (s *notificationService) buildAgendaActions(agenda *Agenda) []NotificationAction {
+	var actions []NotificationAction
+	pauseUntil := agenda.End().Unix()
+	for _, item := range agenda.Upcoming[:min(len(agenda.Upcoming), maxAgendaActions)] {
+		actions = append(actions,
+			NotificationAction{
+				Label: fmt.Sprintf("⏭️ %s %s", item.Occurrence.Format("15:04"), truncateLabel(item.Reminder.Title, 12)),
+				Data:  fmt.Sprintf("reminder_skipat_%d_%d", item.Reminder.ID, item.Occurrence.Unix()),
+			},
+			NotificationAction{
+				Label: "⏸️ 今天暂停",
+				Data:  fmt.Sprintf("reminder_pauseuntil_%d_%d", item.Reminder.ID, pauseUntil),
+			},
+		)
+	}
+	return actions
+}
+
+// truncateLabel 截断按钮文字，超出部分以省略号表示
+func truncateLabel(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit]) + "…"
+}
+
+// CloseMessages 用户响应后将该次提醒发出的 Telegram 消息更新为 text 并移除按钮，之后不再跟踪这些消息；
+// skipMessageID 为调用方已自行更新的消息（如用户点击的那条），为 0 时更新全部
+func (s *notificationService) CloseMessages(ctx context.Context, logID uint, text string, skipMessageID int) error {
+	if s.messageRepo == nil {
+		return nil
+	}
+	
+	messages, err := s.messageRepo.GetByLogID(ctx, logID)
+	if err != nil {
+		return fmt.Errorf("查询提醒消息失败: %w", err)
+	}
+	
+	var failures []string
+	for _, message := range messages {
+		if message.MessageID == skipMessageID {
+			continue
+		}
+		edit := tgbotapi.NewEditMessageText(message.ChatID, message.MessageID, text)
+		edit.ParseMode = tgbotapi.ModeHTML
+		if _, err := s.bot.Send(edit); err != nil {
+			failures = append(failures, fmt.Sprintf("%d: %v", message.MessageID, err))
+		}
+	}
+	
+	// 编辑失败多为消息已被用户删除，不再重试
+	if err := s.messageRepo.DeleteByLogID(ctx, logID); err != nil {
+		logger.Warnf("删除提醒消息记录失败 (LogID: %d): %v", logID, err)
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("更新提醒消息失败: %s", strings.Join(failures, "; "))
+	}
+	return nil
 }
 
-// buildFollowUpMessage 构建关怀消息
-func (s *notificationService) buildFollowUpMessage(reminder *models.Reminder, followUpCount int) string {
-	var message string
+// trackMessage 记录经 Telegram 发出的提醒消息，未设置消息仓储或经其他渠道发送时忽略
+func (s *notificationService) trackMessage(ctx context.Context, log *models.ReminderLog, notification *Notification) {
+	if s.messageRepo == nil || notification.MessageID == 0 {
+		return
+	}
 	
-	switch followUpCount {
-	case 0:
-		message = fmt.Sprintf("🤔 <b>还没完成吗？</b>\n\n"+
-			"📝 %s\n\n"+
-			"没关系，有什么困难吗？需要延期还是跳过？", reminder.Title)
-	case 1:
-		message = fmt.Sprintf("😊 <b>温馨提醒</b>\n\n"+
-			"📝 %s\n\n"+
-			"这个任务还在等着你呢，要不要处理一下？", reminder.Title)
-	default:
-		message = fmt.Sprintf("💪 <b>最后提醒</b>\n\n"+
-			"📝 %s\n\n"+
-			"今天确实不方便的话，可以选择跳过哦～", reminder.Title)
+	message := &models.ReminderMessage{
+		ReminderLogID: log.ID,
+		ChatID:        log.Reminder.User.TelegramID,
+		MessageID:     notification.MessageID,
+		Kind:          notification.Kind,
+	}
+	if err := s.messageRepo.Create(ctx, message); err != nil {
+		logger.Warnf("记录提醒消息失败，响应后将无法更新该消息 (LogID: %d): %v", log.ID, err)
+	}
+}
+
+// originalMessageID 返回该次提醒最早发出的 Telegram 消息，关怀以回复它的形式发送；没有记录时返回 0
+func (s *notificationService) originalMessageID(ctx context.Context, logID uint) int {
+	if s.messageRepo == nil {
+		return 0
 	}
 	
-	return message
+	messages, err := s.messageRepo.GetByLogID(ctx, logID)
+	if err != nil {
+		logger.Warnf("查询提醒消息失败，关怀将作为新消息发送 (LogID: %d): %v", logID, err)
+		return 0
+	}
+	if len(messages) == 0 {
+		return 0
+	}
+	return messages[0].MessageID
+}
+
+// render 按用户语言和提醒类型渲染模板
+func (s *notificationService) render(reminder *models.Reminder, name string, data msgtemplate.Data) (string, error) {
+	return s.templates.Render(reminder.User.LanguageCode, name, string(reminder.Type), data)
+}
+
+// reminderData 提醒本身的模板变量
+func reminderData(reminder *models.Reminder) msgtemplate.Data {
+	return msgtemplate.Data{
+		Title:           reminder.Title,
+		Description:     reminder.Description,
+		Type:            string(reminder.Type),
+		OccurrenceCount: reminder.OccurrenceCount,
+		MaxOccurrences:  reminder.MaxOccurrences,
+	}
+}
+
+// logData 一次触发的模板变量，计划时间按提醒所在时区展示
+func (s *notificationService) logData(log *models.ReminderLog) msgtemplate.Data {
+	data := reminderData(&log.Reminder)
+	data.ScheduledTime = s.inReminderZone(&log.Reminder, log.ScheduledTime)
+	data.FollowUpCount = log.FollowUpCount
+	return data
+}
+
+// inReminderZone 把时间换算到提醒的时区，与调度器相同依次回退到用户时区和默认时区
+func (s *notificationService) inReminderZone(reminder *models.Reminder, t time.Time) time.Time {
+	for _, name := range []string{reminder.Timezone, reminder.User.Timezone} {
+		if loc, err := time.LoadLocation(name); err == nil && name != "" {
+			return t.In(loc)
+		}
+	}
+	if s.location != nil {
+		return t.In(s.location)
+	}
+	return t
+}
+
+// buildMissedDigestMessage 构建错过提醒汇总消息，时间按提醒所在时区展示
+func (s *notificationService) buildMissedDigestMessage(logs []*models.ReminderLog) (string, error) {
+	data := msgtemplate.Data{Missed: make([]msgtemplate.MissedItem, 0, len(logs))}
+	for _, log := range logs {
+		data.Missed = append(data.Missed, msgtemplate.MissedItem{
+			Title:         log.Reminder.Title,
+			ScheduledTime: s.inReminderZone(&log.Reminder, log.ScheduledTime),
+		})
+	}
+	return s.templates.Render(logs[0].Reminder.User.LanguageCode, msgtemplate.MissedDigest, "", data)
+}
+
+// buildReminderMessage 构建提醒消息，习惯提醒附带连续完成次数
+func (s *notificationService) buildReminderMessage(ctx context.Context, log *models.ReminderLog) (string, error) {
+	data := s.logData(log)
+	if log.Reminder.Type == models.ReminderTypeHabit {
+		data.Streak = s.streak(ctx, log)
+	}
+	return s.render(&log.Reminder, msgtemplate.Reminder, data)
+}
+
+// buildFollowUpMessage 构建关怀消息，最后一次关怀使用单独的措辞
+func (s *notificationService) buildFollowUpMessage(log *models.ReminderLog, final bool) (string, error) {
+	name := msgtemplate.FollowUpRepeat
+	switch {
+	case final:
+		name = msgtemplate.FollowUpFinal
+	case log.FollowUpCount == 0:
+		name = msgtemplate.FollowUp
+	}
+	return s.render(&log.Reminder, name, s.logData(log))
+}
+
+// streakLookback 计算连续完成次数时最多查看的记录数
+const streakLookback = 100
+
+// streak 统计本次之前最近连续完成的次数，未设置记录仓储或查询失败时返回 0
+func (s *notificationService) streak(ctx context.Context, current *models.ReminderLog) int {
+	if s.logRepo == nil {
+		return 0
+	}
+	
+	logs, err := s.logRepo.GetByReminderID(ctx, current.ReminderID, streakLookback, 0)
+	if err != nil {
+		logger.Warnf("查询提醒记录失败，不显示连续完成次数 (提醒: %d): %v", current.ReminderID, err)
+		return 0
+	}
+	
+	streak := 0
+	for _, log := range logs {
+		if log.ID == current.ID || !log.ScheduledTime.Before(current.ScheduledTime) {
+			continue
+		}
+		if !log.IsCompleted() {
+			break
+		}
+		streak++
+	}
+	return streak
 }
 
 // buildReminderKeyboard 构建回复键盘
 func (s *notificationService) buildReminderKeyboard(logID uint) tgbotapi.InlineKeyboardMarkup {
-	return tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("✅ 完成了", fmt.Sprintf("reminder_complete_%d", logID)),
-			tgbotapi.NewInlineKeyboardButtonData("⏰ 延期1小时", fmt.Sprintf("reminder_delay_%d_1", logID)),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("⏰ 延期3小时", fmt.Sprintf("reminder_delay_%d_3", logID)),
-			tgbotapi.NewInlineKeyboardButtonData("😴 今天跳过", fmt.Sprintf("reminder_skip_%d", logID)),
-		),
-	)
-}
\ No newline at end of file
+	return actionKeyboard(s.buildReminderActions(logID))
+}
+
+// buildReminderActions 构建提醒的操作，Telegram 中显示为回复按钮
+func (s *notificationService) buildReminderActions(logID uint) []NotificationAction {
+	return []NotificationAction{
+		{Label: "✅ 完成了", Data: fmt.Sprintf("reminder_complete_%d", logID)},
+		{Label: "⏰ 延期1小时", Data: fmt.Sprintf("reminder_delay_%d_1", logID)},
+		{Label: "⏰ 延期3小时", Data: fmt.Sprintf("reminder_delay_%d_3", logID)},
+		{Label: "😴 今天跳过", Data: fmt.Sprintf("reminder_skip_%d", logID)},
+	}
+}
@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -10,6 +12,7 @@ import (
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
 	"mmemory/internal/models"
+	"mmemory/pkg/msgtemplate"
 )
 
 // Mock Bot API for testing
@@ -23,7 +26,7 @@ func (m *mockBotAPI) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
 		return tgbotapi.Message{}, fmt.Errorf("mock send error")
 	}
 	m.sentMessages = append(m.sentMessages, c)
-	return tgbotapi.Message{MessageID: 1}, nil
+	return tgbotapi.Message{MessageID: len(m.sentMessages)}, nil
 }
 
 func (m *mockBotAPI) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
@@ -168,6 +171,7 @@ func TestNotificationService_SendFollowUp(t *testing.T) {
 	tests := []struct {
 		name          string
 		followUpCount int
+		final         bool
 		wantErr       bool
 		wantContains  []string
 	}{
@@ -184,9 +188,16 @@ func TestNotificationService_SendFollowUp(t *testing.T) {
 			wantContains:  []string{"温馨提醒", "运动提醒"},
 		},
 		{
-			name:          "第三次关怀消息",
+			name:          "未到上限的后续关怀消息",
 			followUpCount: 2,
 			wantErr:       false,
+			wantContains:  []string{"温馨提醒", "运动提醒"},
+		},
+		{
+			name:          "最后一次关怀消息",
+			followUpCount: 2,
+			final:         true,
+			wantErr:       false,
 			wantContains:  []string{"最后提醒", "运动提醒"},
 		},
 	}
@@ -205,7 +216,7 @@ func TestNotificationService_SendFollowUp(t *testing.T) {
 				Reminder:      *reminder,
 			}
 
-			err := service.SendFollowUp(ctx, log)
+			err := service.SendFollowUp(ctx, log, tt.final)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("SendFollowUp() error = %v, wantErr %v", err, tt.wantErr)
@@ -234,6 +245,61 @@ func TestNotificationService_SendFollowUp(t *testing.T) {
 	}
 }
 
+func TestNotificationService_SendResumed(t *testing.T) {
+	mockBot := &mockBotAPI{}
+	service := NewNotificationService(mockBot)
+
+	reminder := &models.Reminder{
+		ID:    1,
+		Title: "晨跑",
+		User:  models.User{ID: 1, TelegramID: 123456789},
+	}
+
+	if err := service.SendResumed(context.Background(), reminder); err != nil {
+		t.Fatalf("SendResumed() error = %v", err)
+	}
+
+	msg, ok := mockBot.GetLastSentMessage().(tgbotapi.MessageConfig)
+	if !ok {
+		t.Fatal("SendResumed() 未发送文本消息")
+	}
+	for _, want := range []string{"你的提醒已恢复", "晨跑"} {
+		if !strings.Contains(msg.Text, want) {
+			t.Errorf("消息内容应包含 %q，实际: %s", want, msg.Text)
+		}
+	}
+}
+
+func TestNotificationService_SendLeadNotice(t *testing.T) {
+	mockBot := &mockBotAPI{}
+	service := NewNotificationService(mockBot)
+
+	reminder := &models.Reminder{
+		ID:       1,
+		Title:    "看牙医",
+		Timezone: "Asia/Shanghai",
+		User:     models.User{ID: 1, TelegramID: 123456789},
+	}
+	occurrence := time.Date(2026, 5, 2, 7, 0, 0, 0, time.UTC)
+
+	if err := service.SendLeadNotice(context.Background(), reminder, occurrence, 30*time.Minute); err != nil {
+		t.Fatalf("SendLeadNotice() error = %v", err)
+	}
+
+	msg, ok := mockBot.GetLastSentMessage().(tgbotapi.MessageConfig)
+	if !ok {
+		t.Fatal("SendLeadNotice() 未发送文本消息")
+	}
+	if msg.ReplyMarkup != nil {
+		t.Error("提前通知不应带操作按钮")
+	}
+	for _, want := range []string{"提前提醒", "看牙医", "05-02 15:00", "30分钟后"} {
+		if !strings.Contains(msg.Text, want) {
+			t.Errorf("消息内容应包含 %q，实际: %s", want, msg.Text)
+		}
+	}
+}
+
 func TestNotificationService_SendError(t *testing.T) {
 	user := &models.User{
 		ID:         1,
@@ -299,3 +365,273 @@ func TestNotificationService_BuildReminderKeyboard(t *testing.T) {
 		t.Errorf("完成按钮数据 = %s, want %s", completeData, expectedComplete)
 	}
 }
+
+func TestNotificationService_Templates(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "en.yaml"), []byte(`
+templates:
+  reminder.habit: "Habit {{.Title}}{{if .Streak}}, streak {{.Streak}}{{end}} at {{.ScheduledTime.Format \"15:04\"}}"
+  follow_up_final: "Last call: {{.Title}}"
+`), 0o644); err != nil {
+		t.Fatalf("写入模板失败: %v", err)
+	}
+	templates := msgtemplate.NewSet(dir)
+	if err := templates.Load(); err != nil {
+		t.Fatalf("加载模板失败: %v", err)
+	}
+
+	scheduled := time.Date(2026, 3, 5, 0, 30, 0, 0, time.UTC)
+	reminder := models.Reminder{
+		ID:       1,
+		Title:    "跑步",
+		Type:     models.ReminderTypeHabit,
+		Timezone: "Asia/Shanghai",
+		User:     models.User{ID: 1, TelegramID: 123456789, LanguageCode: "en-US"},
+	}
+
+	// 之前连续完成两次，再往前一次跳过
+	logRepo := newMockReminderLogRepository()
+	for i, status := range []models.ReminderStatus{models.ReminderStatusSkipped, models.ReminderStatusCompleted, models.ReminderStatusCompleted} {
+		_ = logRepo.Create(ctx, &models.ReminderLog{ReminderID: 1, ScheduledTime: scheduled.AddDate(0, 0, i-3), Status: status})
+	}
+	log := &models.ReminderLog{ReminderID: 1, Reminder: reminder, ScheduledTime: scheduled, Status: models.ReminderStatusPending}
+	_ = logRepo.Create(ctx, log)
+
+	mockBot := &mockBotAPI{}
+	service := NewNotificationService(mockBot).(*notificationService)
+	service.SetTemplates(templates)
+	service.SetReminderLogRepository(logRepo)
+
+	if err := service.SendReminder(ctx, log); err != nil {
+		t.Fatalf("SendReminder() 失败: %v", err)
+	}
+	if got := mockBot.GetLastSentMessage().(tgbotapi.MessageConfig).Text; got != "Habit 跑步, streak 2 at 08:30" {
+		t.Errorf("英文提醒 = %q", got)
+	}
+
+	if err := service.SendFollowUp(ctx, log, true); err != nil {
+		t.Fatalf("SendFollowUp() 失败: %v", err)
+	}
+	if got := mockBot.GetLastSentMessage().(tgbotapi.MessageConfig).Text; got != "Last call: 跑步" {
+		t.Errorf("英文最后提醒 = %q", got)
+	}
+
+	// 文件中没有的模板使用内置中文
+	if err := service.SendFollowUp(ctx, log, false); err != nil {
+		t.Fatalf("SendFollowUp() 失败: %v", err)
+	}
+	if got := mockBot.GetLastSentMessage().(tgbotapi.MessageConfig).Text; !strings.Contains(got, "还没完成吗") {
+		t.Errorf("缺少的模板应使用内置中文，实际为 %q", got)
+	}
+}
+
+// TestNotificationService_DefaultTimezone 测试提醒和用户都未设置时区时，按调度器的默认时区展示时间
+func TestNotificationService_DefaultTimezone(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "en.yaml"), []byte(`
+templates:
+  reminder: "{{.Title}} at {{.ScheduledTime.Format \"15:04\"}}"
+`), 0o644); err != nil {
+		t.Fatalf("写入模板失败: %v", err)
+	}
+	templates := msgtemplate.NewSet(dir)
+	if err := templates.Load(); err != nil {
+		t.Fatalf("加载模板失败: %v", err)
+	}
+
+	log := &models.ReminderLog{
+		ID:            1,
+		ReminderID:    1,
+		Reminder:      models.Reminder{ID: 1, Title: "喝水", User: models.User{ID: 1, TelegramID: 123456789, LanguageCode: "en"}},
+		ScheduledTime: time.Date(2026, 3, 5, 1, 0, 0, 0, time.UTC),
+		Status:        models.ReminderStatusPending,
+	}
+
+	mockBot := &mockBotAPI{}
+	service := NewNotificationService(mockBot).(*notificationService)
+	service.SetTemplates(templates)
+
+	// 未配置时与调度器相同使用北京时区
+	if err := service.SendReminder(ctx, log); err != nil {
+		t.Fatalf("SendReminder() 失败: %v", err)
+	}
+	if got := mockBot.GetLastSentMessage().(tgbotapi.MessageConfig).Text; got != "喝水 at 09:00" {
+		t.Errorf("默认时区提醒 = %q", got)
+	}
+
+	if err := service.SetDefaultTimezone("Asia/Tokyo"); err != nil {
+		t.Skipf("时区数据不可用: %v", err)
+	}
+	if err := service.SendReminder(ctx, log); err != nil {
+		t.Fatalf("SendReminder() 失败: %v", err)
+	}
+	if got := mockBot.GetLastSentMessage().(tgbotapi.MessageConfig).Text; got != "喝水 at 10:00" {
+		t.Errorf("配置默认时区后提醒 = %q", got)
+	}
+	if err := service.SetDefaultTimezone("Invalid/Zone"); err == nil {
+		t.Error("无效时区应返回错误")
+	}
+}
+
+type mockReminderMessageRepository struct {
+	messages []*models.ReminderMessage
+}
+
+func (m *mockReminderMessageRepository) Create(ctx context.Context, message *models.ReminderMessage) error {
+	message.ID = uint(len(m.messages) + 1)
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+func (m *mockReminderMessageRepository) GetByLogID(ctx context.Context, logID uint) ([]*models.ReminderMessage, error) {
+	var result []*models.ReminderMessage
+	for _, message := range m.messages {
+		if message.ReminderLogID == logID {
+			result = append(result, message)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockReminderMessageRepository) DeleteByLogID(ctx context.Context, logID uint) error {
+	var kept []*models.ReminderMessage
+	for _, message := range m.messages {
+		if message.ReminderLogID != logID {
+			kept = append(kept, message)
+		}
+	}
+	m.messages = kept
+	return nil
+}
+
+func TestNotificationService_TracksAndClosesMessages(t *testing.T) {
+	ctx := context.Background()
+	user := models.User{ID: 1, TelegramID: 123456789}
+	log := &models.ReminderLog{
+		ID:         7,
+		ReminderID: 1,
+		Reminder:   models.Reminder{ID: 1, Title: "喝水", Type: models.ReminderTypeHabit, User: user},
+	}
+
+	mockBot := &mockBotAPI{}
+	messageRepo := &mockReminderMessageRepository{}
+	service := NewNotificationService(mockBot).(*notificationService)
+	service.SetMessageRepository(messageRepo)
+
+	if err := service.SendReminder(ctx, log); err != nil {
+		t.Fatalf("SendReminder() 失败: %v", err)
+	}
+	if err := service.SendFollowUp(ctx, log, false); err != nil {
+		t.Fatalf("SendFollowUp() 失败: %v", err)
+	}
+	log.FollowUpCount = 1
+	if err := service.SendFollowUp(ctx, log, true); err != nil {
+		t.Fatalf("SendFollowUp() 失败: %v", err)
+	}
+
+	for i, msg := range mockBot.sentMessages[1:] {
+		if reply := msg.(tgbotapi.MessageConfig).ReplyToMessageID; reply != 1 {
+			t.Errorf("第 %d 次关怀应回复原提醒消息 1，实际为 %d", i+1, reply)
+		}
+	}
+	if len(messageRepo.messages) != 3 {
+		t.Fatalf("应记录 3 条消息，实际为 %d", len(messageRepo.messages))
+	}
+	if messageRepo.messages[0].Kind != NotificationKindReminder || messageRepo.messages[2].Kind != NotificationKindFollowUp {
+		t.Errorf("消息类型记录不正确: %+v", messageRepo.messages)
+	}
+
+	// 用户点击了最后一条关怀，其余两条由通知服务更新
+	if err := service.CloseMessages(ctx, log.ID, "✅ 已完成", 3); err != nil {
+		t.Fatalf("CloseMessages() 失败: %v", err)
+	}
+
+	edits := mockBot.sentMessages[3:]
+	if len(edits) != 2 {
+		t.Fatalf("应更新 2 条消息，实际为 %d", len(edits))
+	}
+	for i, msg := range edits {
+		edit, ok := msg.(tgbotapi.EditMessageTextConfig)
+		if !ok {
+			t.Fatalf("应为编辑消息，实际为 %T", msg)
+		}
+		if edit.MessageID != i+1 || edit.ChatID != user.TelegramID || edit.Text != "✅ 已完成" {
+			t.Errorf("编辑消息不正确: %+v", edit)
+		}
+		if edit.ReplyMarkup != nil {
+			t.Error("更新后的消息不应保留按钮")
+		}
+	}
+	if len(messageRepo.messages) != 0 {
+		t.Errorf("响应后应不再跟踪消息，剩余 %d 条", len(messageRepo.messages))
+	}
+
+	t.Run("未设置消息仓储时不回复也不更新", func(t *testing.T) {
+		mockBot := &mockBotAPI{}
+		service := NewNotificationService(mockBot)
+		_ = service.SendReminder(ctx, log)
+		_ = service.SendFollowUp(ctx, log, false)
+
+		if reply := mockBot.GetLastSentMessage().(tgbotapi.MessageConfig).ReplyToMessageID; reply != 0 {
+			t.Errorf("ReplyToMessageID = %d, want 0", reply)
+		}
+		if err := service.CloseMessages(ctx, log.ID, "✅ 已完成", 0); err != nil || len(mockBot.sentMessages) != 2 {
+			t.Errorf("CloseMessages() = %v, 发送 %d 条", err, len(mockBot.sentMessages))
+		}
+	})
+}
+
+func TestNotificationService_SendAgenda(t *testing.T) {
+	ctx := context.Background()
+	loc := time.FixedZone("UTC+8", 8*3600)
+	date := time.Date(2026, 5, 2, 0, 0, 0, 0, loc)
+	user := &models.User{ID: 1, TelegramID: 123456789}
+
+	standup := &models.Reminder{ID: 3, Title: "站会 & 同步"}
+	agenda := &Agenda{
+		User: user,
+		Date: date,
+		Upcoming: []AgendaItem{
+			{Reminder: standup, Occurrence: date.Add(9 * time.Hour)},
+			{Reminder: &models.Reminder{ID: 4, Title: "一个名字非常非常长的晚间复盘提醒"}, Occurrence: date.Add(20 * time.Hour)},
+		},
+		Missed: []*models.ReminderLog{
+			{Reminder: *standup, ScheduledTime: date.Add(-15 * time.Hour).UTC(), Status: models.ReminderStatusSkipped},
+		},
+	}
+
+	mockBot := &mockBotAPI{}
+	service := NewNotificationService(mockBot)
+	if err := service.SendAgenda(ctx, agenda); err != nil {
+		t.Fatalf("SendAgenda() 失败: %v", err)
+	}
+
+	msg := mockBot.GetLastSentMessage().(tgbotapi.MessageConfig)
+	for _, want := range []string{"今日日程</b> 05-02", "• 09:00  站会 &amp; 同步", "昨天未完成", "• 09:00  站会 &amp; 同步（已跳过）"} {
+		if !strings.Contains(msg.Text, want) {
+			t.Errorf("日程消息缺少 %q:\n%s", want, msg.Text)
+		}
+	}
+
+	keyboard := msg.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup)
+	if len(keyboard.InlineKeyboard) != 2 {
+		t.Fatalf("每个事项应有一行按钮，实际 %d 行", len(keyboard.InlineKeyboard))
+	}
+	pauseUntil := date.AddDate(0, 0, 1).Unix()
+	wantData := [][]string{
+		{fmt.Sprintf("reminder_skipat_3_%d", date.Add(9*time.Hour).Unix()), fmt.Sprintf("reminder_pauseuntil_3_%d", pauseUntil)},
+		{fmt.Sprintf("reminder_skipat_4_%d", date.Add(20*time.Hour).Unix()), fmt.Sprintf("reminder_pauseuntil_4_%d", pauseUntil)},
+	}
+	for i, row := range keyboard.InlineKeyboard {
+		for j, button := range row {
+			if *button.CallbackData != wantData[i][j] {
+				t.Errorf("按钮[%d][%d] = %s, want %s", i, j, *button.CallbackData, wantData[i][j])
+			}
+		}
+	}
+	if label := keyboard.InlineKeyboard[1][0].Text; label != "⏭️ 20:00 一个名字非常非常长的晚间…" {
+		t.Errorf("过长的标题应截断，实际为 %q", label)
+	}
+}
@@ -8,13 +8,32 @@ import (
 	"strings"
 	"time"
 
+	aiInternal "mmemory/internal/ai"
 	"mmemory/internal/models"
+	"mmemory/pkg/clock"
 )
 
-type parserService struct{}
+type parserService struct {
+	clock clock.Clock
+}
 
 func NewParserService() *parserService {
-	return &parserService{}
+	return &parserService{clock: clock.Real}
+}
+
+// SetClock 设置时钟，"今天"、"明天"、"30分钟后"等相对时间以此为基准
+func (s *parserService) SetClock(c clock.Clock) {
+	if c == nil {
+		c = clock.Real
+	}
+	s.clock = c
+}
+
+func (s *parserService) now() time.Time {
+	if s.clock == nil {
+		return time.Now()
+	}
+	return s.clock.Now()
 }
 
 // ParsePattern 解析模式结构
@@ -57,7 +76,7 @@ func (s *parserService) GetPatterns() []ParsePattern {
 			Regex: regexp.MustCompile(`(工作日|每个工作日)(\d{1,2})[点:](\d{1,2})?提醒我(.+)`),
 			Type:  models.ReminderTypeHabit,
 			ScheduleGen: func(matches []string) string {
-				return "weekly:1,2,3,4,5" // 周一到周五
+				return string(models.SchedulePatternWorkday) // 法定工作日，跳过节假日并包含调休上班日
 			},
 		},
 		// 周末提醒
@@ -81,7 +100,7 @@ func (s *parserService) GetPatterns() []ParsePattern {
 			Regex: regexp.MustCompile(`明天(\d{1,2})[点:](\d{1,2})?提醒我(.+)`),
 			Type:  models.ReminderTypeTask,
 			ScheduleGen: func(matches []string) string {
-				tomorrow := time.Now().AddDate(0, 0, 1)
+				tomorrow := s.now().AddDate(0, 0, 1)
 				return fmt.Sprintf("once:%s", tomorrow.Format("2006-01-02"))
 			},
 		},
@@ -90,7 +109,7 @@ func (s *parserService) GetPatterns() []ParsePattern {
 			Regex: regexp.MustCompile(`明天(上午|下午|晚上)(\d{1,2})[点:]?(\d{1,2})?提醒我(.+)`),
 			Type:  models.ReminderTypeTask,
 			ScheduleGen: func(matches []string) string {
-				tomorrow := time.Now().AddDate(0, 0, 1)
+				tomorrow := s.now().AddDate(0, 0, 1)
 				return fmt.Sprintf("once:%s", tomorrow.Format("2006-01-02"))
 			},
 		},
@@ -99,7 +118,7 @@ func (s *parserService) GetPatterns() []ParsePattern {
 			Regex: regexp.MustCompile(`(今晚|今天晚上)(\d{1,2})[点:]?(\d{1,2})?\s*提醒我(.+)`),
 			Type:  models.ReminderTypeTask,
 			ScheduleGen: func(matches []string) string {
-				today := time.Now()
+				today := s.now()
 				return fmt.Sprintf("once:%s", today.Format("2006-01-02"))
 			},
 		},
@@ -108,7 +127,7 @@ func (s *parserService) GetPatterns() []ParsePattern {
 			Regex: regexp.MustCompile(`今晚(\d{1,2}):(\d{1,2})\s*提醒我(.+)`),
 			Type:  models.ReminderTypeTask,
 			ScheduleGen: func(matches []string) string {
-				today := time.Now()
+				today := s.now()
 				return fmt.Sprintf("once:%s", today.Format("2006-01-02"))
 			},
 		},
@@ -117,7 +136,7 @@ func (s *parserService) GetPatterns() []ParsePattern {
 			Regex: regexp.MustCompile(`今晚(\d{1,2})[点:]?(\d{1,2})?\s*提醒我(.+)`),
 			Type:  models.ReminderTypeTask,
 			ScheduleGen: func(matches []string) string {
-				today := time.Now()
+				today := s.now()
 				return fmt.Sprintf("once:%s", today.Format("2006-01-02"))
 			},
 		},
@@ -134,7 +153,7 @@ func (s *parserService) GetPatterns() []ParsePattern {
 			Regex: regexp.MustCompile(`后天(\d{1,2})[点:](\d{1,2})?提醒我(.+)`),
 			Type:  models.ReminderTypeTask,
 			ScheduleGen: func(matches []string) string {
-				dayAfterTomorrow := time.Now().AddDate(0, 0, 2)
+				dayAfterTomorrow := s.now().AddDate(0, 0, 2)
 				return fmt.Sprintf("once:%s", dayAfterTomorrow.Format("2006-01-02"))
 			},
 		},
@@ -154,7 +173,7 @@ func (s *parserService) GetPatterns() []ParsePattern {
 			Type:  models.ReminderTypeTask,
 			ScheduleGen: func(matches []string) string {
 				minutes, _ := strconv.Atoi(matches[1])
-				targetTime := time.Now().Add(time.Duration(minutes) * time.Minute)
+				targetTime := s.now().Add(time.Duration(minutes) * time.Minute)
 				return fmt.Sprintf("once:%s", targetTime.Format("2006-01-02"))
 			},
 		},
@@ -164,7 +183,7 @@ func (s *parserService) GetPatterns() []ParsePattern {
 			Type:  models.ReminderTypeTask,
 			ScheduleGen: func(matches []string) string {
 				hours, _ := strconv.Atoi(matches[1])
-				targetTime := time.Now().Add(time.Duration(hours) * time.Hour)
+				targetTime := s.now().Add(time.Duration(hours) * time.Hour)
 				return fmt.Sprintf("once:%s", targetTime.Format("2006-01-02"))
 			},
 		},
@@ -178,6 +197,11 @@ func (s *parserService) ParseReminderFromText(ctx context.Context, text string,
 		return nil, fmt.Errorf("文本不能为空")
 	}
 
+	// 提取"持续21天"、"直到12月31日"、"共10次"等结束条件
+	text, end := aiInternal.ExtractEndCondition(text, s.now())
+	// 提取"提前半小时提醒我"等提前通知
+	text, leads := aiInternal.ExtractLeadTimes(text)
+
 	patterns := s.GetPatterns()
 	
 	for _, pattern := range patterns {
@@ -207,8 +231,13 @@ func (s *parserService) ParseReminderFromText(ctx context.Context, text string,
 			Type:            pattern.Type,
 			SchedulePattern: schedulePattern,
 			TargetTime:      fmt.Sprintf("%02d:%02d:00", hour, minute),
+			LeadTimes:       models.FormatLeadTimes(leads),
 			IsActive:        true,
 		}
+		if pattern.Type == models.ReminderTypeHabit {
+			reminder.UntilDate = end.UntilDate
+			reminder.MaxOccurrences = end.MaxOccurrences
+		}
 
 		return reminder, nil
 	}
@@ -333,7 +362,7 @@ func (s *parserService) chineseWeekdayToInt(weekday string) int {
 
 // getNextWeekdayDate 获取下周指定星期几的日期
 func (s *parserService) getNextWeekdayDate(targetWeekday int) time.Time {
-	now := time.Now()
+	now := s.now()
 	currentWeekday := int(now.Weekday())
 	if currentWeekday == 0 {
 		currentWeekday = 7 // 将周日从0改为7
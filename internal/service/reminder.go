@@ -111,6 +111,15 @@ func (s *reminderService) DeleteReminder(ctx context.Context, id uint) error {
 }
 
 func (s *reminderService) PauseReminder(ctx context.Context, id uint, duration time.Duration, reason string) error {
+	return s.pauseReminder(ctx, id, duration, reason, false)
+}
+
+func (s *reminderService) PauseReminderQuietly(ctx context.Context, id uint, duration time.Duration, reason string) error {
+	return s.pauseReminder(ctx, id, duration, reason, true)
+}
+
+// pauseReminder 暂停提醒，quiet 为 true 时到期后不发送恢复通知
+func (s *reminderService) pauseReminder(ctx context.Context, id uint, duration time.Duration, reason string, quiet bool) error {
 	if id == 0 {
 		return fmt.Errorf("提醒ID不能为空")
 	}
@@ -130,6 +139,7 @@ func (s *reminderService) PauseReminder(ctx context.Context, id uint, duration t
 	pauseUntil := time.Now().Add(duration)
 	reminder.PausedUntil = &pauseUntil
 	reminder.PauseReason = reason
+	reminder.QuietResume = quiet
 
 	if err := s.reminderRepo.Update(ctx, reminder); err != nil {
 		return err
@@ -139,6 +149,13 @@ func (s *reminderService) PauseReminder(ctx context.Context, id uint, duration t
 		if err := s.scheduler.RemoveReminder(id); err != nil {
 			fmt.Printf("移除暂停提醒调度失败: %v", err)
 		}
+
+		// 暂停期间不触发，到期后由调度器自动恢复
+		if reminder.IsActive {
+			if err := s.scheduler.AddReminder(reminder); err != nil {
+				fmt.Printf("安排暂停提醒恢复失败: %v", err)
+			}
+		}
 	}
 
 	return nil
@@ -159,6 +176,7 @@ func (s *reminderService) ResumeReminder(ctx context.Context, id uint) error {
 
 	reminder.PausedUntil = nil
 	reminder.PauseReason = ""
+	reminder.QuietResume = false
 
 	if !reminder.IsActive {
 		reminder.IsActive = true
@@ -179,11 +197,12 @@ func (s *reminderService) ResumeReminder(ctx context.Context, id uint) error {
 
 // EditReminderParams 编辑提醒的参数
 type EditReminderParams struct {
-	ReminderID      uint
-	NewTime         *string // 新的时间 (HH:MM:SS 格式)，可选
-	NewPattern      *string // 新的重复模式，可选
-	NewTitle        *string // 新的标题，可选
-	NewDescription  *string // 新的描述，可选
+	ReminderID     uint
+	NewTime        *string  // 新的时间 (HH:MM:SS 格式)，可选
+	NewTimes       []string // 新的多个触发时间 (HH:MM:SS 格式)，优先于 NewTime，可选
+	NewPattern     *string  // 新的重复模式，可选
+	NewTitle       *string  // 新的标题，可选
+	NewDescription *string  // 新的描述，可选
 }
 
 // EditReminder 编辑提醒（支持部分更新）
@@ -205,8 +224,12 @@ func (s *reminderService) EditReminder(ctx context.Context, params EditReminderP
 	modified := false
 
 	// 2. 应用修改
-	if params.NewTime != nil && *params.NewTime != "" {
-		reminder.TargetTime = *params.NewTime
+	// 修改时间时以新时间替换全部触发时间
+	if len(params.NewTimes) > 0 {
+		reminder.SetTimes(params.NewTimes)
+		modified = true
+	} else if params.NewTime != nil && *params.NewTime != "" {
+		reminder.SetTimes([]string{*params.NewTime})
 		modified = true
 	}
 
@@ -7,11 +7,15 @@ import (
 
 	"mmemory/internal/models"
 	"mmemory/internal/repository/interfaces"
+	"mmemory/pkg/clock"
 )
 
 type reminderLogService struct {
 	reminderLogRepo interfaces.ReminderLogRepository
 	reminderRepo    interfaces.ReminderRepository
+	scheduler       SchedulerService
+	clock           clock.Clock
+	followUpPolicy  models.FollowUpPolicy // 提醒和用户均未设置时使用的关怀策略
 }
 
 func NewReminderLogService(
@@ -21,25 +25,55 @@ func NewReminderLogService(
 	return &reminderLogService{
 		reminderLogRepo: reminderLogRepo,
 		reminderRepo:    reminderRepo,
+		clock:           clock.Real,
+		followUpPolicy:  models.DefaultFollowUpPolicy,
 	}
 }
 
+// SetScheduler 设置调度器，用于投递延期提醒
+func (s *reminderLogService) SetScheduler(scheduler SchedulerService) {
+	s.scheduler = scheduler
+}
+
+// SetClock 设置时钟，供模拟运行时与调度器共用同一时间
+func (s *reminderLogService) SetClock(c clock.Clock) {
+	if c == nil {
+		c = clock.Real
+	}
+	s.clock = c
+}
+
+// SetDefaultFollowUpPolicy 设置默认关怀策略，与调度器保持一致
+func (s *reminderLogService) SetDefaultFollowUpPolicy(policy models.FollowUpPolicy) {
+	s.followUpPolicy = policy
+}
+
+func (s *reminderLogService) now() time.Time {
+	if s.clock == nil {
+		return time.Now()
+	}
+	return s.clock.Now()
+}
+
 func (s *reminderLogService) GetByID(ctx context.Context, id uint) (*models.ReminderLog, error) {
 	return s.reminderLogRepo.GetByID(ctx, id)
 }
 
-func (s *reminderLogService) MarkAsCompleted(ctx context.Context, id uint, response string) error {
+func (s *reminderLogService) MarkAsCompleted(ctx context.Context, id uint, response string) (bool, error) {
 	log, err := s.reminderLogRepo.GetByID(ctx, id)
 	if err != nil {
-		return fmt.Errorf("获取提醒记录失败: %w", err)
+		return false, fmt.Errorf("获取提醒记录失败: %w", err)
 	}
 	
 	if log == nil {
-		return fmt.Errorf("提醒记录不存在")
+		return false, fmt.Errorf("提醒记录不存在")
 	}
 	
-	log.MarkAsCompleted(response)
-	return s.reminderLogRepo.Update(ctx, log)
+	completed, err := s.reminderLogRepo.Complete(ctx, id, response, s.now())
+	if err != nil {
+		return false, fmt.Errorf("标记提醒完成失败: %w", err)
+	}
+	return completed, nil
 }
 
 func (s *reminderLogService) MarkAsSkipped(ctx context.Context, id uint, response string) error {
@@ -70,7 +104,7 @@ func (s *reminderLogService) CreateDelayReminder(ctx context.Context, originalLo
 	// 标记原记录为已延期
 	originalLog.Status = models.ReminderStatusSkipped
 	originalLog.UserResponse = fmt.Sprintf("延期%d小时", hours)
-	now := time.Now()
+	now := s.now()
 	originalLog.ResponseTime = &now
 	
 	if err := s.reminderLogRepo.Update(ctx, originalLog); err != nil {
@@ -84,7 +118,18 @@ func (s *reminderLogService) CreateDelayReminder(ctx context.Context, originalLo
 		Status:        models.ReminderStatusPending,
 	}
 	
-	return s.reminderLogRepo.Create(ctx, delayLog)
+	if err := s.reminderLogRepo.Create(ctx, delayLog); err != nil {
+		return fmt.Errorf("创建延期记录失败: %w", err)
+	}
+	
+	// 交给调度器在到期时投递；即使失败，记录也会在调度器重启时恢复
+	if s.scheduler != nil {
+		if err := s.scheduler.ScheduleDelivery(delayLog); err != nil {
+			return fmt.Errorf("安排延期提醒投递失败: %w", err)
+		}
+	}
+	
+	return nil
 }
 
 func (s *reminderLogService) GetOverdueReminders(ctx context.Context) ([]*models.ReminderLog, error) {
@@ -95,13 +140,15 @@ func (s *reminderLogService) GetOverdueReminders(ctx context.Context) ([]*models
 	}
 	
 	var overdueLogs []*models.ReminderLog
-	now := time.Now()
+	now := s.now()
 	
 	for _, log := range allLogs {
-		// 检查是否已发送且超时（发送后1小时未回复）
-		if log.Status == models.ReminderStatusSent && 
-		   log.SentTime != nil && 
-		   now.Sub(*log.SentTime) > time.Hour {
+		// 检查是否已发送且按关怀策略到了下一次关怀的时间，关怀次数用完的不再返回
+		policy := log.Reminder.ResolveFollowUpPolicy(s.followUpPolicy)
+		if log.FollowUpCount >= policy.Max {
+			continue
+		}
+		if dueAt, ok := policy.NextAt(log); ok && !dueAt.After(now) {
 			overdueLogs = append(overdueLogs, log)
 		}
 	}
@@ -120,7 +167,9 @@ func (s *reminderLogService) UpdateFollowUpCount(ctx context.Context, id uint) e
 		return fmt.Errorf("提醒记录不存在")
 	}
 	
+	now := s.now()
 	log.FollowUpCount++
+	log.LastFollowUpAt = &now
 	return s.reminderLogRepo.Update(ctx, log)
 }
 
@@ -144,7 +193,7 @@ func (s *reminderLogService) GetUserStatistics(ctx context.Context, userID uint)
 	}
 	
 	// 获取时间范围
-	now := time.Now()
+	now := s.now()
 	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	weekStart := todayStart.AddDate(0, 0, -int(now.Weekday())+1) // 本周一
 	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
@@ -194,6 +243,8 @@ func (s *reminderLogService) GetUserStatistics(ctx context.Context, userID uint)
 		stats.CompletionRate = (stats.CompletedMonth * 100) / totalThisMonth
 	}
 	
+	s.countRoutines(ctx, reminders, weekStart, monthStart, stats)
+	
 	// TODO: 计算连续天数 (需要更复杂的逻辑)
 	stats.CurrentStreak = 0
 	stats.LongestStreak = 0
@@ -201,6 +252,60 @@ func (s *reminderLogService) GetUserStatistics(ctx context.Context, userID uint)
 	return stats, nil
 }
 
+// countRoutines 统计例程完成情况：第一步发送即开始一次例程，最后一步完成即完成一次例程
+func (s *reminderLogService) countRoutines(ctx context.Context, reminders []*models.Reminder, weekStart, monthStart time.Time, stats *UserStatistics) {
+	first := make(map[uint]*models.Reminder)
+	last := make(map[uint]*models.Reminder)
+	for _, reminder := range reminders {
+		if !reminder.IsRoutineStep() || !reminder.IsActive {
+			continue
+		}
+		routineID := *reminder.RoutineID
+		if current, ok := first[routineID]; !ok || reminder.RoutineStep < current.RoutineStep {
+			first[routineID] = reminder
+		}
+		if current, ok := last[routineID]; !ok || reminder.RoutineStep > current.RoutineStep {
+			last[routineID] = reminder
+		}
+	}
+	
+	startedMonth := 0
+	for routineID, reminder := range first {
+		logs, err := s.reminderLogRepo.GetByReminderID(ctx, reminder.ID, 0, 0)
+		if err != nil {
+			continue
+		}
+		for _, log := range logs {
+			if log.SentTime != nil && log.SentTime.After(monthStart) {
+				startedMonth++
+			}
+		}
+		
+		logs, err = s.reminderLogRepo.GetByReminderID(ctx, last[routineID].ID, 0, 0)
+		if err != nil {
+			continue
+		}
+		for _, log := range logs {
+			if log.Status != models.ReminderStatusCompleted || log.ResponseTime == nil {
+				continue
+			}
+			if log.ResponseTime.After(weekStart) {
+				stats.RoutinesCompletedWeek++
+			}
+			if log.ResponseTime.After(monthStart) {
+				stats.RoutinesCompletedMonth++
+			}
+		}
+	}
+	
+	if startedMonth > 0 {
+		stats.RoutineCompletionRate = (stats.RoutinesCompletedMonth * 100) / startedMonth
+		if stats.RoutineCompletionRate > 100 {
+			stats.RoutineCompletionRate = 100
+		}
+	}
+}
+
 // 辅助函数：统计本月跳过的数量
 func countSkippedThisMonth(reminders []*models.Reminder, repo interfaces.ReminderLogRepository, ctx context.Context, monthStart time.Time) int {
 	count := 0
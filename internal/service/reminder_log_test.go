@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"mmemory/internal/models"
+	"mmemory/pkg/clock"
 )
 
 func TestReminderLogService_MarkAsCompleted(t *testing.T) {
@@ -48,7 +49,7 @@ func TestReminderLogService_MarkAsCompleted(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := service.MarkAsCompleted(ctx, tt.logID, tt.response)
+			_, err := service.MarkAsCompleted(ctx, tt.logID, tt.response)
 			
 			if (err != nil) != tt.wantErr {
 				t.Errorf("MarkAsCompleted() error = %v, wantErr %v", err, tt.wantErr)
@@ -79,6 +80,35 @@ func TestReminderLogService_MarkAsCompleted(t *testing.T) {
 	}
 }
 
+// TestReminderLogService_MarkAsCompletedOnce 测试重复标记完成只有第一次生效，例程不会因重复点击多次推进
+func TestReminderLogService_MarkAsCompletedOnce(t *testing.T) {
+	mockLogRepo := newMockReminderLogRepository()
+	service := NewReminderLogService(mockLogRepo, newMockReminderRepository())
+	ctx := context.Background()
+
+	// 回复时间使用服务的时钟，与模拟运行时的调度时间一致
+	simulated := clock.NewSimulated(time.Date(2026, 5, 1, 9, 5, 0, 0, time.UTC))
+	service.(*reminderLogService).SetClock(simulated)
+
+	log := &models.ReminderLog{ReminderID: 1, ScheduledTime: time.Now(), Status: models.ReminderStatusSent}
+	mockLogRepo.Create(ctx, log)
+
+	completed, err := service.MarkAsCompleted(ctx, log.ID, "第一次点击")
+	if err != nil || !completed {
+		t.Fatalf("第一次标记应生效: completed=%v err=%v", completed, err)
+	}
+	completed, err = service.MarkAsCompleted(ctx, log.ID, "第二次点击")
+	if err != nil || completed {
+		t.Fatalf("重复标记不应生效: completed=%v err=%v", completed, err)
+	}
+	if log.UserResponse != "第一次点击" {
+		t.Errorf("重复标记不应覆盖回复，实际 %q", log.UserResponse)
+	}
+	if log.ResponseTime == nil || !log.ResponseTime.Equal(simulated.Now()) {
+		t.Errorf("回复时间应取服务时钟 %s，实际 %v", simulated.Now(), log.ResponseTime)
+	}
+}
+
 func TestReminderLogService_CreateDelayReminder(t *testing.T) {
 	mockLogRepo := newMockReminderLogRepository()
 	mockReminderRepo := newMockReminderRepository()
@@ -158,6 +188,37 @@ func TestReminderLogService_CreateDelayReminder(t *testing.T) {
 	}
 }
 
+func TestReminderLogService_CreateDelayReminder_SchedulesDelivery(t *testing.T) {
+	mockLogRepo := newMockReminderLogRepository()
+	scheduler := &mockScheduler{}
+
+	service := NewReminderLogService(mockLogRepo, newMockReminderRepository())
+	service.(*reminderLogService).SetScheduler(scheduler)
+
+	ctx := context.Background()
+	originalLog := &models.ReminderLog{
+		ReminderID:    1,
+		ScheduledTime: time.Now(),
+		Status:        models.ReminderStatusSent,
+	}
+	if err := mockLogRepo.Create(ctx, originalLog); err != nil {
+		t.Fatalf("创建原始日志失败: %v", err)
+	}
+
+	if err := service.CreateDelayReminder(ctx, originalLog.ID, time.Now().Add(time.Hour), 1); err != nil {
+		t.Fatalf("CreateDelayReminder() 失败: %v", err)
+	}
+
+	if len(scheduler.delivered) != 1 {
+		t.Fatalf("期待延期记录交给调度器投递，实际 %d 条", len(scheduler.delivered))
+	}
+
+	delayLog, _ := mockLogRepo.GetByID(ctx, scheduler.delivered[0])
+	if delayLog == nil || delayLog.Status != models.ReminderStatusPending || delayLog.ID == originalLog.ID {
+		t.Errorf("投递的记录不是新的待发送延期记录: %+v", delayLog)
+	}
+}
+
 func TestReminderLogService_GetOverdueReminders(t *testing.T) {
 	mockLogRepo := newMockReminderLogRepository()
 	mockReminderRepo := newMockReminderRepository()
@@ -13,6 +13,7 @@ import (
 type mockReminderRepository struct {
 	reminders map[uint]*models.Reminder
 	idCounter uint
+	updates   int // 整体更新（会刷新 updated_at）的次数
 	mu        sync.Mutex
 }
 
@@ -71,12 +72,23 @@ func (m *mockReminderRepository) Update(ctx context.Context, reminder *models.Re
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	m.updates++
 	if existing := m.reminders[reminder.ID]; existing != nil {
 		m.reminders[reminder.ID] = reminder
 	}
 	return nil
 }
 
+func (m *mockReminderRepository) UpdateOccurrenceCount(ctx context.Context, id uint, count int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if existing := m.reminders[id]; existing != nil {
+		existing.OccurrenceCount = count
+	}
+	return nil
+}
+
 func (m *mockReminderRepository) Delete(ctx context.Context, id uint) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -100,8 +112,9 @@ func (m *mockReminderRepository) CountByStatus(ctx context.Context, status model
 }
 
 type mockScheduler struct {
-	added   []uint
-	removed []uint
+	added     []uint
+	removed   []uint
+	delivered []uint
 }
 
 func (m *mockScheduler) Start() error {
@@ -126,6 +139,25 @@ func (m *mockScheduler) RefreshSchedules() error {
 	return nil
 }
 
+func (m *mockScheduler) NextOccurrences(reminder *models.Reminder, after time.Time, n int) ([]time.Time, error) {
+	return nil, nil
+}
+
+func (m *mockScheduler) ScheduleAgenda(user *models.User) {}
+
+func (m *mockScheduler) BuildAgenda(ctx context.Context, user *models.User, now time.Time) (*Agenda, error) {
+	return &Agenda{User: user}, nil
+}
+
+func (m *mockScheduler) SkipOccurrence(ctx context.Context, reminderID uint, occurrence time.Time) error {
+	return nil
+}
+
+func (m *mockScheduler) ScheduleDelivery(log *models.ReminderLog) error {
+	m.delivered = append(m.delivered, log.ID)
+	return nil
+}
+
 func TestReminderService_CreateReminder(t *testing.T) {
 	mockRepo := newMockReminderRepository()
 	reminderService := NewReminderService(mockRepo)
@@ -329,6 +361,9 @@ func TestReminderService_PauseReminder(t *testing.T) {
 	if len(scheduler.removed) == 0 || scheduler.removed[0] != reminder.ID {
 		t.Fatalf("PauseReminder 应移除调度，got %v", scheduler.removed)
 	}
+	if len(scheduler.added) != 2 || scheduler.added[1] != reminder.ID {
+		t.Fatalf("PauseReminder 应重新加入调度以便到期后自动恢复，got %v", scheduler.added)
+	}
 }
 
 func TestReminderService_ResumeReminder(t *testing.T) {
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"mmemory/internal/models"
+	"mmemory/internal/repository/interfaces"
+	"mmemory/pkg/clock"
+	"mmemory/pkg/logger"
+)
+
+const maxRoutineSteps = 10
+
+type routineService struct {
+	routineRepo     interfaces.RoutineRepository
+	reminderRepo    interfaces.ReminderRepository
+	reminderLogRepo interfaces.ReminderLogRepository
+	scheduler       SchedulerService
+	clock           clock.Clock
+}
+
+func NewRoutineService(
+	routineRepo interfaces.RoutineRepository,
+	reminderRepo interfaces.ReminderRepository,
+	reminderLogRepo interfaces.ReminderLogRepository,
+) RoutineService {
+	return &routineService{
+		routineRepo:     routineRepo,
+		reminderRepo:    reminderRepo,
+		reminderLogRepo: reminderLogRepo,
+		clock:           clock.Real,
+	}
+}
+
+// SetScheduler 设置调度器，用于投递例程的下一步
+func (s *routineService) SetScheduler(scheduler SchedulerService) {
+	s.scheduler = scheduler
+}
+
+// SetClock 设置时钟，供模拟运行时与调度器共用同一时间
+func (s *routineService) SetClock(c clock.Clock) {
+	if c == nil {
+		c = clock.Real
+	}
+	s.clock = c
+}
+
+func (s *routineService) now() time.Time {
+	if s.clock == nil {
+		return time.Now()
+	}
+	return s.clock.Now()
+}
+
+func (s *routineService) CreateRoutine(ctx context.Context, userID uint, name string, reminderIDs []uint, delay time.Duration) (*models.Routine, error) {
+	name = strings.TrimSpace(name)
+	if userID == 0 {
+		return nil, fmt.Errorf("用户ID不能为空")
+	}
+	if name == "" {
+		return nil, fmt.Errorf("例程名称不能为空")
+	}
+	if len(reminderIDs) < 2 || len(reminderIDs) > maxRoutineSteps {
+		return nil, fmt.Errorf("例程需要2到%d个步骤", maxRoutineSteps)
+	}
+	if delay < 0 {
+		return nil, fmt.Errorf("步骤间隔不能为负数")
+	}
+
+	seen := make(map[uint]bool, len(reminderIDs))
+	steps := make([]*models.Reminder, 0, len(reminderIDs))
+	for _, id := range reminderIDs {
+		if seen[id] {
+			return nil, fmt.Errorf("提醒 %d 重复出现", id)
+		}
+		seen[id] = true
+
+		reminder, err := s.reminderRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("获取提醒失败: %w", err)
+		}
+		if reminder == nil || reminder.UserID != userID {
+			return nil, fmt.Errorf("未找到ID为 %d 的提醒", id)
+		}
+		if reminder.IsRoutineStep() {
+			return nil, fmt.Errorf("提醒 %d 已属于其他例程", id)
+		}
+		steps = append(steps, reminder)
+	}
+
+	routine := &models.Routine{UserID: userID, Name: name}
+	if err := s.routineRepo.Create(ctx, routine); err != nil {
+		return nil, fmt.Errorf("创建例程失败: %w", err)
+	}
+
+	// 第一步保留自身计划，之后的步骤改由上一步完成触发
+	for i, reminder := range steps {
+		routineID := routine.ID
+		reminder.RoutineID = &routineID
+		reminder.RoutineStep = i + 1
+		if i > 0 {
+			reminder.RoutineDelay = int(delay / time.Minute)
+		}
+		if err := s.reminderRepo.Update(ctx, reminder); err != nil {
+			return nil, fmt.Errorf("更新例程步骤失败: %w", err)
+		}
+		s.reschedule(reminder)
+		routine.Steps = append(routine.Steps, *reminder)
+	}
+
+	logger.Infof("🔗 例程已创建: ID=%d, 名称=%s, 步骤=%d", routine.ID, routine.Name, len(steps))
+	return routine, nil
+}
+
+func (s *routineService) GetUserRoutines(ctx context.Context, userID uint) ([]*models.Routine, error) {
+	return s.routineRepo.GetByUserID(ctx, userID)
+}
+
+func (s *routineService) DeleteRoutine(ctx context.Context, userID, id uint) error {
+	routine, err := s.routineRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("获取例程失败: %w", err)
+	}
+	if routine == nil || routine.UserID != userID {
+		return fmt.Errorf("例程不存在")
+	}
+
+	if err := s.routineRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("删除例程失败: %w", err)
+	}
+
+	// 步骤恢复为独立提醒，按各自的计划调度
+	for _, step := range routine.OrderedSteps() {
+		step.RoutineID = nil
+		step.RoutineStep = 0
+		step.RoutineDelay = 0
+		s.reschedule(step)
+	}
+	return nil
+}
+
+// AdvanceRoutine 例程中的一步完成后安排下一步，最后一步完成即完成一次例程
+func (s *routineService) AdvanceRoutine(ctx context.Context, log *models.ReminderLog) (*models.Reminder, error) {
+	if log == nil || log.Reminder.RoutineID == nil {
+		return nil, nil
+	}
+
+	routine, err := s.routineRepo.GetByID(ctx, *log.Reminder.RoutineID)
+	if err != nil {
+		return nil, fmt.Errorf("获取例程失败: %w", err)
+	}
+	if routine == nil {
+		return nil, nil
+	}
+
+	next := routine.NextStep(log.Reminder.RoutineStep)
+	if next == nil {
+		logger.Infof("🏁 例程已完成: ID=%d, 名称=%s", routine.ID, routine.Name)
+		return nil, nil
+	}
+
+	nextLog := &models.ReminderLog{
+		ReminderID:    next.ID,
+		ScheduledTime: s.now().Add(time.Duration(next.RoutineDelay) * time.Minute),
+		Status:        models.ReminderStatusPending,
+	}
+	if err := s.reminderLogRepo.Create(ctx, nextLog); err != nil {
+		return nil, fmt.Errorf("创建例程下一步记录失败: %w", err)
+	}
+
+	// 交给调度器在到期时投递；即使失败，记录也会在调度器重启时恢复
+	if s.scheduler != nil {
+		if err := s.scheduler.ScheduleDelivery(nextLog); err != nil {
+			return nil, fmt.Errorf("安排例程下一步投递失败: %w", err)
+		}
+	}
+
+	logger.Infof("➡️ 例程进入下一步: 例程=%d, 步骤=%d, 提醒=%d", routine.ID, next.RoutineStep, next.ID)
+	return next, nil
+}
+
+// reschedule 按提醒当前的例程设置重新调度，由上一步触发的步骤不再按自身计划调度
+func (s *routineService) reschedule(reminder *models.Reminder) {
+	if s.scheduler == nil || !reminder.IsActive {
+		return
+	}
+	if err := s.scheduler.AddReminder(reminder); err != nil {
+		logger.Warnf("重新调度例程步骤失败 (ID: %d): %v", reminder.ID, err)
+	}
+}
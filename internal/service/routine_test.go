@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mmemory/internal/models"
+	"mmemory/pkg/clock"
+)
+
+// Mock RoutineRepository for testing，步骤与数据库实现一样从提醒表按 routine_id 关联
+type mockRoutineRepository struct {
+	routines  map[uint]*models.Routine
+	reminders *mockReminderRepository
+	idCounter uint
+}
+
+func newMockRoutineRepository(reminders *mockReminderRepository) *mockRoutineRepository {
+	return &mockRoutineRepository{
+		routines:  make(map[uint]*models.Routine),
+		reminders: reminders,
+		idCounter: 1,
+	}
+}
+
+func (m *mockRoutineRepository) Create(ctx context.Context, routine *models.Routine) error {
+	routine.ID = m.idCounter
+	m.routines[m.idCounter] = &models.Routine{ID: routine.ID, UserID: routine.UserID, Name: routine.Name}
+	m.idCounter++
+	return nil
+}
+
+func (m *mockRoutineRepository) GetByID(ctx context.Context, id uint) (*models.Routine, error) {
+	stored := m.routines[id]
+	if stored == nil {
+		return nil, nil
+	}
+
+	routine := *stored
+	m.reminders.mu.Lock()
+	for _, reminder := range m.reminders.reminders {
+		if reminder.RoutineID != nil && *reminder.RoutineID == id {
+			routine.Steps = append(routine.Steps, *reminder)
+		}
+	}
+	m.reminders.mu.Unlock()
+	return &routine, nil
+}
+
+func (m *mockRoutineRepository) GetByUserID(ctx context.Context, userID uint) ([]*models.Routine, error) {
+	var result []*models.Routine
+	for id, routine := range m.routines {
+		if routine.UserID == userID {
+			loaded, _ := m.GetByID(ctx, id)
+			result = append(result, loaded)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockRoutineRepository) Delete(ctx context.Context, id uint) error {
+	m.reminders.mu.Lock()
+	for _, reminder := range m.reminders.reminders {
+		if reminder.RoutineID != nil && *reminder.RoutineID == id {
+			reminder.RoutineID = nil
+			reminder.RoutineStep = 0
+			reminder.RoutineDelay = 0
+		}
+	}
+	m.reminders.mu.Unlock()
+	delete(m.routines, id)
+	return nil
+}
+
+func TestRoutineService_CreateAndAdvance(t *testing.T) {
+	reminderRepo := newMockReminderRepository()
+	logRepo := newMockReminderLogRepository()
+	routineRepo := newMockRoutineRepository(reminderRepo)
+	ctx := context.Background()
+
+	var steps []*models.Reminder
+	for _, title := range []string{"喝水", "拉伸", "写日记"} {
+		reminder := &models.Reminder{
+			UserID:          1,
+			Title:           title,
+			Type:            models.ReminderTypeHabit,
+			SchedulePattern: "daily",
+			TargetTime:      "07:00:00",
+			IsActive:        true,
+		}
+		reminderRepo.Create(ctx, reminder)
+		steps = append(steps, reminder)
+	}
+
+	now := time.Date(2026, 5, 1, 7, 5, 0, 0, time.UTC)
+	scheduler := &mockScheduler{}
+	service := NewRoutineService(routineRepo, reminderRepo, logRepo).(*routineService)
+	service.SetScheduler(scheduler)
+	service.SetClock(clock.NewSimulated(now))
+
+	if _, err := service.CreateRoutine(ctx, 2, "晨间", []uint{steps[0].ID, steps[1].ID}, 0); err == nil {
+		t.Fatal("不能使用其他用户的提醒创建例程")
+	}
+
+	routine, err := service.CreateRoutine(ctx, 1, "晨间", []uint{steps[0].ID, steps[1].ID, steps[2].ID}, 10*time.Minute)
+	if err != nil {
+		t.Fatalf("CreateRoutine() 失败: %v", err)
+	}
+	if steps[0].IsChainedStep() || !steps[1].IsChainedStep() || steps[2].RoutineStep != 3 {
+		t.Fatalf("步骤顺序设置错误: %d/%d/%d", steps[0].RoutineStep, steps[1].RoutineStep, steps[2].RoutineStep)
+	}
+	if steps[0].RoutineDelay != 0 || steps[1].RoutineDelay != 10 {
+		t.Errorf("步骤间隔 = %d/%d, want 0/10", steps[0].RoutineDelay, steps[1].RoutineDelay)
+	}
+	if len(scheduler.added) != 3 {
+		t.Errorf("创建例程后应重新调度全部步骤，实际 %v", scheduler.added)
+	}
+
+	if _, err := service.CreateRoutine(ctx, 1, "重复", []uint{steps[0].ID, steps[1].ID}, 0); err == nil {
+		t.Error("已属于例程的提醒不能加入其他例程")
+	}
+
+	// 完成第一步后10分钟提醒第二步
+	next, err := service.AdvanceRoutine(ctx, &models.ReminderLog{ReminderID: steps[0].ID, Reminder: *steps[0]})
+	if err != nil {
+		t.Fatalf("AdvanceRoutine() 失败: %v", err)
+	}
+	if next == nil || next.ID != steps[1].ID {
+		t.Fatalf("下一步 = %v, want %d", next, steps[1].ID)
+	}
+	if len(scheduler.delivered) != 1 {
+		t.Fatalf("应安排投递下一步，实际 %v", scheduler.delivered)
+	}
+	nextLog, _ := logRepo.GetByID(ctx, scheduler.delivered[0])
+	if nextLog.ReminderID != steps[1].ID || !nextLog.ScheduledTime.Equal(now.Add(10*time.Minute)) || nextLog.Status != models.ReminderStatusPending {
+		t.Errorf("下一步记录 = %+v", nextLog)
+	}
+
+	// 停用的步骤被跳过，最后一步完成后例程结束
+	steps[1].IsActive = false
+	next, _ = service.AdvanceRoutine(ctx, &models.ReminderLog{ReminderID: steps[0].ID, Reminder: *steps[0]})
+	if next == nil || next.ID != steps[2].ID {
+		t.Errorf("应跳过停用的步骤，下一步 = %v", next)
+	}
+	next, _ = service.AdvanceRoutine(ctx, &models.ReminderLog{ReminderID: steps[2].ID, Reminder: *steps[2]})
+	if next != nil {
+		t.Errorf("最后一步完成后不应再有下一步，实际 %d", next.ID)
+	}
+
+	if err := service.DeleteRoutine(ctx, 1, routine.ID); err != nil {
+		t.Fatalf("DeleteRoutine() 失败: %v", err)
+	}
+	if stored, _ := reminderRepo.GetByID(ctx, steps[2].ID); stored.IsRoutineStep() {
+		t.Error("删除例程后提醒应恢复为独立提醒")
+	}
+}
+
+func TestReminderLogService_RoutineStatistics(t *testing.T) {
+	reminderRepo := newMockReminderRepository()
+	logRepo := newMockReminderLogRepository()
+	ctx := context.Background()
+
+	routineID := uint(1)
+	first := &models.Reminder{UserID: 1, Title: "喝水", SchedulePattern: "daily", TargetTime: "07:00:00", IsActive: true, RoutineID: &routineID, RoutineStep: 1}
+	last := &models.Reminder{UserID: 1, Title: "写日记", SchedulePattern: "daily", TargetTime: "07:00:00", IsActive: true, RoutineID: &routineID, RoutineStep: 2}
+	reminderRepo.Create(ctx, first)
+	reminderRepo.Create(ctx, last)
+
+	now := time.Date(2026, 5, 14, 12, 0, 0, 0, time.UTC)
+	for day := 11; day <= 14; day++ {
+		sent := time.Date(2026, 5, day, 7, 0, 0, 0, time.UTC)
+		logRepo.Create(ctx, &models.ReminderLog{ReminderID: first.ID, ScheduledTime: sent, SentTime: &sent, Status: models.ReminderStatusCompleted, ResponseTime: &sent})
+	}
+	// 只有两天走完了整个例程
+	for _, day := range []int{12, 14} {
+		done := time.Date(2026, 5, day, 7, 30, 0, 0, time.UTC)
+		logRepo.Create(ctx, &models.ReminderLog{ReminderID: last.ID, ScheduledTime: done, SentTime: &done, Status: models.ReminderStatusCompleted, ResponseTime: &done})
+	}
+
+	service := NewReminderLogService(logRepo, reminderRepo).(*reminderLogService)
+	service.SetClock(clock.NewSimulated(now))
+
+	stats, err := service.GetUserStatistics(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetUserStatistics() 失败: %v", err)
+	}
+	if stats.RoutinesCompletedWeek != 2 || stats.RoutinesCompletedMonth != 2 {
+		t.Errorf("例程完成次数 = 本周 %d / 本月 %d, want 2 / 2", stats.RoutinesCompletedWeek, stats.RoutinesCompletedMonth)
+	}
+	if stats.RoutineCompletionRate != 50 {
+		t.Errorf("例程完成率 = %d%%, want 50%%", stats.RoutineCompletionRate)
+	}
+}
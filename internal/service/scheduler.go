@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -12,17 +13,61 @@ import (
 
 	"mmemory/internal/models"
 	"mmemory/internal/repository/interfaces"
+	"mmemory/pkg/clock"
+	"mmemory/pkg/holiday"
 	"mmemory/pkg/logger"
+	"mmemory/pkg/rrule"
+)
+
+// MissedPolicy 停机期间错过提醒的补偿策略
+type MissedPolicy string
+
+const (
+	MissedPolicyLate   MissedPolicy = "late"   // 补发最近一次错过的提醒，更早的记录为已过期
+	MissedPolicyDigest MissedPolicy = "digest" // 按用户汇总成一条消息发送
+	MissedPolicyMark   MissedPolicy = "mark"   // 只记录为已过期，不发送消息
+)
+
+const (
+	defaultMissedLookback = 24 * time.Hour
+	maxMissedOccurrences  = 100         // 单个提醒最多追溯的错过次数
+	occurrenceTolerance   = time.Minute // 触发延迟在此范围内时仍归到原计划时刻
+	defaultReconcileEvery = 5 * time.Minute
+	maxDeliveryRetries    = 5                // 发送失败后最多重试次数，之后取消该次提醒
+	deliveryRetryBase     = time.Minute      // 第一次重试前的等待时间，之后每次翻倍
+	deliveryRetryMax      = 30 * time.Minute // 重试等待时间上限
 )
 
 type schedulerService struct {
 	cron                *cron.Cron
-	location            *time.Location
+	location            *time.Location // 默认时区，提醒和用户均未设置时区时使用
 	reminderRepo        interfaces.ReminderRepository
 	reminderLogRepo     interfaces.ReminderLogRepository
 	notificationService NotificationService
-	jobs                map[uint]cron.EntryID
-	onceTimers          map[uint]*time.Timer
+	jobs                map[uint][]cron.EntryID // 每个触发时间一个 cron 任务
+	onceTimers          map[uint][]clock.Timer
+	clockJobs           map[uint][]*clockJob           // 非系统时钟下代替 cron 的周期任务
+	versions            map[uint]scheduleVersion       // 已加入调度的提醒版本，增量同步时与数据库比较
+	deliveryTimers      map[uint]clock.Timer           // 待投递的提醒记录，key 为 ReminderLog.ID
+	deliveryAttempts    map[uint]int                   // 发送失败的待发送记录已重试次数，key 为 ReminderLog.ID
+	followUpTimers      map[uint]clock.Timer           // 已发送未回复记录的下一次关怀，key 为 ReminderLog.ID
+	followUpPolicy      models.FollowUpPolicy          // 提醒和用户均未设置时使用的关怀策略
+	resumeTimers        map[uint]clock.Timer           // 暂停到期后恢复调度，key 为 Reminder.ID
+	resumeNotice        bool                           // 暂停到期自动恢复时是否通知用户
+	noticeTimers        map[string]clock.Timer         // 免打扰时段内推迟发送的通知，同一 key 只保留最新一次
+	pendingDigests      map[uint][]*models.ReminderLog // 免打扰时段内推迟的错过提醒汇总，key 为 User.ID
+	agendas             map[uint]*agendaJob            // 每日日程推送，key 为 User.ID
+	clock               clock.Clock                    // 时钟，模拟运行时替换为 clock.Simulated
+	missedPolicy        MissedPolicy
+	missedLookback      time.Duration
+	holidays            holiday.Calendar // 工作日模式使用的节假日日历
+	holidayRegion       string           // 默认节假日地区，提醒未指定地区时使用
+	holidayGen          uint64           // 日历更新次数，变化后工作日提醒需要重新调度
+	holidayMu           sync.RWMutex     // 保护 holidays/holidayRegion/holidayGen，调度计划计算时可能已持有 mu
+	pool                *workerPool      // 执行提醒的工作线程池，限制并发的数据库写入和消息发送
+	elector             *leaderElector   // 多实例部署时的主实例选举，为空表示单实例
+	reconcileInterval   time.Duration    // 与数据库增量同步调度的间隔，0 表示不定期同步
+	reconcileTimer      clock.Timer      // 下一次定期同步，调度器未运行时为空
 	mu                  sync.RWMutex
 }
 
@@ -31,8 +76,14 @@ func NewSchedulerService(
 	reminderLogRepo interfaces.ReminderLogRepository,
 	notificationService NotificationService,
 ) SchedulerService {
-	// 使用北京时区
-	loc, _ := time.LoadLocation("Asia/Shanghai")
+	// 默认使用北京时区，可通过 SetDefaultTimezone 覆盖
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		loc = time.Local
+	}
+
+	pool := newWorkerPool(defaultMaxWorkers)
+	pool.Start()
 
 	return &schedulerService{
 		cron:                cron.New(cron.WithLocation(loc)),
@@ -40,146 +91,1775 @@ func NewSchedulerService(
 		reminderRepo:        reminderRepo,
 		reminderLogRepo:     reminderLogRepo,
 		notificationService: notificationService,
-		jobs:                make(map[uint]cron.EntryID),
-		onceTimers:          make(map[uint]*time.Timer),
+		jobs:                make(map[uint][]cron.EntryID),
+		onceTimers:          make(map[uint][]clock.Timer),
+		clockJobs:           make(map[uint][]*clockJob),
+		versions:            make(map[uint]scheduleVersion),
+		deliveryTimers:      make(map[uint]clock.Timer),
+		deliveryAttempts:    make(map[uint]int),
+		followUpTimers:      make(map[uint]clock.Timer),
+		followUpPolicy:      models.DefaultFollowUpPolicy,
+		resumeTimers:        make(map[uint]clock.Timer),
+		resumeNotice:        true,
+		noticeTimers:        make(map[string]clock.Timer),
+		pendingDigests:      make(map[uint][]*models.ReminderLog),
+		agendas:             make(map[uint]*agendaJob),
+		clock:               clock.Real,
+		missedPolicy:        MissedPolicyLate,
+		missedLookback:      defaultMissedLookback,
+		reconcileInterval:   defaultReconcileEvery,
+		holidays:            holiday.Weekdays,
+		pool:                pool,
+	}
+}
+
+// SetClock 替换调度器使用的时钟，需在 Start 之前调用
+// 使用模拟时钟时周期任务改由时钟的定时器驱动，任务在触发的 goroutine 中同步执行，结果确定可复现
+func (s *schedulerService) SetClock(c clock.Clock) {
+	if c == nil {
+		c = clock.Real
+	}
+
+	s.mu.Lock()
+	s.clock = c
+	s.mu.Unlock()
+
+	if !clock.IsReal(c) {
+		logger.Infof("🧪 调度器使用模拟时钟，当前时间: %s", c.Now().Format(time.RFC3339))
+	}
+}
+
+// now 返回调度器时钟的当前时间
+func (s *schedulerService) now() time.Time {
+	if s.clock == nil {
+		return time.Now()
+	}
+	return s.clock.Now()
+}
+
+// afterFunc 通过调度器时钟创建定时器
+func (s *schedulerService) afterFunc(d time.Duration, f func()) clock.Timer {
+	if s.clock == nil {
+		return time.AfterFunc(d, f)
+	}
+	return s.clock.AfterFunc(d, f)
+}
+
+// SetDefaultTimezone 设置默认时区（对应 SchedulerConfig.Timezone）
+func (s *schedulerService) SetDefaultTimezone(name string) error {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("无效的时区: %s", name)
+	}
+
+	s.mu.Lock()
+	s.location = loc
+	s.mu.Unlock()
+
+	logger.Infof("🌏 调度器默认时区: %s", loc.String())
+	return nil
+}
+
+// SetMissedPolicy 设置错过提醒的补偿策略和追溯时间（对应 SchedulerConfig.MissedPolicy/MissedLookback）
+func (s *schedulerService) SetMissedPolicy(policy string, lookback time.Duration) error {
+	switch MissedPolicy(policy) {
+	case MissedPolicyLate, MissedPolicyDigest, MissedPolicyMark:
+	default:
+		return fmt.Errorf("无效的补偿策略: %s", policy)
+	}
+
+	s.mu.Lock()
+	s.missedPolicy = MissedPolicy(policy)
+	if lookback > 0 {
+		s.missedLookback = lookback
+	}
+	s.mu.Unlock()
+
+	logger.Infof("🧭 错过提醒补偿策略: %s, 追溯时间: %s", policy, s.missedLookback)
+	return nil
+}
+
+// SetHolidayCalendar 设置工作日模式使用的节假日日历和默认地区（对应 SchedulerConfig.HolidayDir/HolidayRegion）
+// 日历在每次计算触发时间和触发时实时查询，日历文件重新加载后调用 RefreshSchedules 重新调度工作日提醒
+func (s *schedulerService) SetHolidayCalendar(calendar holiday.Calendar, region string) {
+	if calendar == nil {
+		calendar = holiday.Weekdays
+	}
+
+	s.holidayMu.Lock()
+	s.holidays = calendar
+	s.holidayRegion = region
+	s.holidayGen++
+	s.holidayMu.Unlock()
+
+	logger.Infof("📅 节假日日历地区: %s", region)
+}
+
+// SetMaxWorkers 设置同时执行提醒的最大工作线程数（对应 SchedulerConfig.MaxWorkers）
+func (s *schedulerService) SetMaxWorkers(workers int) error {
+	if workers <= 0 {
+		return fmt.Errorf("无效的工作线程数: %d", workers)
+	}
+
+	pool := newWorkerPool(workers)
+	pool.Start()
+
+	s.mu.Lock()
+	old := s.pool
+	s.pool = pool
+	s.mu.Unlock()
+
+	// 旧线程池中已排队的任务执行完毕后退出
+	old.Stop()
+
+	logger.Infof("👷 提醒执行工作线程数: %d (队列容量: %d)", workers, workers*workerQueueFactor)
+	return nil
+}
+
+// dispatch 将任务交给工作线程池执行，队列满时阻塞等待
+func (s *schedulerService) dispatch(name string, fn func()) {
+	s.mu.RLock()
+	pool := s.pool
+	s.mu.RUnlock()
+
+	if pool == nil || !clock.IsReal(s.clock) {
+		fn()
+		return
+	}
+	pool.Submit(name, fn)
+}
+
+// EnableLeaderElection 启用基于数据库租约的主实例选举（对应 SchedulerConfig.LeaderElection/LeaseTTL/InstanceID）
+// 所有实例都加载调度任务，但只有持有租约的主实例真正触发提醒；需在 Start 之前调用
+func (s *schedulerService) EnableLeaderElection(leaseRepo interfaces.LeaseRepository, instanceID string, ttl time.Duration) {
+	elector := newLeaderElector(leaseRepo, instanceID, ttl)
+	elector.onElected = s.takeOver
+
+	s.mu.Lock()
+	s.elector = elector
+	s.mu.Unlock()
+
+	logger.Infof("🗳️ 已启用调度主实例选举 (实例: %s, 租约: %s)", instanceID, elector.ttl)
+}
+
+// isLeader 当前实例是否应触发提醒，未启用选举时始终为 true
+func (s *schedulerService) isLeader() bool {
+	s.mu.RLock()
+	elector := s.elector
+	s.mu.RUnlock()
+
+	return elector == nil || elector.IsLeader()
+}
+
+// takeOver 成为主实例后重新加载提醒，补偿接管前错过的提醒并恢复待投递记录
+func (s *schedulerService) takeOver() {
+	if err := s.RefreshSchedules(); err != nil {
+		logger.Errorf("接管后刷新调度任务失败: %v", err)
+	}
+
+	ctx := context.Background()
+	reminders, err := s.reminderRepo.GetActiveReminders(ctx)
+	if err != nil {
+		logger.Errorf("接管后获取有效提醒失败: %v", err)
+		return
+	}
+
+	if missed := s.reconcileMissed(ctx, reminders, s.now()); missed > 0 {
+		logger.Infof("🧭 已处理 %d 次接管前错过的提醒 (策略: %s)", missed, s.missedPolicy)
+	}
+	s.restorePendingDeliveries(ctx)
+}
+
+func (s *schedulerService) Start() error {
+	logger.Info("🕰️ 定时调度器启动中...")
+
+	s.mu.RLock()
+	s.pool.Start()
+	elector := s.elector
+	s.mu.RUnlock()
+
+	// 启动cron调度器
+	s.cron.Start()
+
+	// 从数据库恢复所有有效提醒
+	ctx := context.Background()
+	reminders, err := s.reminderRepo.GetActiveReminders(ctx)
+	if err != nil {
+		return fmt.Errorf("获取有效提醒失败: %w", err)
+	}
+
+	leader := true
+	if elector != nil {
+		leader, _ = elector.tryAcquire(ctx)
+		if !leader {
+			logger.Infof("🪑 调度租约由其他实例持有，当前实例作为备用启动")
+		}
+	}
+
+	// 补偿停机期间错过的提醒，过期的一次性提醒会在此停用；备用实例由主实例负责
+	if leader {
+		missed := s.reconcileMissed(ctx, reminders, s.now())
+		if missed > 0 {
+			logger.Infof("🧭 已处理 %d 次停机期间错过的提醒 (策略: %s)", missed, s.missedPolicy)
+		}
+	}
+
+	// 为每个提醒添加调度任务
+	for _, reminder := range reminders {
+		if !reminder.IsActive {
+			continue
+		}
+		if err := s.AddReminder(reminder); err != nil {
+			logger.Errorf("添加提醒调度失败 (ID: %d): %v", reminder.ID, err)
+			continue
+		}
+	}
+
+	// 安排开启了每日日程的用户的推送
+	s.syncAgendas(reminders)
+
+	// 恢复尚未投递的提醒记录（如延期提醒），重启期间到期的会立即投递
+	delivered := 0
+	if leader {
+		delivered = s.restorePendingDeliveries(ctx)
+	}
+
+	// 定期续期租约，主实例宕机后由备用实例接管
+	if elector != nil {
+		elector.Start()
+	}
+
+	// 定期与数据库同步，其他进程或直接修改数据库的变更也能生效
+	s.mu.Lock()
+	s.armReconcileLocked()
+	s.mu.Unlock()
+
+	logger.Infof("✅ 定时调度器启动成功，已加载 %d 个提醒，%d 条待投递记录", len(reminders), delivered)
+	return nil
+}
+
+func (s *schedulerService) Stop() error {
+	logger.Info("🔄 定时调度器停止中...")
+	s.cron.Stop()
+	s.mu.Lock()
+	for id, timers := range s.onceTimers {
+		stopTimers(timers)
+		delete(s.onceTimers, id)
+	}
+	for id, timer := range s.deliveryTimers {
+		timer.Stop()
+		delete(s.deliveryTimers, id)
+	}
+	for id := range s.deliveryAttempts {
+		delete(s.deliveryAttempts, id)
+	}
+	for id, timer := range s.followUpTimers {
+		timer.Stop()
+		delete(s.followUpTimers, id)
+	}
+	for id, timer := range s.resumeTimers {
+		timer.Stop()
+		delete(s.resumeTimers, id)
+	}
+	for key, timer := range s.noticeTimers {
+		timer.Stop()
+		delete(s.noticeTimers, key)
+	}
+	for id := range s.pendingDigests {
+		delete(s.pendingDigests, id)
+	}
+	for id, jobs := range s.clockJobs {
+		for _, job := range jobs {
+			job.Stop()
+		}
+		delete(s.clockJobs, id)
+	}
+	for id := range s.agendas {
+		s.clearAgendaLocked(id)
+	}
+	s.jobs = make(map[uint][]cron.EntryID)
+	s.versions = make(map[uint]scheduleVersion)
+	if s.reconcileTimer != nil {
+		s.reconcileTimer.Stop()
+		s.reconcileTimer = nil
+	}
+	pool := s.pool
+	elector := s.elector
+	s.mu.Unlock()
+
+	// 等待已排队的提醒执行完毕
+	pool.Stop()
+
+	// 最后释放租约，让备用实例尽快接管
+	if elector != nil {
+		elector.Stop()
+	}
+	logger.Info("✅ 定时调度器已停止")
+	return nil
+}
+
+func (s *schedulerService) AddReminder(reminder *models.Reminder) error {
+	if reminder == nil {
+		return fmt.Errorf("提醒信息不能为空")
+	}
+
+	if !reminder.IsActive {
+		return fmt.Errorf("提醒未激活，无法添加调度")
+	}
+
+	// 间隔模式的起算点需要查询数据库，在加锁前取得
+	last := s.lastOccurrence(reminder)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 如果存在旧的定时器/任务，先清理
+	s.clearReminderLocked(reminder.ID)
+
+	// 调度失败（如一次性提醒已过期）同样记录版本，提醒未变更时不再重复尝试
+	s.versions[reminder.ID] = s.versionOf(reminder)
+
+	if reminder.IsChainedStep() {
+		logger.Debugf("🔗 例程步骤由上一步完成触发，不单独调度: ID=%d", reminder.ID)
+		return nil
+	}
+
+	if reminder.IsPausedAt(s.now()) {
+		s.armResumeLocked(reminder)
+		logger.Debugf("⏸️ 提醒处于暂停状态，到期后恢复调度: ID=%d, 恢复时间=%s", reminder.ID, reminder.PausedUntil.Format(time.RFC3339))
+		return nil
+	}
+
+	// 暂停已到期但尚未清除（如定期同步先于恢复定时器执行，或停机期间到期），由主实例立即补做恢复
+	if reminder.PausedUntil != nil && (s.elector == nil || s.elector.IsLeader()) {
+		s.armResumeLocked(reminder)
+	}
+
+	if reminder.IsOnce() {
+		return s.addOnceReminderLocked(reminder)
+	}
+
+	schedules, err := s.buildSchedulesFrom(reminder, last)
+	if err != nil {
+		return err
+	}
+
+	// 每个触发时间注册一个任务，执行时都归到同一个提醒下
+	reminderID := reminder.ID
+	if clock.IsReal(s.clock) {
+		entryIDs := make([]cron.EntryID, 0, len(schedules))
+		for _, schedule := range schedules {
+			entryIDs = append(entryIDs, s.cron.Schedule(schedule, cron.FuncJob(func() {
+				s.dispatchReminder(reminderID, occurrenceAt(schedule, s.now()))
+			})))
+			for _, offset := range reminder.LeadOffsets() {
+				entryIDs = append(entryIDs, s.cron.Schedule(leadSchedule{schedule: schedule, offset: offset}, cron.FuncJob(func() {
+					s.dispatchLead(reminderID, occurrenceAt(schedule, s.now().Add(offset)), offset)
+				})))
+			}
+		}
+		s.jobs[reminder.ID] = entryIDs
+	} else {
+		// cron 只能按系统时间运行，模拟时钟下改用定时器链
+		jobs := make([]*clockJob, 0, len(schedules))
+		for _, schedule := range schedules {
+			jobs = append(jobs, startClockJob(s.clock, schedule, func(occurrence time.Time) {
+				s.dispatchReminder(reminderID, occurrence)
+			}))
+			for _, offset := range reminder.LeadOffsets() {
+				jobs = append(jobs, startClockJob(s.clock, leadSchedule{schedule: schedule, offset: offset}, func(fired time.Time) {
+					s.dispatchLead(reminderID, fired.Add(offset), offset)
+				}))
+			}
+		}
+		s.clockJobs[reminder.ID] = jobs
+	}
+
+	logger.Debugf("📅 添加提醒调度: ID=%d, Pattern=%s, 时间=%s, 时区=%s",
+		reminder.ID, reminder.SchedulePattern, strings.Join(reminder.Times(), ","), s.resolveLocation(reminder).String())
+	return nil
+}
+
+func (s *schedulerService) RemoveReminder(reminderID uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := s.clearReminderLocked(reminderID)
+
+	if removed {
+		logger.Debugf("🗑️ 移除提醒调度: ID=%d", reminderID)
+		return nil
+	}
+
+	return fmt.Errorf("提醒调度不存在: %d", reminderID)
+}
+
+func (s *schedulerService) RefreshSchedules() error {
+	ctx := context.Background()
+	reminders, err := s.reminderRepo.GetActiveReminders(ctx)
+	if err != nil {
+		return fmt.Errorf("获取有效提醒失败: %w", err)
+	}
+
+	// 与已调度的版本比较，找出新增、变更和已失效的提醒
+	active := make(map[uint]bool, len(reminders))
+	var changed []*models.Reminder
+	var removed []uint
+
+	s.mu.RLock()
+	for _, reminder := range reminders {
+		active[reminder.ID] = true
+		if version, ok := s.versions[reminder.ID]; !ok || version != s.versionOf(reminder) {
+			changed = append(changed, reminder)
+		}
+	}
+	for id := range s.versions {
+		if !active[id] {
+			removed = append(removed, id)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, id := range removed {
+		s.mu.Lock()
+		s.clearReminderLocked(id)
+		s.mu.Unlock()
+	}
+
+	// AddReminder 会先清理旧任务再重新调度，未变更的提醒保持原样
+	for _, reminder := range changed {
+		if err := s.AddReminder(reminder); err != nil {
+			logger.Errorf("重新添加提醒调度失败 (ID: %d): %v", reminder.ID, err)
+		}
+	}
+
+	// 用户修改日程推送时间或时区后更新推送计划
+	s.syncAgendas(reminders)
+
+	if len(changed) > 0 || len(removed) > 0 {
+		logger.Infof("🔄 调度任务已同步：更新 %d 个，移除 %d 个，当前有效提醒 %d 个", len(changed), len(removed), len(reminders))
+	} else {
+		logger.Debugf("🔄 调度任务无变化，当前有效提醒 %d 个", len(reminders))
+	}
+	return nil
+}
+
+// SetReconcileInterval 设置与数据库增量同步调度的间隔（对应 SchedulerConfig.ReconcileInterval），0 表示只在启动和热更新时同步
+func (s *schedulerService) SetReconcileInterval(interval time.Duration) error {
+	if interval < 0 {
+		return fmt.Errorf("同步间隔不能为负数: %s", interval)
+	}
+
+	s.mu.Lock()
+	s.reconcileInterval = interval
+	if s.reconcileTimer != nil {
+		s.reconcileTimer.Stop()
+		s.armReconcileLocked()
+	}
+	s.mu.Unlock()
+
+	logger.Infof("🔄 调度同步间隔: %s", interval)
+	return nil
+}
+
+// armReconcileLocked 设置下一次定期同步，调用方需持有 mu
+func (s *schedulerService) armReconcileLocked() {
+	s.reconcileTimer = nil
+	if s.reconcileInterval <= 0 {
+		return
+	}
+
+	var timer clock.Timer
+	timer = s.afterFunc(s.reconcileInterval, func() {
+		if err := s.RefreshSchedules(); err != nil {
+			logger.Errorf("同步调度任务失败: %v", err)
+		}
+
+		// 调度器已停止或间隔已变更时不再继续
+		s.mu.Lock()
+		if s.reconcileTimer == timer {
+			s.armReconcileLocked()
+		}
+		s.mu.Unlock()
+	})
+	s.reconcileTimer = timer
+}
+
+// scheduleVersion 决定调度计划的全部输入，任一项变化都需要重新调度
+type scheduleVersion struct {
+	updatedAt time.Time
+	location  string // 解析后的时区，包含用户时区和默认时区的变化
+	paused    bool   // 暂停结束后需要重新调度
+	calendar  uint64 // 工作日提醒依赖的节假日日历版本
+}
+
+// versionOf 计算提醒当前的调度版本
+func (s *schedulerService) versionOf(reminder *models.Reminder) scheduleVersion {
+	version := scheduleVersion{
+		updatedAt: reminder.UpdatedAt,
+		location:  s.resolveLocation(reminder).String(),
+		paused:    reminder.IsPausedAt(s.now()),
+	}
+	if reminder.IsWorkday() {
+		s.holidayMu.RLock()
+		version.calendar = s.holidayGen
+		s.holidayMu.RUnlock()
+	}
+	return version
+}
+
+// ScheduleDelivery 安排提醒记录在 ScheduledTime 投递，已到期的立即投递
+func (s *schedulerService) ScheduleDelivery(log *models.ReminderLog) error {
+	if log == nil || log.ID == 0 {
+		return fmt.Errorf("提醒记录不能为空")
+	}
+
+	if log.Status != models.ReminderStatusPending {
+		return fmt.Errorf("提醒记录状态不是待发送: %s", log.Status)
+	}
+
+	delay := log.ScheduledTime.Sub(s.now())
+	if delay < 0 {
+		delay = 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.armDeliveryLocked(log.ID, delay)
+
+	logger.Debugf("📬 提醒记录已加入投递队列: LogID=%d, 投递时间=%s", log.ID, log.ScheduledTime.Format(time.RFC3339))
+	return nil
+}
+
+// armDeliveryLocked 在 delay 后投递提醒记录，替换已有的投递计时器，调用方需持有 mu
+func (s *schedulerService) armDeliveryLocked(logID uint, delay time.Duration) {
+	if timer, exists := s.deliveryTimers[logID]; exists {
+		timer.Stop()
+	}
+
+	s.deliveryTimers[logID] = s.afterFunc(delay, func() {
+		s.dispatch(fmt.Sprintf("投递提醒记录 %d", logID), func() {
+			s.deliverLog(logID)
+		})
+	})
+}
+
+// retryDelivery 发送失败的待发送记录按退避时间重新投递，超过重试次数后取消
+// 记录已被认领，不重试的话这次提醒既不会再发送也不会被补偿
+func (s *schedulerService) retryDelivery(ctx context.Context, log *models.ReminderLog, cause error) {
+	s.mu.Lock()
+	s.deliveryAttempts[log.ID]++
+	attempt := s.deliveryAttempts[log.ID]
+	if attempt <= maxDeliveryRetries {
+		delay := deliveryBackoff(attempt)
+		s.armDeliveryLocked(log.ID, delay)
+		s.mu.Unlock()
+		logger.Warnf("📮 发送提醒失败，%s 后第 %d 次重试 (LogID: %d): %v", delay, attempt, log.ID, cause)
+		return
+	}
+	delete(s.deliveryAttempts, log.ID)
+	s.mu.Unlock()
+
+	log.Status = models.ReminderStatusCancelled
+	if err := s.reminderLogRepo.Update(ctx, log); err != nil {
+		logger.Errorf("取消提醒记录失败 (LogID: %d): %v", log.ID, err)
+	}
+	logger.Errorf("发送提醒重试 %d 次仍失败，已放弃 (LogID: %d): %v", maxDeliveryRetries, log.ID, cause)
+}
+
+// clearDeliveryAttempts 清除提醒记录的重试次数
+func (s *schedulerService) clearDeliveryAttempts(logID uint) {
+	s.mu.Lock()
+	delete(s.deliveryAttempts, logID)
+	s.mu.Unlock()
+}
+
+// deliveryBackoff 第 n 次重试前的等待时间：1分钟、2分钟、4分钟……最长30分钟
+func deliveryBackoff(attempt int) time.Duration {
+	backoff := deliveryRetryBase << (attempt - 1)
+	if backoff <= 0 || backoff > deliveryRetryMax {
+		return deliveryRetryMax
+	}
+	return backoff
+}
+
+// NextOccurrences 返回提醒在 after 之后最多 n 次触发时间，按提醒时区表示
+// 暂停期间的触发会被跳过，截止日期、剩余次数和节假日日历与实际调度一致；例程中由上一步触发的步骤没有固定触发时间
+func (s *schedulerService) NextOccurrences(reminder *models.Reminder, after time.Time, n int) ([]time.Time, error) {
+	if reminder == nil || !reminder.IsActive || reminder.IsChainedStep() || n <= 0 {
+		return nil, nil
+	}
+	if remaining := reminder.RemainingOccurrences(); remaining >= 0 && remaining < n {
+		n = remaining
+	}
+	if reminder.PausedUntil != nil && reminder.PausedUntil.After(after) {
+		after = *reminder.PausedUntil
+	}
+
+	loc := s.resolveLocation(reminder)
+	var occurrences []time.Time
+
+	if reminder.IsOnce() {
+		targets, err := s.onceTargetTimes(reminder)
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(targets, func(i, j int) bool { return targets[i].Before(targets[j]) })
+		for _, target := range targets {
+			if target.After(after) && len(occurrences) < n {
+				occurrences = append(occurrences, target.In(loc))
+			}
+		}
+		return occurrences, nil
+	}
+
+	schedules, err := s.buildSchedules(reminder)
+	if err != nil {
+		return nil, err
+	}
+	schedule := multiSchedule(schedules)
+	for next := schedule.Next(after); !next.IsZero() && len(occurrences) < n; next = schedule.Next(next) {
+		occurrences = append(occurrences, next.In(loc))
+	}
+	return occurrences, nil
+}
+
+// restorePendingDeliveries 从数据库恢复待投递的提醒记录和待关怀的已发送记录，返回恢复投递的数量
+func (s *schedulerService) restorePendingDeliveries(ctx context.Context) int {
+	logs, err := s.reminderLogRepo.GetPendingLogs(ctx)
+	if err != nil {
+		logger.Errorf("获取待投递提醒记录失败: %v", err)
+		return 0
+	}
+
+	count := 0
+	for _, log := range logs {
+		// 已发送未回复的记录继续按策略关怀
+		if log.Status == models.ReminderStatusSent {
+			s.scheduleFollowUp(log)
+			continue
+		}
+		if log.Status != models.ReminderStatusPending {
+			continue
+		}
+		if err := s.ScheduleDelivery(log); err != nil {
+			logger.Errorf("恢复提醒记录投递失败 (LogID: %d): %v", log.ID, err)
+			continue
+		}
+		count++
+	}
+	return count
+}
+
+// deliverLog 投递到期的提醒记录
+func (s *schedulerService) deliverLog(logID uint) {
+	ctx := context.Background()
+
+	s.mu.Lock()
+	delete(s.deliveryTimers, logID)
+	s.mu.Unlock()
+
+	reminderLog, err := s.reminderLogRepo.GetByID(ctx, logID)
+	if err != nil {
+		logger.Errorf("获取提醒记录失败 (LogID: %d): %v", logID, err)
+		return
+	}
+	if reminderLog == nil || reminderLog.Status != models.ReminderStatusPending {
+		s.clearDeliveryAttempts(logID)
+		logger.Debugf("提醒记录不存在或已处理，跳过投递 (LogID: %d)", logID)
+		return
+	}
+
+	if !s.isLeader() {
+		logger.Debugf("🪑 备用实例不投递提醒记录 (LogID: %d)", logID)
+		return
+	}
+
+	reminder, err := s.reminderRepo.GetByID(ctx, reminderLog.ReminderID)
+	if err != nil {
+		logger.Errorf("获取提醒失败 (ID: %d): %v", reminderLog.ReminderID, err)
+		return
+	}
+
+	// 提醒已删除、停用或暂停时不再投递
+	if reminder == nil || !reminder.IsActive || reminder.IsPausedAt(s.now()) {
+		s.clearDeliveryAttempts(logID)
+		reminderLog.Status = models.ReminderStatusCancelled
+		if err := s.reminderLogRepo.Update(ctx, reminderLog); err != nil {
+			logger.Errorf("取消提醒记录失败 (LogID: %d): %v", logID, err)
+		}
+		logger.Infof("🚫 提醒已停用，取消投递 (LogID: %d)", logID)
+		return
+	}
+
+	// 延期后落在免打扰时段内，或投递前用户修改了免打扰设置
+	if until, _, quiet := s.quietUntil(reminder, s.now()); quiet {
+		s.deferDelivery(ctx, reminderLog, until)
+		return
+	}
+
+	if err := s.notificationService.SendReminder(ctx, reminderLog); err != nil {
+		s.retryDelivery(ctx, reminderLog, err)
+		return
+	}
+	s.clearDeliveryAttempts(logID)
+
+	reminderLog.MarkAsSentAt(s.now())
+	if err := s.reminderLogRepo.Update(ctx, reminderLog); err != nil {
+		logger.Errorf("更新提醒记录失败 (LogID: %d): %v", logID, err)
+	} else {
+		s.scheduleFollowUp(reminderLog)
+	}
+
+	logger.Infof("📬 延期提醒已投递 (LogID: %d)", logID)
+}
+
+// quietUntil 判断 at 是否处于提醒所属用户的免打扰时段内，按用户时区计算并返回时段结束时间
+// 重要提醒和免打扰设置无效时不受限制
+func (s *schedulerService) quietUntil(reminder *models.Reminder, at time.Time) (time.Time, models.QuietHours, bool) {
+	if reminder.Critical {
+		return time.Time{}, models.QuietHours{}, false
+	}
+	return s.userQuietUntil(&reminder.User, at)
+}
+
+// userQuietUntil 判断 at 是否处于用户的免打扰时段内，用于不属于单个提醒的通知（如错过提醒汇总）
+func (s *schedulerService) userQuietUntil(user *models.User, at time.Time) (time.Time, models.QuietHours, bool) {
+	if user.QuietHours == "" {
+		return time.Time{}, models.QuietHours{}, false
+	}
+
+	hours, err := models.ParseQuietHours(user.QuietHours)
+	if err != nil {
+		logger.Warnf("用户免打扰设置无效，忽略 (UserID: %d): %v", user.ID, err)
+		return time.Time{}, models.QuietHours{}, false
+	}
+
+	until, quiet := hours.Until(at.In(s.userLocation(user)))
+	return until, hours, quiet
+}
+
+// deferDelivery 将待发送的提醒记录推迟到 until 投递
+func (s *schedulerService) deferDelivery(ctx context.Context, log *models.ReminderLog, until time.Time) {
+	log.ScheduledTime = until
+	if err := s.reminderLogRepo.Update(ctx, log); err != nil {
+		logger.Errorf("推迟提醒记录失败 (LogID: %d): %v", log.ID, err)
+		return
+	}
+	if err := s.ScheduleDelivery(log); err != nil {
+		logger.Errorf("安排推迟投递失败 (LogID: %d): %v", log.ID, err)
+		return
+	}
+	logger.Infof("🌙 免打扰时段内，提醒推迟到 %s 投递 (LogID: %d)", until.Format(time.RFC3339), log.ID)
+}
+
+// SetFollowUpPolicy 设置默认关怀策略（对应 SchedulerConfig.FollowUpPolicy），提醒和用户均未设置时使用
+func (s *schedulerService) SetFollowUpPolicy(policy models.FollowUpPolicy) {
+	s.mu.Lock()
+	s.followUpPolicy = policy
+	s.mu.Unlock()
+
+	logger.Infof("💌 默认关怀策略: %s", policy)
+}
+
+// scheduleFollowUp 按生效的关怀策略安排已发送记录的下一次关怀，策略关闭或记录已回复时不安排
+func (s *schedulerService) scheduleFollowUp(log *models.ReminderLog) {
+	if log == nil || log.ID == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if timer, exists := s.followUpTimers[log.ID]; exists {
+		timer.Stop()
+		delete(s.followUpTimers, log.ID)
+	}
+
+	policy := log.Reminder.ResolveFollowUpPolicy(s.followUpPolicy)
+	dueAt, ok := policy.NextAt(log)
+	if !ok {
+		return
+	}
+
+	s.armFollowUpLocked(log.ID, dueAt)
+}
+
+// armFollowUpLocked 在 at 时处理记录的关怀，调用方需持有 mu
+func (s *schedulerService) armFollowUpLocked(logID uint, at time.Time) {
+	if timer, exists := s.followUpTimers[logID]; exists {
+		timer.Stop()
+	}
+
+	delay := at.Sub(s.now())
+	if delay < 0 {
+		delay = 0
+	}
+
+	s.followUpTimers[logID] = s.afterFunc(delay, func() {
+		s.dispatch(fmt.Sprintf("关怀提醒记录 %d", logID), func() {
+			s.followUp(logID)
+		})
+	})
+
+	logger.Debugf("💌 已安排关怀: LogID=%d, 时间=%s", logID, at.Format(time.RFC3339))
+}
+
+// followUp 发送到期的关怀消息，最后一次关怀后仍未回复时按策略标记为已超时或已跳过
+func (s *schedulerService) followUp(logID uint) {
+	ctx := context.Background()
+
+	s.mu.Lock()
+	delete(s.followUpTimers, logID)
+	fallback := s.followUpPolicy
+	s.mu.Unlock()
+
+	if !s.isLeader() {
+		logger.Debugf("🪑 备用实例不发送关怀 (LogID: %d)", logID)
+		return
+	}
+
+	reminderLog, err := s.reminderLogRepo.GetByID(ctx, logID)
+	if err != nil {
+		logger.Errorf("获取提醒记录失败 (LogID: %d): %v", logID, err)
+		return
+	}
+	if reminderLog == nil || reminderLog.Status != models.ReminderStatusSent {
+		logger.Debugf("提醒记录不存在或已回复，取消关怀 (LogID: %d)", logID)
+		return
+	}
+
+	// 策略可能在安排后被修改，以当前设置为准
+	policy := reminderLog.Reminder.ResolveFollowUpPolicy(fallback)
+	dueAt, ok := policy.NextAt(reminderLog)
+	if !ok {
+		return
+	}
+	if dueAt.After(s.now()) {
+		s.scheduleFollowUp(reminderLog)
+		return
+	}
+
+	if reminderLog.FollowUpCount >= policy.Max {
+		reminderLog.Status = policy.Then
+		if err := s.reminderLogRepo.Update(ctx, reminderLog); err != nil {
+			logger.Errorf("更新提醒记录状态失败 (LogID: %d): %v", logID, err)
+			return
+		}
+		logger.Infof("⌛ 关怀 %d 次后仍未回复，记录为 %s (LogID: %d)", reminderLog.FollowUpCount, policy.Then, logID)
+		return
+	}
+
+	// 免打扰时段内的关怀按用户设置推迟到时段结束，或丢弃本次并计入次数
+	if until, hours, quiet := s.quietUntil(&reminderLog.Reminder, s.now()); quiet {
+		if hours.FollowUps == models.QuietFollowUpDefer {
+			s.mu.Lock()
+			s.armFollowUpLocked(logID, until)
+			s.mu.Unlock()
+			logger.Infof("🌙 免打扰时段内，关怀推迟到 %s (LogID: %d)", until.Format(time.RFC3339), logID)
+			return
+		}
+
+		now := s.now()
+		reminderLog.FollowUpCount++
+		reminderLog.LastFollowUpAt = &now
+		if err := s.reminderLogRepo.Update(ctx, reminderLog); err != nil {
+			logger.Errorf("更新关怀次数失败 (LogID: %d): %v", logID, err)
+			return
+		}
+		logger.Infof("🌙 免打扰时段内，跳过第 %d 次关怀 (LogID: %d)", reminderLog.FollowUpCount, logID)
+		s.scheduleFollowUp(reminderLog)
+		return
+	}
+
+	final := reminderLog.FollowUpCount+1 >= policy.Max
+	if err := s.notificationService.SendFollowUp(ctx, reminderLog, final); err != nil {
+		logger.Errorf("发送关怀消息失败 (LogID: %d): %v", logID, err)
+		return
+	}
+
+	now := s.now()
+	reminderLog.FollowUpCount++
+	reminderLog.LastFollowUpAt = &now
+	if err := s.reminderLogRepo.Update(ctx, reminderLog); err != nil {
+		logger.Errorf("更新关怀次数失败 (LogID: %d): %v", logID, err)
+		return
+	}
+
+	logger.Debugf("💌 已发送关怀消息: LogID=%d, 次数=%d/%d", logID, reminderLog.FollowUpCount, policy.Max)
+	s.scheduleFollowUp(reminderLog)
+}
+
+// SetResumeNotice 设置暂停到期自动恢复时是否通知用户（对应 SchedulerConfig.ResumeNotice）
+func (s *schedulerService) SetResumeNotice(enabled bool) {
+	s.mu.Lock()
+	s.resumeNotice = enabled
+	s.mu.Unlock()
+
+	logger.Infof("▶️ 暂停到期恢复通知: %v", enabled)
+}
+
+// armResumeLocked 在暂停到期时恢复提醒的调度，调用方需持有 mu
+func (s *schedulerService) armResumeLocked(reminder *models.Reminder) {
+	delay := reminder.PausedUntil.Sub(s.now())
+	if delay < 0 {
+		delay = 0
+	}
+
+	reminderID := reminder.ID
+	s.resumeTimers[reminderID] = s.afterFunc(delay, func() {
+		s.dispatch(fmt.Sprintf("恢复暂停提醒 %d", reminderID), func() {
+			s.resumeReminder(reminderID)
+		})
+	})
+}
+
+// resumeReminder 暂停到期后重新调度提醒，主实例清除暂停状态并按配置通知用户
+func (s *schedulerService) resumeReminder(reminderID uint) {
+	ctx := context.Background()
+
+	s.mu.Lock()
+	delete(s.resumeTimers, reminderID)
+	notice := s.resumeNotice
+	s.mu.Unlock()
+
+	reminder, err := s.reminderRepo.GetByID(ctx, reminderID)
+	if err != nil {
+		logger.Errorf("获取暂停提醒失败 (ID: %d): %v", reminderID, err)
+		return
+	}
+	if reminder == nil || !reminder.IsActive || reminder.PausedUntil == nil {
+		logger.Debugf("提醒不存在、已停用或已手动恢复，跳过自动恢复 (ID: %d)", reminderID)
+		return
+	}
+
+	// 暂停被延长时 AddReminder 会按新的到期时间重新安排；备用实例只恢复本地调度
+	if reminder.IsPausedAt(s.now()) || !s.isLeader() {
+		if err := s.AddReminder(reminder); err != nil {
+			logger.Errorf("重新添加提醒调度失败 (ID: %d): %v", reminderID, err)
+		}
+		return
+	}
+
+	quietResume := reminder.QuietResume
+	reminder.PausedUntil = nil
+	reminder.PauseReason = ""
+	reminder.QuietResume = false
+	updateErr := s.reminderRepo.Update(ctx, reminder)
+	if updateErr != nil {
+		logger.Errorf("清除提醒暂停状态失败 (ID: %d): %v", reminderID, updateErr)
+	}
+
+	if err := s.AddReminder(reminder); err != nil {
+		logger.Errorf("恢复提醒调度失败 (ID: %d): %v", reminderID, err)
+		return
+	}
+	logger.Infof("▶️ 提醒暂停已到期，恢复调度 (ID: %d)", reminderID)
+
+	if !notice || quietResume || updateErr != nil {
+		return
+	}
+
+	// 免打扰时段内推迟到时段结束后通知
+	if until, _, quiet := s.quietUntil(reminder, s.now()); quiet {
+		s.deferNotice(fmt.Sprintf("resumed/%d", reminderID), until, func() {
+			s.sendResumed(reminderID)
+		})
+		return
+	}
+	if err := s.notificationService.SendResumed(ctx, reminder); err != nil {
+		logger.Errorf("发送恢复通知失败 (ID: %d): %v", reminderID, err)
+	}
+}
+
+// sendResumed 发送推迟的恢复通知，期间提醒被停用或再次暂停时不再通知
+func (s *schedulerService) sendResumed(reminderID uint) {
+	ctx := context.Background()
+
+	if !s.isLeader() {
+		return
+	}
+	reminder, err := s.reminderRepo.GetByID(ctx, reminderID)
+	if err != nil {
+		logger.Errorf("获取提醒失败 (ID: %d): %v", reminderID, err)
+		return
+	}
+	if reminder == nil || !reminder.IsActive || reminder.IsPausedAt(s.now()) {
+		return
+	}
+	if err := s.notificationService.SendResumed(ctx, reminder); err != nil {
+		logger.Errorf("发送恢复通知失败 (ID: %d): %v", reminderID, err)
+	}
+}
+
+// deferNotice 在 until 发送免打扰时段内产生的通知，同一 key 已有推迟的通知时替换
+func (s *schedulerService) deferNotice(key string, until time.Time, send func()) {
+	delay := until.Sub(s.now())
+	if delay < 0 {
+		delay = 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if timer, exists := s.noticeTimers[key]; exists {
+		timer.Stop()
+	}
+	var timer clock.Timer
+	timer = s.afterFunc(delay, func() {
+		s.mu.Lock()
+		if s.noticeTimers[key] == timer {
+			delete(s.noticeTimers, key)
+		}
+		s.mu.Unlock()
+		s.dispatch("推迟的通知 "+key, send)
+	})
+	s.noticeTimers[key] = timer
+
+	logger.Infof("🌙 免打扰时段内，通知推迟到 %s 发送 (%s)", until.Format(time.RFC3339), key)
+}
+
+// reconcileMissed 查找并补偿停机期间错过的提醒，返回处理的错过次数
+func (s *schedulerService) reconcileMissed(ctx context.Context, reminders []*models.Reminder, now time.Time) int {
+	since := now.Add(-s.missedLookback)
+	digests := make(map[uint][]*models.ReminderLog)
+	total := 0
+
+	for _, reminder := range reminders {
+		// 例程中由上一步触发的步骤没有自身的触发时间
+		if reminder.IsChainedStep() {
+			continue
+		}
+
+		counted := false
+		if !reminder.IsPausedAt(s.now()) {
+			occurrences, err := s.missedOccurrences(ctx, reminder, since, now)
+			if remaining := reminder.RemainingOccurrences(); remaining >= 0 && len(occurrences) > remaining {
+				occurrences = occurrences[:remaining]
+			}
+			if err != nil {
+				logger.Errorf("计算错过的提醒失败 (ID: %d): %v", reminder.ID, err)
+			} else if len(occurrences) > 0 {
+				digest, claimed := s.handleMissed(ctx, reminder, occurrences)
+				digests[reminder.UserID] = append(digests[reminder.UserID], digest...)
+				total += claimed
+				reminder.OccurrenceCount += claimed
+				counted = claimed > 0
+			}
+		}
+
+		// 已过期的一次性提醒不会再触发，直接停用
+		if reminder.IsOnce() && s.onceExpired(reminder, now) {
+			reminder.IsActive = false
+			if err := s.reminderRepo.Update(ctx, reminder); err != nil {
+				logger.Errorf("停用过期一次性提醒失败 (ID: %d): %v", reminder.ID, err)
+			} else {
+				logger.Infof("⌛ 一次性提醒已过期并停用 (ID: %d)", reminder.ID)
+			}
+			continue
+		}
+
+		// 停机期间已到结束条件的重复提醒同样停用
+		if s.reminderEnded(reminder, now) {
+			reminder.IsActive = false
+			if err := s.reminderRepo.Update(ctx, reminder); err != nil {
+				logger.Errorf("停用已结束的提醒失败 (ID: %d): %v", reminder.ID, err)
+			} else {
+				logger.Infof("🏁 提醒已到结束条件并停用 (ID: %d)", reminder.ID)
+			}
+			continue
+		}
+
+		if counted {
+			if err := s.reminderRepo.UpdateOccurrenceCount(ctx, reminder.ID, reminder.OccurrenceCount); err != nil {
+				logger.Errorf("更新提醒触发次数失败 (ID: %d): %v", reminder.ID, err)
+			}
+		}
+	}
+
+	for userID, logs := range digests {
+		if len(logs) == 0 {
+			continue
+		}
+		// 免打扰时段内推迟到时段结束，期间再次补偿的记录并入同一条汇总
+		if until, _, quiet := s.userQuietUntil(&logs[0].Reminder.User, s.now()); quiet {
+			s.mu.Lock()
+			s.pendingDigests[userID] = append(s.pendingDigests[userID], logs...)
+			s.mu.Unlock()
+			id := userID
+			s.deferNotice(fmt.Sprintf("digest/%d", userID), until, func() {
+				s.sendPendingDigest(id)
+			})
+			continue
+		}
+		if err := s.notificationService.SendMissedDigest(ctx, logs); err != nil {
+			logger.Errorf("发送错过提醒汇总失败 (UserID: %d): %v", userID, err)
+		}
+	}
+
+	return total
+}
+
+// sendPendingDigest 发送免打扰时段内推迟的错过提醒汇总
+func (s *schedulerService) sendPendingDigest(userID uint) {
+	s.mu.Lock()
+	logs := s.pendingDigests[userID]
+	delete(s.pendingDigests, userID)
+	s.mu.Unlock()
+
+	if len(logs) == 0 || !s.isLeader() {
+		return
+	}
+	if err := s.notificationService.SendMissedDigest(context.Background(), logs); err != nil {
+		logger.Errorf("发送错过提醒汇总失败 (UserID: %d): %v", userID, err)
+	}
+}
+
+// missedOccurrences 计算 (上次记录, now] 区间内应触发但没有记录的时间点
+func (s *schedulerService) missedOccurrences(ctx context.Context, reminder *models.Reminder, since, now time.Time) ([]time.Time, error) {
+	from := since
+	if reminder.CreatedAt.After(from) {
+		from = reminder.CreatedAt
+	}
+
+	// 延期和预先跳过的记录计划时间在将来，从不晚于 now 的最近一条记录起算
+	lastLogs, err := s.reminderLogRepo.GetByReminderID(ctx, reminder.ID, 10, 0)
+	if err != nil {
+		return nil, fmt.Errorf("获取最近提醒记录失败: %w", err)
+	}
+	for _, log := range lastLogs {
+		if log.ScheduledTime.After(now) {
+			continue
+		}
+		if log.ScheduledTime.After(from) {
+			from = log.ScheduledTime
+		}
+		break
+	}
+
+	if reminder.IsOnce() {
+		targets, err := s.onceTargetTimes(reminder)
+		if err != nil {
+			return nil, err
+		}
+		var occurrences []time.Time
+		for _, target := range targets {
+			if target.After(from) && !target.After(now) {
+				occurrences = append(occurrences, target)
+			}
+		}
+		return occurrences, nil
+	}
+
+	schedules, err := s.buildSchedules(reminder)
+	if err != nil {
+		return nil, err
+	}
+	schedule := multiSchedule(schedules)
+
+	var occurrences []time.Time
+	for next := schedule.Next(from); !next.IsZero() && !next.After(now); next = schedule.Next(next) {
+		occurrences = append(occurrences, next)
+		if len(occurrences) >= maxMissedOccurrences {
+			break
+		}
+	}
+	return occurrences, nil
+}
+
+// handleMissed 按补偿策略处理错过的时间点，返回需要汇总发送的记录和实际认领的次数
+func (s *schedulerService) handleMissed(ctx context.Context, reminder *models.Reminder, occurrences []time.Time) ([]*models.ReminderLog, int) {
+	var digest []*models.ReminderLog
+	claimedCount := 0
+
+	for i, at := range occurrences {
+		reminderLog := &models.ReminderLog{
+			ReminderID:    reminder.ID,
+			ScheduledTime: at,
+			OccurrenceAt:  models.OccurrenceKey(at),
+			Status:        models.ReminderStatusOverdue,
+		}
+
+		sendLate := s.missedPolicy == MissedPolicyLate && i == len(occurrences)-1
+		if sendLate {
+			reminderLog.Status = models.ReminderStatusPending
+		}
+
+		claimed, err := s.reminderLogRepo.Claim(ctx, reminderLog)
+		if err != nil {
+			logger.Errorf("创建错过提醒记录失败 (ID: %d): %v", reminder.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		claimedCount++
+		reminderLog.Reminder = *reminder
+
+		switch {
+		case sendLate:
+			if until, _, quiet := s.quietUntil(reminder, s.now()); quiet {
+				s.deferDelivery(ctx, reminderLog, until)
+				continue
+			}
+			if err := s.notificationService.SendReminder(ctx, reminderLog); err != nil {
+				logger.Errorf("补发提醒失败 (ID: %d): %v", reminder.ID, err)
+				continue
+			}
+			reminderLog.MarkAsSentAt(s.now())
+			if err := s.reminderLogRepo.Update(ctx, reminderLog); err != nil {
+				logger.Errorf("更新提醒记录失败 (ID: %d): %v", reminder.ID, err)
+			} else {
+				s.scheduleFollowUp(reminderLog)
+			}
+			logger.Infof("📮 已补发错过的提醒 (ID: %d, 原定时间: %s)", reminder.ID, at.Format(time.RFC3339))
+		case s.missedPolicy == MissedPolicyDigest:
+			digest = append(digest, reminderLog)
+		}
+	}
+
+	return digest, claimedCount
+}
+
+// onceExpired 判断一次性提醒的所有目标时间是否都已过
+func (s *schedulerService) onceExpired(reminder *models.Reminder, now time.Time) bool {
+	targets, err := s.onceTargetTimes(reminder)
+	if err != nil {
+		return false
+	}
+	for _, target := range targets {
+		if target.After(now) {
+			return false
+		}
+	}
+	return true
+}
+
+// onceTargetTimes 返回一次性提醒每个触发时间对应的目标时刻
+func (s *schedulerService) onceTargetTimes(reminder *models.Reminder) ([]time.Time, error) {
+	loc := s.resolveLocation(reminder)
+	var targets []time.Time
+	for _, targetTime := range reminder.Times() {
+		hour, minute, err := parseTargetTime(targetTime)
+		if err != nil {
+			return nil, err
+		}
+		target, err := s.onceTargetTime(reminder.SchedulePattern, hour, minute, loc)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// buildSchedules 为提醒的每个触发时间分别构建调度计划，设置了截止日期时截止后不再触发
+func (s *schedulerService) buildSchedules(reminder *models.Reminder) ([]cron.Schedule, error) {
+	return s.buildSchedulesFrom(reminder, s.lastOccurrence(reminder))
+}
+
+// buildSchedulesFrom 同 buildSchedules，间隔模式从已查得的最近一次触发 last 起算，不访问数据库
+func (s *schedulerService) buildSchedulesFrom(reminder *models.Reminder, last time.Time) ([]cron.Schedule, error) {
+	end, err := s.untilEnd(reminder)
+	if err != nil {
+		return nil, err
+	}
+
+	times := reminder.Times()
+	schedules := make([]cron.Schedule, 0, len(times))
+	for _, targetTime := range times {
+		schedule, err := s.buildScheduleFrom(withTargetTime(reminder, targetTime), last)
+		if err != nil {
+			return nil, err
+		}
+		if !end.IsZero() {
+			schedule = &untilSchedule{schedule: schedule, end: end}
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+// untilEnd 返回截止日期次日零点（提醒时区），未设置截止日期时返回零值
+func (s *schedulerService) untilEnd(reminder *models.Reminder) (time.Time, error) {
+	if reminder.UntilDate == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.ParseInLocation("2006-01-02", reminder.UntilDate, s.resolveLocation(reminder))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("无效的截止日期: %s", reminder.UntilDate)
+	}
+	return date.AddDate(0, 0, 1), nil
+}
+
+// reminderEnded 判断重复提醒是否已满足结束条件：次数已用完，或截止日期前已没有下一次触发
+func (s *schedulerService) reminderEnded(reminder *models.Reminder, now time.Time) bool {
+	if reminder.IsOnce() || !reminder.HasEndCondition() {
+		return false
+	}
+	if reminder.ReachedMaxOccurrences() {
+		return true
+	}
+	if reminder.UntilDate == "" {
+		return false
+	}
+
+	schedules, err := s.buildSchedules(reminder)
+	if err != nil {
+		return false
+	}
+	return multiSchedule(schedules).Next(now).IsZero()
+}
+
+// untilSchedule 在截止时间之前有效的调度计划
+type untilSchedule struct {
+	schedule cron.Schedule
+	end      time.Time
+}
+
+func (u *untilSchedule) Next(t time.Time) time.Time {
+	next := u.schedule.Next(t)
+	if next.IsZero() || !next.Before(u.end) {
+		return time.Time{}
+	}
+	return next
+}
+
+// withTargetTime 返回只替换了触发时间的提醒副本
+func withTargetTime(reminder *models.Reminder, targetTime string) *models.Reminder {
+	copied := *reminder
+	copied.TargetTime = targetTime
+	copied.ExtraTimes = ""
+	return &copied
+}
+
+// clockJob 由时钟定时器驱动的周期任务：每次触发后按调度计划设置下一次定时器
+type clockJob struct {
+	mu       sync.Mutex
+	clock    clock.Clock
+	schedule cron.Schedule
+	fn       func(occurrence time.Time)
+	next     time.Time
+	timer    clock.Timer
+	stopped  bool
+}
+
+func startClockJob(c clock.Clock, schedule cron.Schedule, fn func(occurrence time.Time)) *clockJob {
+	job := &clockJob{clock: c, schedule: schedule, fn: fn}
+	job.mu.Lock()
+	job.armLocked()
+	job.mu.Unlock()
+	return job
+}
+
+// armLocked 设置下一次触发的定时器，调度计划结束后不再设置
+func (j *clockJob) armLocked() {
+	now := j.clock.Now()
+	j.next = j.schedule.Next(now)
+	if j.next.IsZero() {
+		j.timer = nil
+		return
+	}
+	j.timer = j.clock.AfterFunc(j.next.Sub(now), j.run)
+}
+
+func (j *clockJob) run() {
+	j.mu.Lock()
+	if j.stopped {
+		j.mu.Unlock()
+		return
+	}
+	occurrence := j.next
+	j.mu.Unlock()
+
+	j.fn(occurrence)
+
+	j.mu.Lock()
+	if !j.stopped {
+		j.armLocked()
+	}
+	j.mu.Unlock()
+}
+
+// Stop 取消后续触发
+func (j *clockJob) Stop() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.stopped = true
+	if j.timer != nil {
+		j.timer.Stop()
+	}
+}
+
+// multiSchedule 合并多个调度计划，Next 取其中最早的触发时间
+type multiSchedule []cron.Schedule
+
+func (m multiSchedule) Next(t time.Time) time.Time {
+	var earliest time.Time
+	for _, schedule := range m {
+		next := schedule.Next(t)
+		if !next.IsZero() && (earliest.IsZero() || next.Before(earliest)) {
+			earliest = next
+		}
+	}
+	return earliest
+}
+
+// buildSchedule 根据提醒配置构建带时区的调度计划
+func (s *schedulerService) buildSchedule(reminder *models.Reminder) (cron.Schedule, error) {
+	return s.buildScheduleFrom(reminder, s.lastOccurrence(reminder))
+}
+
+// buildScheduleFrom 同 buildSchedule，last 为间隔模式的最近一次触发时刻，零值表示从未触发
+func (s *schedulerService) buildScheduleFrom(reminder *models.Reminder, last time.Time) (cron.Schedule, error) {
+	if reminder.IsMonthly() || reminder.IsYearly() {
+		return s.buildCalendarSchedule(reminder)
+	}
+	if reminder.IsInterval() {
+		return s.buildIntervalSchedule(reminder, last)
+	}
+	if reminder.IsRRule() {
+		return s.buildRRuleSchedule(reminder)
+	}
+	if reminder.IsWorkday() {
+		return s.buildWorkdaySchedule(reminder)
+	}
+
+	cronExpr, err := s.buildCronExpression(reminder)
+	if err != nil {
+		return nil, fmt.Errorf("构建cron表达式失败: %w", err)
+	}
+
+	schedule, err := cron.ParseStandard(cronExpr)
+	if err != nil {
+		return nil, fmt.Errorf("解析cron表达式失败: %w", err)
+	}
+
+	// 在提醒自身的时区内计算触发时间，夏令时切换由 cron 按本地时间处理
+	if spec, ok := schedule.(*cron.SpecSchedule); ok {
+		spec.Location = s.resolveLocation(reminder)
+	}
+
+	return schedule, nil
+}
+
+// buildCalendarSchedule 构建每月/每年模式的调度计划
+// 标准cron无法表达"最后一天""第N个星期几"，因此按日历规则逐日计算
+func (s *schedulerService) buildCalendarSchedule(reminder *models.Reminder) (cron.Schedule, error) {
+	rule, err := models.ParseCalendarPattern(reminder.SchedulePattern)
+	if err != nil {
+		return nil, err
+	}
+
+	hour, minute, err := parseTargetTime(reminder.TargetTime)
+	if err != nil {
+		return nil, err
 	}
+
+	return &calendarSchedule{
+		rule:     rule,
+		hour:     hour,
+		minute:   minute,
+		location: s.resolveLocation(reminder),
+	}, nil
 }
 
-func (s *schedulerService) Start() error {
-	logger.Info("🕰️ 定时调度器启动中...")
+// calendarSearchDays 向后查找触发日期的最大天数，覆盖2月29日等跨多年的情况
+const calendarSearchDays = 366 * 8
 
-	// 启动cron调度器
-	s.cron.Start()
+// calendarSchedule 按日历规则触发的调度计划，实现 cron.Schedule
+type calendarSchedule struct {
+	rule     *models.CalendarRule
+	hour     int
+	minute   int
+	location *time.Location
+}
 
-	// 从数据库恢复所有有效提醒
-	ctx := context.Background()
-	reminders, err := s.reminderRepo.GetActiveReminders(ctx)
-	if err != nil {
-		return fmt.Errorf("获取有效提醒失败: %w", err)
-	}
+// Next 返回 t 之后第一个满足规则的触发时间，找不到时返回零值（cron 不再调度）
+func (c *calendarSchedule) Next(t time.Time) time.Time {
+	local := t.In(c.location)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, c.location)
 
-	// 为每个提醒添加调度任务
-	for _, reminder := range reminders {
-		if err := s.AddReminder(reminder); err != nil {
-			logger.Errorf("添加提醒调度失败 (ID: %d): %v", reminder.ID, err)
+	for i := 0; i <= calendarSearchDays; i++ {
+		date := day.AddDate(0, 0, i)
+		if !c.rule.Matches(date) {
 			continue
 		}
+
+		next := time.Date(date.Year(), date.Month(), date.Day(), c.hour, c.minute, 0, 0, c.location)
+		if next.After(t) {
+			return next
+		}
 	}
 
-	logger.Infof("✅ 定时调度器启动成功，已加载 %d 个提醒", len(reminders))
-	return nil
+	return time.Time{}
 }
 
-func (s *schedulerService) Stop() error {
-	logger.Info("🔄 定时调度器停止中...")
-	s.cron.Stop()
-	s.mu.Lock()
-	for id, timer := range s.onceTimers {
-		if timer != nil {
-			timer.Stop()
-		}
-		delete(s.onceTimers, id)
+// buildIntervalSchedule 构建间隔模式的调度计划
+func (s *schedulerService) buildIntervalSchedule(reminder *models.Reminder, last time.Time) (cron.Schedule, error) {
+	rule, err := models.ParseIntervalPattern(reminder.SchedulePattern)
+	if err != nil {
+		return nil, err
 	}
-	s.jobs = make(map[uint]cron.EntryID)
-	s.mu.Unlock()
-	logger.Info("✅ 定时调度器已停止")
-	return nil
+
+	hour, minute, err := parseTargetTime(reminder.TargetTime)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := s.resolveLocation(reminder)
+	return &intervalSchedule{
+		rule:     rule,
+		hour:     hour,
+		minute:   minute,
+		anchor:   s.intervalAnchor(reminder, last, hour, minute, loc),
+		location: loc,
+	}, nil
 }
 
-func (s *schedulerService) AddReminder(reminder *models.Reminder) error {
-	if reminder == nil {
-		return fmt.Errorf("提醒信息不能为空")
+// intervalAnchor 计算间隔的起算点：优先取最近一次触发时间 last，否则取创建当天的目标时间
+func (s *schedulerService) intervalAnchor(reminder *models.Reminder, last time.Time, hour, minute int, loc *time.Location) time.Time {
+	if !last.IsZero() {
+		return last.In(loc).Truncate(time.Minute)
 	}
 
-	if !reminder.IsActive {
-		return fmt.Errorf("提醒未激活，无法添加调度")
+	created := reminder.CreatedAt
+	if created.IsZero() {
+		created = s.now()
 	}
+	created = created.In(loc)
+	return time.Date(created.Year(), created.Month(), created.Day(), hour, minute, 0, 0, loc)
+}
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// lastOccurrence 间隔模式提醒最近一次已到期的计划触发时刻，没有时返回零值
+// 延期记录没有计划触发时刻，不作为起算点；会查询数据库，调用方不能持有 mu
+func (s *schedulerService) lastOccurrence(reminder *models.Reminder) time.Time {
+	if !reminder.IsInterval() || reminder.ID == 0 || s.reminderLogRepo == nil {
+		return time.Time{}
+	}
 
-	// 如果存在旧的定时器/任务，先清理
-	s.clearReminderLocked(reminder.ID)
+	logs, err := s.reminderLogRepo.GetByReminderID(context.Background(), reminder.ID, 10, 0)
+	if err != nil {
+		logger.Warnf("获取最近触发记录失败，使用创建时间起算 (ID: %d): %v", reminder.ID, err)
+		return time.Time{}
+	}
 
-	if reminder.IsPaused() {
-		logger.Debugf("⏸️ 提醒处于暂停状态，跳过调度: ID=%d", reminder.ID)
-		return nil
+	now := s.now()
+	var last time.Time
+	for _, log := range logs {
+		if log.OccurrenceAt == nil || log.OccurrenceAt.After(now) {
+			continue
+		}
+		if log.OccurrenceAt.After(last) {
+			last = *log.OccurrenceAt
+		}
 	}
+	return last
+}
 
-	if reminder.IsOnce() {
-		return s.addOnceReminderLocked(reminder)
+// intervalSchedule 按固定间隔触发的调度计划，实现 cron.Schedule
+//   - 按小时且带时间窗口：每天从窗口开始时间起每隔 N 小时触发，直到窗口结束
+//   - 按小时：从起算点起每隔 N 小时触发（按实际经过时间，不受夏令时影响）
+//   - 按天/周：从起算点所在日期起每隔 N 天/周，在目标时间触发
+type intervalSchedule struct {
+	rule     *models.IntervalRule
+	hour     int
+	minute   int
+	anchor   time.Time
+	location *time.Location
+}
+
+// Next 返回 t 之后的下一次触发时间
+func (i *intervalSchedule) Next(t time.Time) time.Time {
+	local := t.In(i.location)
+
+	switch i.rule.Unit {
+	case models.IntervalHour:
+		if i.rule.HasWindow {
+			step := i.rule.Every * 60
+			for day := 0; day <= 1; day++ {
+				for slot := i.rule.WindowStart; slot <= i.rule.WindowEnd; slot += step {
+					next := time.Date(local.Year(), local.Month(), local.Day()+day, slot/60, slot%60, 0, 0, i.location)
+					if next.After(t) {
+						return next
+					}
+				}
+			}
+			return time.Time{}
+		}
+
+		interval := time.Duration(i.rule.Every) * time.Hour
+		steps := t.Sub(i.anchor) / interval
+		next := i.anchor.Add(steps * interval)
+		for !next.After(t) {
+			next = next.Add(interval)
+		}
+		for next.Add(-interval).After(t) {
+			next = next.Add(-interval)
+		}
+		return next
+
+	default:
+		stepDays := i.rule.Every
+		if i.rule.Unit == models.IntervalWeek {
+			stepDays *= 7
+		}
+
+		anchorDate := civilDays(i.anchor.In(i.location))
+		k := floorDiv(civilDays(local)-anchorDate, stepDays)
+		for ; ; k++ {
+			date := i.anchor.In(i.location).AddDate(0, 0, k*stepDays)
+			next := time.Date(date.Year(), date.Month(), date.Day(), i.hour, i.minute, 0, 0, i.location)
+			if next.After(t) {
+				return next
+			}
+		}
 	}
+}
 
-	cronExpr, err := s.buildCronExpression(reminder)
+// civilDays 返回本地日期距 1970-01-01 的天数，用于计算日期差
+func civilDays(t time.Time) int {
+	return int(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+// buildRRuleSchedule 构建 RRULE 规则的调度计划，DTSTART 为创建当天的目标时间
+func (s *schedulerService) buildRRuleSchedule(reminder *models.Reminder) (cron.Schedule, error) {
+	set, err := rrule.ParseSet(strings.TrimPrefix(reminder.SchedulePattern, string(models.SchedulePatternRRule)))
 	if err != nil {
-		return fmt.Errorf("构建cron表达式失败: %w", err)
+		return nil, fmt.Errorf("无效的RRULE: %w", err)
 	}
 
-	entryID, err := s.cron.AddFunc(cronExpr, func() {
-		s.executeReminder(reminder.ID)
-	})
+	hour, minute, err := parseTargetTime(reminder.TargetTime)
 	if err != nil {
-		return fmt.Errorf("添加cron任务失败: %w", err)
+		return nil, err
 	}
 
-	s.jobs[reminder.ID] = entryID
+	loc := s.resolveLocation(reminder)
+	created := reminder.CreatedAt
+	if created.IsZero() {
+		created = s.now()
+	}
+	created = created.In(loc)
 
-	logger.Debugf("📅 添加提醒调度: ID=%d, Cron=%s", reminder.ID, cronExpr)
-	return nil
+	return &rruleSchedule{
+		set:     set,
+		dtstart: time.Date(created.Year(), created.Month(), created.Day(), hour, minute, 0, 0, loc),
+	}, nil
 }
 
-func (s *schedulerService) RemoveReminder(reminderID uint) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// rruleSchedule 按 RRULE 展开触发时间的调度计划，实现 cron.Schedule
+type rruleSchedule struct {
+	set     *rrule.Set
+	dtstart time.Time
+}
 
-	removed := s.clearReminderLocked(reminderID)
+// Next 返回 t 之后的下一次触发时间，规则结束（COUNT/UNTIL）后返回零值
+func (r *rruleSchedule) Next(t time.Time) time.Time {
+	return r.set.Next(r.dtstart, t)
+}
 
-	if removed {
-		logger.Debugf("🗑️ 移除提醒调度: ID=%d", reminderID)
-		return nil
+// buildWorkdaySchedule 构建法定工作日模式的调度计划
+func (s *schedulerService) buildWorkdaySchedule(reminder *models.Reminder) (cron.Schedule, error) {
+	hour, minute, err := parseTargetTime(reminder.TargetTime)
+	if err != nil {
+		return nil, err
 	}
 
-	return fmt.Errorf("提醒调度不存在: %d", reminderID)
+	return &workdaySchedule{
+		scheduler: s,
+		region:    s.workdayRegion(reminder),
+		hour:      hour,
+		minute:    minute,
+		location:  s.resolveLocation(reminder),
+	}, nil
 }
 
-func (s *schedulerService) RefreshSchedules() error {
-	logger.Info("🔄 刷新所有调度任务...")
+// workdaySearchDays 向后查找工作日的最大天数，足以跨过最长的节假日
+const workdaySearchDays = 60
+
+// workdaySchedule 按节假日日历触发的调度计划，实现 cron.Schedule
+// 每次计算时查询调度器当前的日历，日历重新加载后无需重建
+type workdaySchedule struct {
+	scheduler *schedulerService
+	region    string
+	hour      int
+	minute    int
+	location  *time.Location
+}
 
-	// 停止所有现有任务
-	s.mu.Lock()
-	for id, entry := range s.jobs {
-		s.cron.Remove(entry)
-		delete(s.jobs, id)
-	}
-	for id, timer := range s.onceTimers {
-		if timer != nil {
-			timer.Stop()
+// Next 返回 t 之后第一个工作日的触发时间
+func (w *workdaySchedule) Next(t time.Time) time.Time {
+	calendar := w.scheduler.holidayCalendar()
+	local := t.In(w.location)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, w.location)
+
+	for i := 0; i <= workdaySearchDays; i++ {
+		date := day.AddDate(0, 0, i)
+		if !calendar.IsWorkday(w.region, date) {
+			continue
+		}
+
+		next := time.Date(date.Year(), date.Month(), date.Day(), w.hour, w.minute, 0, 0, w.location)
+		if next.After(t) {
+			return next
 		}
-		delete(s.onceTimers, id)
 	}
-	s.mu.Unlock()
 
-	// 重新加载所有有效提醒
-	ctx := context.Background()
-	reminders, err := s.reminderRepo.GetActiveReminders(ctx)
-	if err != nil {
-		return fmt.Errorf("获取有效提醒失败: %w", err)
+	return time.Time{}
+}
+
+// holidayCalendar 返回当前的节假日日历
+func (s *schedulerService) holidayCalendar() holiday.Calendar {
+	s.holidayMu.RLock()
+	defer s.holidayMu.RUnlock()
+	if s.holidays == nil {
+		return holiday.Weekdays
 	}
+	return s.holidays
+}
 
-	// 重新添加所有任务
-	for _, reminder := range reminders {
-		if err := s.AddReminder(reminder); err != nil {
-			logger.Errorf("重新添加提醒调度失败 (ID: %d): %v", reminder.ID, err)
+// workdayRegion 返回工作日提醒使用的节假日地区：提醒指定的地区 > 默认地区
+func (s *schedulerService) workdayRegion(reminder *models.Reminder) string {
+	if region := reminder.WorkdayRegion(); region != "" {
+		return region
+	}
+	s.holidayMu.RLock()
+	defer s.holidayMu.RUnlock()
+	return s.holidayRegion
+}
+
+// isWorkdayNow 判断工作日提醒在其时区的当天是否为工作日
+func (s *schedulerService) isWorkdayNow(reminder *models.Reminder, now time.Time) bool {
+	return s.holidayCalendar().IsWorkday(s.workdayRegion(reminder), now.In(s.resolveLocation(reminder)))
+}
+
+// resolveLocation 解析提醒使用的时区：提醒时区 > 用户时区 > 默认时区
+func (s *schedulerService) resolveLocation(reminder *models.Reminder) *time.Location {
+	for _, name := range []string{reminder.Timezone, reminder.User.Timezone} {
+		if name == "" {
 			continue
 		}
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			logger.Warnf("提醒时区无效，继续回退 (ID: %d, 时区: %s): %v", reminder.ID, name, err)
+			continue
+		}
+		return loc
 	}
 
-	s.mu.RLock()
-	activeJobs := len(s.jobs) + len(s.onceTimers)
-	s.mu.RUnlock()
-
-	logger.Infof("✅ 调度任务刷新完成，当前活跃任务: %d", activeJobs)
-	return nil
+	if s.location != nil {
+		return s.location
+	}
+	return time.Local
 }
 
 // buildCronExpression 根据提醒配置构建cron表达式
@@ -224,6 +1904,26 @@ func (s *schedulerService) buildCronExpression(reminder *models.Reminder) (strin
 	}
 }
 
+// parseTargetTime 解析 "HH:MM[:SS]" 格式的目标时间
+func parseTargetTime(targetTime string) (int, int, error) {
+	timeParts := strings.Split(targetTime, ":")
+	if len(timeParts) < 2 {
+		return 0, 0, fmt.Errorf("无效的时间格式: %s", targetTime)
+	}
+
+	hour, err := strconv.Atoi(timeParts[0])
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, 0, fmt.Errorf("无效的小时: %s", timeParts[0])
+	}
+
+	minute, err := strconv.Atoi(timeParts[1])
+	if err != nil || minute < 0 || minute > 59 {
+		return 0, 0, fmt.Errorf("无效的分钟: %s", timeParts[1])
+	}
+
+	return hour, minute, nil
+}
+
 // parseWeeklyPattern 解析每周模式 "weekly:1,3,5"
 func (s *schedulerService) parseWeeklyPattern(pattern string) ([]string, error) {
 	if !strings.HasPrefix(pattern, "weekly:") {
@@ -245,47 +1945,96 @@ func (s *schedulerService) parseWeeklyPattern(pattern string) ([]string, error)
 }
 
 func (s *schedulerService) addOnceReminderLocked(reminder *models.Reminder) error {
-	timeParts := strings.Split(reminder.TargetTime, ":")
-	if len(timeParts) < 2 {
-		return fmt.Errorf("无效的时间格式: %s", reminder.TargetTime)
+	var timers []clock.Timer
+	var lastErr error
+
+	// 多个触发时间时跳过已过去的时间点，只要还有未到的时间就保留调度
+	for _, targetTimeStr := range reminder.Times() {
+		timeParts := strings.Split(targetTimeStr, ":")
+		if len(timeParts) < 2 {
+			stopTimers(timers)
+			return fmt.Errorf("无效的时间格式: %s", targetTimeStr)
+		}
+
+		hour, err := strconv.Atoi(timeParts[0])
+		if err != nil {
+			stopTimers(timers)
+			return fmt.Errorf("无效的小时: %s", timeParts[0])
+		}
+
+		minute, err := strconv.Atoi(timeParts[1])
+		if err != nil {
+			stopTimers(timers)
+			return fmt.Errorf("无效的分钟: %s", timeParts[1])
+		}
+
+		targetTime, err := s.parseOnceTargetTime(reminder.SchedulePattern, hour, minute, s.resolveLocation(reminder))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		delay := targetTime.Sub(s.now())
+		if delay <= 0 {
+			lastErr = fmt.Errorf("目标时间已过期: %v", targetTime)
+			continue
+		}
+
+		reminderID := reminder.ID
+		timers = append(timers, s.afterFunc(delay, func() {
+			s.dispatchReminder(reminderID, targetTime)
+		}))
+		// 已经过了的提前通知不再补发
+		for _, offset := range reminder.LeadOffsets() {
+			if leadDelay := delay - offset; leadDelay > 0 {
+				timers = append(timers, s.afterFunc(leadDelay, func() {
+					s.dispatchLead(reminderID, targetTime, offset)
+				}))
+			}
+		}
+		logger.Debugf("⏰ 一次性提醒定时器已创建: ID=%d, 触发时间=%s", reminder.ID, targetTime.Format(time.RFC3339))
 	}
 
-	hour, err := strconv.Atoi(timeParts[0])
-	if err != nil {
-		return fmt.Errorf("无效的小时: %s", timeParts[0])
+	if len(timers) == 0 {
+		return lastErr
 	}
 
-	minute, err := strconv.Atoi(timeParts[1])
-	if err != nil {
-		return fmt.Errorf("无效的分钟: %s", timeParts[1])
+	s.onceTimers[reminder.ID] = timers
+	return nil
+}
+
+func stopTimers(timers []clock.Timer) {
+	for _, timer := range timers {
+		if timer != nil {
+			timer.Stop()
+		}
 	}
+}
 
-	targetTime, err := s.parseOnceTargetTime(reminder.SchedulePattern, hour, minute)
+func (s *schedulerService) parseOnceTargetTime(pattern string, hour, minute int, loc *time.Location) (time.Time, error) {
+	targetTime, err := s.onceTargetTime(pattern, hour, minute, loc)
 	if err != nil {
-		return err
+		return time.Time{}, err
 	}
 
-	delay := time.Until(targetTime)
-	if delay <= 0 {
-		return fmt.Errorf("目标时间已过期: %v", targetTime)
+	if loc == nil {
+		loc = time.Local
+	}
+	currentTime := s.now().In(loc)
+	if !targetTime.After(currentTime) {
+		return time.Time{}, fmt.Errorf("目标时间已过期: %v", targetTime)
 	}
 
-	timer := time.AfterFunc(delay, func() {
-		s.executeReminder(reminder.ID)
-	})
-
-	s.onceTimers[reminder.ID] = timer
-	logger.Debugf("⏰ 一次性提醒定时器已创建: ID=%d, 触发时间=%s", reminder.ID, targetTime.Format(time.RFC3339))
-	return nil
+	return targetTime, nil
 }
 
-func (s *schedulerService) parseOnceTargetTime(pattern string, hour, minute int) (time.Time, error) {
+// onceTargetTime 解析一次性提醒的目标时间，不检查是否已过期
+func (s *schedulerService) onceTargetTime(pattern string, hour, minute int, loc *time.Location) (time.Time, error) {
 	if !strings.HasPrefix(pattern, string(models.SchedulePatternOnce)) {
 		return time.Time{}, fmt.Errorf("无效的一次性模式: %s", pattern)
 	}
 
 	dateStr := strings.TrimPrefix(pattern, string(models.SchedulePatternOnce))
-	loc := s.location
 	if loc == nil {
 		loc = time.Local
 	}
@@ -295,38 +2044,47 @@ func (s *schedulerService) parseOnceTargetTime(pattern string, hour, minute int)
 		return time.Time{}, fmt.Errorf("无效的日期格式: %s", dateStr)
 	}
 
-	targetTime := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, loc)
-	currentTime := time.Now().In(loc)
-	if !targetTime.After(currentTime) {
-		return time.Time{}, fmt.Errorf("目标时间已过期: %v", targetTime)
-	}
-
-	return targetTime, nil
+	return time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, loc), nil
 }
 
 func (s *schedulerService) clearReminderLocked(reminderID uint) bool {
 	removed := false
 
-	if entryID, exists := s.jobs[reminderID]; exists {
-		s.cron.Remove(entryID)
+	if entryIDs, exists := s.jobs[reminderID]; exists {
+		for _, entryID := range entryIDs {
+			s.cron.Remove(entryID)
+		}
 		delete(s.jobs, reminderID)
 		removed = true
 	}
 
-	if timer, exists := s.onceTimers[reminderID]; exists {
-		if timer != nil {
-			timer.Stop()
-		}
+	if timers, exists := s.onceTimers[reminderID]; exists {
+		stopTimers(timers)
 		delete(s.onceTimers, reminderID)
 		removed = true
 	}
 
+	if jobs, exists := s.clockJobs[reminderID]; exists {
+		for _, job := range jobs {
+			job.Stop()
+		}
+		delete(s.clockJobs, reminderID)
+		removed = true
+	}
+
+	if timer, exists := s.resumeTimers[reminderID]; exists {
+		timer.Stop()
+		delete(s.resumeTimers, reminderID)
+		removed = true
+	}
+
+	delete(s.versions, reminderID)
 	return removed
 }
 
 // buildOnceExpression 构建一次性提醒表达式
 func (s *schedulerService) buildOnceExpression(pattern string, hour, minute int) (string, error) {
-	targetTime, err := s.parseOnceTargetTime(pattern, hour, minute)
+	targetTime, err := s.parseOnceTargetTime(pattern, hour, minute, s.location)
 	if err != nil {
 		return "", err
 	}
@@ -335,11 +2093,90 @@ func (s *schedulerService) buildOnceExpression(pattern string, hour, minute int)
 	return fmt.Sprintf("%02d %d %d %d *", minute, hour, targetTime.Day(), int(targetTime.Month())), nil
 }
 
-// executeReminder 执行提醒任务
-func (s *schedulerService) executeReminder(reminderID uint) {
+// dispatchReminder 通过工作线程池执行提醒任务
+func (s *schedulerService) dispatchReminder(reminderID uint, occurrence time.Time) {
+	s.dispatch(fmt.Sprintf("执行提醒 %d", reminderID), func() {
+		s.executeReminder(reminderID, occurrence)
+	})
+}
+
+func (s *schedulerService) dispatchLead(reminderID uint, occurrence time.Time, offset time.Duration) {
+	s.dispatch(fmt.Sprintf("提前通知 %d", reminderID), func() {
+		s.executeLead(reminderID, occurrence, offset)
+	})
+}
+
+// executeLead 发送计划时刻 occurrence 的提前通知
+// 提前通知只是提示，不创建提醒记录，不影响触发次数和完成统计；本次触发不会发生时（已停用、暂停、非工作日）不发送
+func (s *schedulerService) executeLead(reminderID uint, occurrence time.Time, offset time.Duration) {
+	ctx := context.Background()
+
+	if !s.isLeader() {
+		return
+	}
+
+	reminder, err := s.reminderRepo.GetByID(ctx, reminderID)
+	if err != nil {
+		logger.Errorf("获取提醒失败 (ID: %d): %v", reminderID, err)
+		return
+	}
+	if reminder == nil || !reminder.IsActive || reminder.IsPausedAt(occurrence) || reminder.ReachedMaxOccurrences() {
+		return
+	}
+	if reminder.IsWorkday() && !s.isWorkdayNow(reminder, occurrence) {
+		return
+	}
+	// 已在日程中跳过的触发不再提前通知
+	if s.occurrenceClaimed(ctx, reminderID, occurrence) {
+		return
+	}
+
+	// 免打扰时段内直接丢弃：推迟后可能已经临近甚至晚于正式提醒
+	if _, _, quiet := s.quietUntil(reminder, s.now()); quiet {
+		logger.Infof("🌙 免打扰时段内跳过提前通知 (ID: %d, 提前: %s)", reminderID, models.DescribeLeadTime(offset))
+		return
+	}
+
+	if err := s.notificationService.SendLeadNotice(ctx, reminder, occurrence, offset); err != nil {
+		logger.Errorf("发送提前通知失败 (ID: %d): %v", reminderID, err)
+	}
+}
+
+// leadSchedule 比原调度计划提前 offset 触发的调度计划
+type leadSchedule struct {
+	schedule cron.Schedule
+	offset   time.Duration
+}
+
+func (l leadSchedule) Next(t time.Time) time.Time {
+	next := l.schedule.Next(t.Add(l.offset))
+	if next.IsZero() {
+		return next
+	}
+	return next.Add(-l.offset)
+}
+
+// occurrenceAt 推算本次触发对应的计划时刻
+// cron 按计划时刻唤醒，实际执行会有毫秒级延迟；同一次触发无论由哪个任务执行都应得到相同的时刻
+func occurrenceAt(schedule cron.Schedule, fired time.Time) time.Time {
+	if planned := schedule.Next(fired.Add(-occurrenceTolerance)); !planned.IsZero() && !planned.After(fired) {
+		return planned
+	}
+	return fired.Truncate(time.Second)
+}
+
+// executeReminder 执行一次计划触发：先按 (提醒, 计划时刻) 认领提醒记录，认领成功才发送
+// 同一次触发被重复调度（刷新调度、编辑提醒与正在运行的任务并发）或重试时不会重复通知
+func (s *schedulerService) executeReminder(reminderID uint, occurrence time.Time) {
 	ctx := context.Background()
 
-	logger.Debugf("⏰ 执行提醒任务: ID=%d", reminderID)
+	logger.Debugf("⏰ 执行提醒任务: ID=%d, 计划时间=%s", reminderID, occurrence.Format(time.RFC3339))
+
+	// 多实例部署时只有主实例触发，备用实例接管时会补偿错过的提醒
+	if !s.isLeader() {
+		logger.Debugf("🪑 备用实例跳过提醒任务: ID=%d", reminderID)
+		return
+	}
 
 	// 获取提醒详情
 	reminder, err := s.reminderRepo.GetByID(ctx, reminderID)
@@ -353,17 +2190,35 @@ func (s *schedulerService) executeReminder(reminderID uint) {
 		return
 	}
 
-	// 创建提醒记录
+	// 已到截止日期或次数用完的重复提醒不再发送
+	if reminder.ReachedMaxOccurrences() || s.reminderEnded(reminder, s.now()) {
+		s.finishReminder(ctx, reminder)
+		return
+	}
+
+	// 触发时再次确认日历，避免日历更新前已排好的任务在节假日发送
+	if reminder.IsWorkday() && !s.isWorkdayNow(reminder, s.now()) {
+		logger.Infof("🏖️ 今天不是工作日，跳过提醒 (ID: %d)", reminderID)
+		return
+	}
+
+	// 认领本次触发，已有记录说明其他任务已处理
 	reminderLog := &models.ReminderLog{
 		ReminderID:    reminderID,
-		ScheduledTime: time.Now(),
+		ScheduledTime: occurrence,
+		OccurrenceAt:  models.OccurrenceKey(occurrence),
 		Status:        models.ReminderStatusPending,
 	}
 
-	if err := s.reminderLogRepo.Create(ctx, reminderLog); err != nil {
+	claimed, err := s.reminderLogRepo.Claim(ctx, reminderLog)
+	if err != nil {
 		logger.Errorf("创建提醒记录失败 (ID: %d): %v", reminderID, err)
 		return
 	}
+	if !claimed {
+		logger.Infof("🔁 本次触发已处理，跳过重复执行 (ID: %d, 计划时间: %s)", reminderID, occurrence.Format(time.RFC3339))
+		return
+	}
 
 	// 重新加载提醒记录，确保包含提醒与用户信息
 	if reminderLog, err = s.reminderLogRepo.GetByID(ctx, reminderLog.ID); err != nil {
@@ -375,20 +2230,28 @@ func (s *schedulerService) executeReminder(reminderID uint) {
 		return
 	}
 
-	// 发送提醒通知
-	if err := s.notificationService.SendReminder(ctx, reminderLog); err != nil {
-		logger.Errorf("发送提醒通知失败 (ID: %d): %v", reminderID, err)
-		return
+	if until, _, quiet := s.quietUntil(reminder, s.now()); quiet {
+		// 免打扰时段内推迟到时段结束后投递，本次触发照常计数
+		s.deferDelivery(ctx, reminderLog, until)
+	} else {
+		// 发送提醒通知，失败时本次触发已认领，交给投递队列重试并照常计数
+		if err := s.notificationService.SendReminder(ctx, reminderLog); err != nil {
+			s.retryDelivery(ctx, reminderLog, err)
+		} else {
+			// 更新提醒记录状态
+			reminderLog.MarkAsSentAt(s.now())
+			if err := s.reminderLogRepo.Update(ctx, reminderLog); err != nil {
+				logger.Errorf("更新提醒记录失败 (ID: %d): %v", reminderID, err)
+			} else {
+				s.scheduleFollowUp(reminderLog)
+			}
+		}
 	}
 
-	// 更新提醒记录状态
-	reminderLog.MarkAsSent()
-	if err := s.reminderLogRepo.Update(ctx, reminderLog); err != nil {
-		logger.Errorf("更新提醒记录失败 (ID: %d): %v", reminderID, err)
-	}
+	reminder.OccurrenceCount++
 
-	// 如果是一次性提醒，完成后禁用
-	if reminder.IsOnce() {
+	// 如果是一次性提醒，最后一个时间点触发后禁用
+	if reminder.IsOnce() && s.onceExpired(reminder, s.now()) {
 		reminder.IsActive = false
 		if err := s.reminderRepo.Update(ctx, reminder); err != nil {
 			logger.Errorf("禁用一次性提醒失败 (ID: %d): %v", reminderID, err)
@@ -396,5 +2259,28 @@ func (s *schedulerService) executeReminder(reminderID uint) {
 			s.RemoveReminder(reminderID)
 			logger.Infof("✅ 一次性提醒已完成并禁用 (ID: %d)", reminderID)
 		}
+		return
+	}
+
+	// 重复提醒最后一次触发后自动停用
+	if s.reminderEnded(reminder, s.now()) {
+		s.finishReminder(ctx, reminder)
+		return
+	}
+
+	// 只写触发次数，不刷新 updated_at，否则每次触发后的同步都会重建该提醒的调度
+	if err := s.reminderRepo.UpdateOccurrenceCount(ctx, reminderID, reminder.OccurrenceCount); err != nil {
+		logger.Errorf("更新提醒触发次数失败 (ID: %d): %v", reminderID, err)
+	}
+}
+
+// finishReminder 停用已满足结束条件的重复提醒
+func (s *schedulerService) finishReminder(ctx context.Context, reminder *models.Reminder) {
+	reminder.IsActive = false
+	if err := s.reminderRepo.Update(ctx, reminder); err != nil {
+		logger.Errorf("停用已结束的提醒失败 (ID: %d): %v", reminder.ID, err)
+		return
 	}
+	s.RemoveReminder(reminder.ID)
+	logger.Infof("🏁 提醒已到结束条件并停用 (ID: %d, 已触发 %d 次)", reminder.ID, reminder.OccurrenceCount)
 }
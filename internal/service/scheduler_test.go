@@ -3,18 +3,34 @@ package service
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/robfig/cron/v3"
 
 	"mmemory/internal/models"
+	"mmemory/pkg/clock"
+	"mmemory/pkg/holiday"
 )
 
 // Mock NotificationService for testing
 type mockNotificationService struct {
 	sentReminders []uint
 	sentFollowUps []uint
+	finalFollowUp []uint // 标记为最后一次关怀的记录
+	sentDigests   []int
+	sentResumed   []uint
+	sentLeads     []sentLead
+	sentAgendas   []*Agenda
+	failReminders int // 接下来发送提醒失败的次数
+}
+
+type sentLead struct {
+	reminderID uint
+	occurrence time.Time
+	offset     time.Duration
 }
 
 func newMockNotificationService() *mockNotificationService {
@@ -25,12 +41,43 @@ func newMockNotificationService() *mockNotificationService {
 }
 
 func (m *mockNotificationService) SendReminder(ctx context.Context, log *models.ReminderLog) error {
+	if m.failReminders > 0 {
+		m.failReminders--
+		return fmt.Errorf("mock send error")
+	}
 	m.sentReminders = append(m.sentReminders, log.ID)
 	return nil
 }
 
-func (m *mockNotificationService) SendFollowUp(ctx context.Context, log *models.ReminderLog) error {
+func (m *mockNotificationService) SendFollowUp(ctx context.Context, log *models.ReminderLog, final bool) error {
 	m.sentFollowUps = append(m.sentFollowUps, log.ID)
+	if final {
+		m.finalFollowUp = append(m.finalFollowUp, log.ID)
+	}
+	return nil
+}
+
+func (m *mockNotificationService) SendMissedDigest(ctx context.Context, logs []*models.ReminderLog) error {
+	m.sentDigests = append(m.sentDigests, len(logs))
+	return nil
+}
+
+func (m *mockNotificationService) SendResumed(ctx context.Context, reminder *models.Reminder) error {
+	m.sentResumed = append(m.sentResumed, reminder.ID)
+	return nil
+}
+
+func (m *mockNotificationService) SendLeadNotice(ctx context.Context, reminder *models.Reminder, occurrence time.Time, offset time.Duration) error {
+	m.sentLeads = append(m.sentLeads, sentLead{reminderID: reminder.ID, occurrence: occurrence, offset: offset})
+	return nil
+}
+
+func (m *mockNotificationService) CloseMessages(ctx context.Context, logID uint, text string, skipMessageID int) error {
+	return nil
+}
+
+func (m *mockNotificationService) SendAgenda(ctx context.Context, agenda *Agenda) error {
+	m.sentAgendas = append(m.sentAgendas, agenda)
 	return nil
 }
 
@@ -68,7 +115,7 @@ func TestSchedulerService_CronExpression(t *testing.T) {
 		{
 			name: "一次性提醒",
 			reminder: &models.Reminder{
-				SchedulePattern: "once:2025-12-25",
+				SchedulePattern: "once:2099-12-25",
 				TargetTime:      "10:30:00",
 			},
 			wantExpr: "30 10 25 12 *",
@@ -269,8 +316,9 @@ func TestSchedulerService_AddReminder_OnceUsesTimer(t *testing.T) {
 
 // Mock repositories for scheduler tests
 type mockReminderLogRepository struct {
-	logs      map[uint]*models.ReminderLog
-	idCounter uint
+	logs         map[uint]*models.ReminderLog
+	idCounter    uint
+	reminderRepo *mockReminderRepository // 按用户查询时用来确定记录所属用户
 }
 
 func newMockReminderLogRepository() *mockReminderLogRepository {
@@ -287,6 +335,26 @@ func (m *mockReminderLogRepository) Create(ctx context.Context, log *models.Remi
 	return nil
 }
 
+func (m *mockReminderLogRepository) Claim(ctx context.Context, log *models.ReminderLog) (bool, error) {
+	for _, existing := range m.logs {
+		if existing.ReminderID == log.ReminderID && existing.OccurrenceAt != nil && existing.OccurrenceAt.Equal(*log.OccurrenceAt) {
+			return false, nil
+		}
+	}
+	return true, m.Create(ctx, log)
+}
+
+func (m *mockReminderLogRepository) Complete(ctx context.Context, id uint, response string, at time.Time) (bool, error) {
+	log := m.logs[id]
+	if log == nil || log.IsCompleted() {
+		return false, nil
+	}
+	log.Status = models.ReminderStatusCompleted
+	log.UserResponse = response
+	log.ResponseTime = &at
+	return true, nil
+}
+
 func (m *mockReminderLogRepository) GetByID(ctx context.Context, id uint) (*models.ReminderLog, error) {
 	log := m.logs[id]
 	return log, nil
@@ -299,6 +367,33 @@ func (m *mockReminderLogRepository) GetByReminderID(ctx context.Context, reminde
 			result = append(result, log)
 		}
 	}
+	// 与数据库实现一致：按计划时间倒序
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ScheduledTime.After(result[j].ScheduledTime)
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *mockReminderLogRepository) GetByUserBetween(ctx context.Context, userID uint, from, to time.Time) ([]*models.ReminderLog, error) {
+	within := func(t time.Time) bool { return !t.Before(from) && t.Before(to) }
+	var result []*models.ReminderLog
+	for _, log := range m.logs {
+		if m.reminderRepo == nil {
+			continue
+		}
+		if reminder, _ := m.reminderRepo.GetByID(ctx, log.ReminderID); reminder == nil || reminder.UserID != userID {
+			continue
+		}
+		if within(log.ScheduledTime) || (log.OccurrenceAt != nil && within(*log.OccurrenceAt)) {
+			result = append(result, log)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ScheduledTime.Before(result[j].ScheduledTime)
+	})
 	return result, nil
 }
 
@@ -324,6 +419,484 @@ func (m *mockReminderLogRepository) Delete(ctx context.Context, id uint) error {
 	return nil
 }
 
+// TestScheduler_IntervalScheduleNext 测试间隔模式的触发时间计算
+func TestScheduler_IntervalScheduleNext(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	createdAt := time.Date(2026, 5, 1, 8, 15, 0, 0, loc)
+
+	tests := []struct {
+		name       string
+		pattern    string
+		targetTime string
+		from       time.Time
+		want       time.Time
+	}{
+		{
+			name:       "时间窗口内每2小时",
+			pattern:    "every:2h@09:00-18:00",
+			targetTime: "09:00:00",
+			from:       time.Date(2026, 5, 3, 11, 30, 0, 0, loc),
+			want:       time.Date(2026, 5, 3, 13, 0, 0, 0, loc),
+		},
+		{
+			name:       "窗口结束后到次日开始",
+			pattern:    "every:2h@09:00-18:00",
+			targetTime: "09:00:00",
+			from:       time.Date(2026, 5, 3, 17, 0, 0, 0, loc),
+			want:       time.Date(2026, 5, 4, 9, 0, 0, 0, loc),
+		},
+		{
+			name:       "每5小时跨天连续计算",
+			pattern:    "every:5h",
+			targetTime: "10:00:00",
+			from:       time.Date(2026, 5, 2, 0, 0, 0, 0, loc),
+			want:       time.Date(2026, 5, 2, 1, 0, 0, 0, loc),
+		},
+		{
+			name:       "每3天",
+			pattern:    "every:3d",
+			targetTime: "20:00:00",
+			from:       time.Date(2026, 5, 4, 21, 0, 0, 0, loc),
+			want:       time.Date(2026, 5, 7, 20, 0, 0, 0, loc),
+		},
+		{
+			name:       "创建当天尚未到点",
+			pattern:    "every:3d",
+			targetTime: "20:00:00",
+			from:       createdAt,
+			want:       time.Date(2026, 5, 1, 20, 0, 0, 0, loc),
+		},
+		{
+			name:       "隔周",
+			pattern:    "every:2w",
+			targetTime: "09:00:00",
+			from:       time.Date(2026, 5, 2, 0, 0, 0, 0, loc),
+			want:       time.Date(2026, 5, 15, 9, 0, 0, 0, loc),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduler := &schedulerService{location: loc, reminderLogRepo: newMockReminderLogRepository()}
+			schedule, err := scheduler.buildSchedule(&models.Reminder{
+				SchedulePattern: tt.pattern,
+				TargetTime:      tt.targetTime,
+				CreatedAt:       createdAt,
+			})
+			if err != nil {
+				t.Fatalf("buildSchedule() 失败: %v", err)
+			}
+
+			if got := schedule.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestScheduler_RRuleScheduleNext 测试 RRULE 模式的触发时间展开
+func TestScheduler_RRuleScheduleNext(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	// 2026-05-01 是周五
+	createdAt := time.Date(2026, 5, 1, 8, 15, 0, 0, loc)
+
+	tests := []struct {
+		name    string
+		pattern string
+		from    time.Time
+		want    time.Time
+	}{
+		{
+			name:    "创建当天即命中",
+			pattern: "rrule:FREQ=WEEKLY;BYDAY=MO,WE,FR",
+			from:    createdAt,
+			want:    time.Date(2026, 5, 1, 9, 30, 0, 0, loc),
+		},
+		{
+			name:    "每月最后一个工作日",
+			pattern: "rrule:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			from:    time.Date(2026, 5, 2, 0, 0, 0, 0, loc),
+			want:    time.Date(2026, 5, 29, 9, 30, 0, 0, loc),
+		},
+		{
+			name:    "COUNT用尽后不再触发",
+			pattern: "rrule:FREQ=DAILY;COUNT=2",
+			from:    time.Date(2026, 5, 2, 10, 0, 0, 0, loc),
+			want:    time.Time{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduler := &schedulerService{location: loc}
+			schedule, err := scheduler.buildSchedule(&models.Reminder{
+				SchedulePattern: tt.pattern,
+				TargetTime:      "09:30:00",
+				CreatedAt:       createdAt,
+			})
+			if err != nil {
+				t.Fatalf("buildSchedule() 失败: %v", err)
+			}
+
+			if got := schedule.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	scheduler := &schedulerService{location: loc}
+	if _, err := scheduler.buildSchedule(&models.Reminder{SchedulePattern: "rrule:FREQ=HOURLY", TargetTime: "09:30:00"}); err == nil {
+		t.Error("不支持的 RRULE 应返回错误")
+	}
+}
+
+// TestScheduler_IntervalAnchorFromLastFire 测试间隔从最近一次触发时间起算
+func TestScheduler_IntervalAnchorFromLastFire(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	mockLogRepo := newMockReminderLogRepository()
+	scheduler := &schedulerService{location: loc, reminderLogRepo: mockLogRepo}
+
+	lastFire := time.Now().In(loc).Add(-time.Hour).Truncate(time.Minute)
+	mockLogRepo.Create(context.Background(), &models.ReminderLog{
+		ReminderID:    1,
+		ScheduledTime: lastFire,
+		OccurrenceAt:  models.OccurrenceKey(lastFire),
+		Status:        models.ReminderStatusSent,
+	})
+	// 之后的延期记录不是计划触发，不影响起算点
+	mockLogRepo.Create(context.Background(), &models.ReminderLog{
+		ReminderID:    1,
+		ScheduledTime: lastFire.Add(30 * time.Minute),
+		Status:        models.ReminderStatusSent,
+	})
+
+	schedule, err := scheduler.buildSchedule(&models.Reminder{
+		ID:              1,
+		SchedulePattern: "every:3h",
+		TargetTime:      "09:00:00",
+		CreatedAt:       lastFire.AddDate(0, 0, -10),
+	})
+	if err != nil {
+		t.Fatalf("buildSchedule() 失败: %v", err)
+	}
+
+	if got, want := schedule.Next(lastFire), lastFire.Add(3*time.Hour); !got.Equal(want) {
+		t.Errorf("Next() = %s, want %s", got, want)
+	}
+}
+
+// TestScheduler_ScheduleDelivery 测试待发送记录到期后投递
+func TestScheduler_ScheduleDelivery(t *testing.T) {
+	mockReminderRepo := newMockReminderRepository()
+	mockLogRepo := newMockReminderLogRepository()
+	mockNotification := newMockNotificationService()
+
+	scheduler := NewSchedulerService(mockReminderRepo, mockLogRepo, mockNotification).(*schedulerService)
+	ctx := context.Background()
+
+	reminder := &models.Reminder{
+		UserID:          1,
+		Title:           "延期提醒",
+		SchedulePattern: "daily",
+		TargetTime:      "09:00:00",
+		IsActive:        true,
+	}
+	mockReminderRepo.Create(ctx, reminder)
+
+	delayLog := &models.ReminderLog{
+		ReminderID:    reminder.ID,
+		ScheduledTime: time.Now().Add(50 * time.Millisecond),
+		Status:        models.ReminderStatusPending,
+	}
+	mockLogRepo.Create(ctx, delayLog)
+
+	if err := scheduler.ScheduleDelivery(delayLog); err != nil {
+		t.Fatalf("ScheduleDelivery() 失败: %v", err)
+	}
+
+	if len(mockNotification.sentReminders) != 0 {
+		t.Fatal("未到期的记录不应立即投递")
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	if len(mockNotification.sentReminders) != 1 || mockNotification.sentReminders[0] != delayLog.ID {
+		t.Fatalf("期待投递记录 %d，实际 %v", delayLog.ID, mockNotification.sentReminders)
+	}
+
+	if got, _ := mockLogRepo.GetByID(ctx, delayLog.ID); got.Status != models.ReminderStatusSent {
+		t.Errorf("投递后状态应为 sent，实际 %s", got.Status)
+	}
+
+	scheduler.mu.RLock()
+	remaining := len(scheduler.deliveryTimers)
+	scheduler.mu.RUnlock()
+	if remaining != 0 {
+		t.Errorf("投递后应清理定时器，剩余 %d", remaining)
+	}
+}
+
+// TestScheduler_RetryFailedSend 测试认领后发送失败的触发按退避时间重试，多次失败后取消
+func TestScheduler_RetryFailedSend(t *testing.T) {
+	reminderRepo := newMockReminderRepository()
+	logRepo := newMockReminderLogRepository()
+	notification := newMockNotificationService()
+	ctx := context.Background()
+
+	start := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	simulated := clock.NewSimulated(start)
+
+	reminder := &models.Reminder{
+		UserID:          1,
+		Title:           "吃药",
+		SchedulePattern: "daily",
+		TargetTime:      "09:00:00",
+		IsActive:        true,
+		CreatedAt:       start,
+	}
+	reminderRepo.Create(ctx, reminder)
+
+	scheduler := NewSchedulerService(reminderRepo, logRepo, notification).(*schedulerService)
+	scheduler.location = time.UTC
+	scheduler.SetClock(simulated)
+	if err := scheduler.Start(); err != nil {
+		t.Fatalf("Start() 失败: %v", err)
+	}
+	defer scheduler.Stop()
+
+	// 09:00 发送失败，09:01 第一次重试失败，09:03 第二次重试成功
+	notification.failReminders = 2
+	simulated.AdvanceTo(time.Date(2026, 5, 1, 9, 2, 0, 0, time.UTC))
+	if len(notification.sentReminders) != 0 {
+		t.Fatalf("重试成功前不应有发送记录，实际 %d", len(notification.sentReminders))
+	}
+	if stored, _ := reminderRepo.GetByID(ctx, reminder.ID); stored.OccurrenceCount != 1 {
+		t.Errorf("发送失败的触发也应计数，实际 %d", stored.OccurrenceCount)
+	}
+
+	simulated.AdvanceTo(time.Date(2026, 5, 1, 9, 3, 0, 0, time.UTC))
+	if len(notification.sentReminders) != 1 {
+		t.Fatalf("重试后应发送1次提醒，实际 %d", len(notification.sentReminders))
+	}
+	if log, _ := logRepo.GetByID(ctx, notification.sentReminders[0]); log.Status != models.ReminderStatusSent {
+		t.Errorf("重试成功后状态应为 sent，实际 %s", log.Status)
+	}
+
+	// 次日一直失败，重试用完后取消
+	notification.failReminders = maxDeliveryRetries + 1
+	simulated.AdvanceTo(time.Date(2026, 5, 2, 12, 0, 0, 0, time.UTC))
+	if len(notification.sentReminders) != 1 || notification.failReminders != 0 {
+		t.Fatalf("应重试 %d 次后放弃，实际发送 %d 次，剩余失败 %d 次", maxDeliveryRetries, len(notification.sentReminders), notification.failReminders)
+	}
+	logs, _ := logRepo.GetByReminderID(ctx, reminder.ID, 10, 0)
+	cancelled := 0
+	for _, log := range logs {
+		if log.Status == models.ReminderStatusCancelled {
+			cancelled++
+		}
+	}
+	if cancelled != 1 {
+		t.Errorf("重试用完后应取消记录，实际取消 %d 条", cancelled)
+	}
+
+	scheduler.mu.RLock()
+	attempts := len(scheduler.deliveryAttempts)
+	scheduler.mu.RUnlock()
+	if attempts != 0 {
+		t.Errorf("放弃后应清理重试次数，剩余 %d", attempts)
+	}
+}
+
+// TestScheduler_RestorePendingDeliveries 测试重启后恢复并投递待发送记录
+func TestScheduler_RestorePendingDeliveries(t *testing.T) {
+	mockReminderRepo := newMockReminderRepository()
+	mockLogRepo := newMockReminderLogRepository()
+	mockNotification := newMockNotificationService()
+	ctx := context.Background()
+
+	active := &models.Reminder{UserID: 1, Title: "活跃", SchedulePattern: "daily", TargetTime: "09:00:00", IsActive: true, CreatedAt: time.Now()}
+	inactive := &models.Reminder{UserID: 1, Title: "已停用", SchedulePattern: "daily", TargetTime: "09:00:00", IsActive: false}
+	mockReminderRepo.Create(ctx, active)
+	mockReminderRepo.Create(ctx, inactive)
+
+	// 重启期间已到期的延期记录
+	dueLog := &models.ReminderLog{ReminderID: active.ID, ScheduledTime: time.Now().Add(-time.Minute), Status: models.ReminderStatusPending}
+	// 提醒已停用的延期记录
+	staleLog := &models.ReminderLog{ReminderID: inactive.ID, ScheduledTime: time.Now().Add(-time.Minute), Status: models.ReminderStatusPending}
+	// 已发送的记录不应重复投递
+	sentLog := &models.ReminderLog{ReminderID: active.ID, ScheduledTime: time.Now().Add(-time.Hour), Status: models.ReminderStatusSent}
+	mockLogRepo.Create(ctx, dueLog)
+	mockLogRepo.Create(ctx, staleLog)
+	mockLogRepo.Create(ctx, sentLog)
+
+	scheduler := NewSchedulerService(mockReminderRepo, mockLogRepo, mockNotification).(*schedulerService)
+	if err := scheduler.Start(); err != nil {
+		t.Fatalf("Start() 失败: %v", err)
+	}
+	defer scheduler.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+
+	if len(mockNotification.sentReminders) != 1 || mockNotification.sentReminders[0] != dueLog.ID {
+		t.Fatalf("期待只投递记录 %d，实际 %v", dueLog.ID, mockNotification.sentReminders)
+	}
+
+	if got, _ := mockLogRepo.GetByID(ctx, staleLog.ID); got.Status != models.ReminderStatusCancelled {
+		t.Errorf("提醒停用后记录应取消，实际 %s", got.Status)
+	}
+}
+
+// TestScheduler_ReconcileMissed 测试启动时按策略补偿停机期间错过的提醒
+func TestScheduler_ReconcileMissed(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, loc)
+
+	tests := []struct {
+		policy      MissedPolicy
+		wantSent    int
+		wantDigests []int
+		wantOverdue int
+	}{
+		{policy: MissedPolicyLate, wantSent: 1, wantOverdue: 1},
+		{policy: MissedPolicyDigest, wantSent: 0, wantDigests: []int{2}, wantOverdue: 2},
+		{policy: MissedPolicyMark, wantSent: 0, wantOverdue: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			mockReminderRepo := newMockReminderRepository()
+			mockLogRepo := newMockReminderLogRepository()
+			mockNotification := newMockNotificationService()
+			ctx := context.Background()
+
+			scheduler := NewSchedulerService(mockReminderRepo, mockLogRepo, mockNotification).(*schedulerService)
+			scheduler.location = loc
+			if err := scheduler.SetMissedPolicy(string(tt.policy), 48*time.Hour); err != nil {
+				t.Fatalf("SetMissedPolicy() 失败: %v", err)
+			}
+
+			// 停机48小时，错过 05-09 和 05-10 两次 09:00 的提醒
+			reminder := &models.Reminder{
+				UserID:          1,
+				Title:           "每日提醒",
+				SchedulePattern: "daily",
+				TargetTime:      "09:00:00",
+				IsActive:        true,
+				CreatedAt:       now.AddDate(0, 0, -7),
+			}
+			mockReminderRepo.Create(ctx, reminder)
+
+			if got := scheduler.reconcileMissed(ctx, []*models.Reminder{reminder}, now); got != 2 {
+				t.Fatalf("reconcileMissed() = %d, want 2", got)
+			}
+
+			if len(mockNotification.sentReminders) != tt.wantSent {
+				t.Errorf("补发数量 = %d, want %d", len(mockNotification.sentReminders), tt.wantSent)
+			}
+			if fmt.Sprint(mockNotification.sentDigests) != fmt.Sprint(tt.wantDigests) {
+				t.Errorf("汇总 = %v, want %v", mockNotification.sentDigests, tt.wantDigests)
+			}
+
+			logs, _ := mockLogRepo.GetByReminderID(ctx, reminder.ID, 0, 0)
+			overdue := 0
+			for _, log := range logs {
+				if log.Status == models.ReminderStatusOverdue {
+					overdue++
+				}
+			}
+			if overdue != tt.wantOverdue {
+				t.Errorf("过期记录 = %d, want %d", overdue, tt.wantOverdue)
+			}
+
+			// 再次启动时已有记录，不应重复补偿
+			if got := scheduler.reconcileMissed(ctx, []*models.Reminder{reminder}, now); got != 0 {
+				t.Errorf("重复 reconcileMissed() = %d, want 0", got)
+			}
+		})
+	}
+}
+
+// TestScheduler_MissedDigestQuietHours 测试错过提醒汇总在免打扰时段内推迟到时段结束发送
+func TestScheduler_MissedDigestQuietHours(t *testing.T) {
+	reminderRepo := newMockReminderRepository()
+	notification := newMockNotificationService()
+	ctx := context.Background()
+
+	now := time.Date(2026, 5, 10, 23, 30, 0, 0, time.UTC)
+	simulated := clock.NewSimulated(now)
+
+	scheduler := NewSchedulerService(reminderRepo, newMockReminderLogRepository(), notification).(*schedulerService)
+	scheduler.location = time.UTC
+	scheduler.SetClock(simulated)
+	if err := scheduler.SetMissedPolicy(string(MissedPolicyDigest), 48*time.Hour); err != nil {
+		t.Fatalf("SetMissedPolicy() 失败: %v", err)
+	}
+
+	reminder := &models.Reminder{
+		UserID:          1,
+		User:            models.User{ID: 1, Timezone: "UTC", QuietHours: "22:00-07:00"},
+		Title:           "每日提醒",
+		SchedulePattern: "daily",
+		TargetTime:      "09:00:00",
+		IsActive:        true,
+		CreatedAt:       now.AddDate(0, 0, -7),
+	}
+	reminderRepo.Create(ctx, reminder)
+
+	if got := scheduler.reconcileMissed(ctx, []*models.Reminder{reminder}, now); got != 2 {
+		t.Fatalf("reconcileMissed() = %d, want 2", got)
+	}
+	if len(notification.sentDigests) != 0 {
+		t.Fatalf("免打扰时段内不应发送汇总，实际 %v", notification.sentDigests)
+	}
+
+	simulated.AdvanceTo(time.Date(2026, 5, 11, 7, 0, 0, 0, time.UTC))
+	if fmt.Sprint(notification.sentDigests) != "[2]" {
+		t.Errorf("免打扰结束后应发送汇总，实际 %v", notification.sentDigests)
+	}
+}
+
+// TestScheduler_ReconcileMissed_ExpiredOnce 测试过期的一次性提醒被补发并停用
+func TestScheduler_ReconcileMissed_ExpiredOnce(t *testing.T) {
+	mockReminderRepo := newMockReminderRepository()
+	mockLogRepo := newMockReminderLogRepository()
+	mockNotification := newMockNotificationService()
+	ctx := context.Background()
+
+	scheduler := NewSchedulerService(mockReminderRepo, mockLogRepo, mockNotification).(*schedulerService)
+	now := time.Now().In(scheduler.location)
+	target := now.Add(-2 * time.Hour)
+
+	missed := &models.Reminder{
+		UserID:          1,
+		Title:           "停机期间的一次性提醒",
+		SchedulePattern: "once:" + target.Format("2006-01-02"),
+		TargetTime:      target.Format("15:04:00"),
+		IsActive:        true,
+		CreatedAt:       now.Add(-3 * time.Hour),
+	}
+	stale := &models.Reminder{
+		UserID:          1,
+		Title:           "很久以前的一次性提醒",
+		SchedulePattern: "once:2020-01-01",
+		TargetTime:      "09:00:00",
+		IsActive:        true,
+	}
+	mockReminderRepo.Create(ctx, missed)
+	mockReminderRepo.Create(ctx, stale)
+
+	if got := scheduler.reconcileMissed(ctx, []*models.Reminder{missed, stale}, now); got != 1 {
+		t.Fatalf("reconcileMissed() = %d, want 1", got)
+	}
+
+	if len(mockNotification.sentReminders) != 1 {
+		t.Errorf("期待补发1条提醒，实际 %d", len(mockNotification.sentReminders))
+	}
+	if missed.IsActive || stale.IsActive {
+		t.Error("过期的一次性提醒应被停用")
+	}
+}
+
 // TestScheduler_OnceReminder_PastTime 测试过期时间的once提醒
 func TestScheduler_OnceReminder_PastTime(t *testing.T) {
 	mockReminderRepo := newMockReminderRepository()
@@ -413,16 +986,96 @@ func TestScheduler_RemoveOnceReminder(t *testing.T) {
 }
 
 // TestScheduler_DailyReminder 测试每日提醒调度
-func TestScheduler_DailyReminder(t *testing.T) {
-	mockReminderRepo := newMockReminderRepository()
-	mockLogRepo := newMockReminderLogRepository()
-	mockNotification := newMockNotificationService()
+// TestScheduler_ReminderTimezone 测试提醒按自身时区调度，并正确处理夏令时切换
+func TestScheduler_ReminderTimezone(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("时区数据不可用: %v", err)
+	}
 
-	scheduler := NewSchedulerService(mockReminderRepo, mockLogRepo, mockNotification).(*schedulerService)
+	scheduler := NewSchedulerService(newMockReminderRepository(), newMockReminderLogRepository(), newMockNotificationService()).(*schedulerService)
 
 	reminder := &models.Reminder{
-		ID:              500,
-		UserID:          1,
+		ID:              520,
+		SchedulePattern: "daily",
+		TargetTime:      "09:00:00",
+		Timezone:        "Europe/Berlin",
+		IsActive:        true,
+	}
+
+	schedule, err := scheduler.buildSchedule(reminder)
+	if err != nil {
+		t.Fatalf("buildSchedule() 失败: %v", err)
+	}
+
+	// 2026-03-29 柏林切换到夏令时，前后两天都应在本地 09:00 触发
+	before := schedule.Next(time.Date(2026, 3, 28, 0, 0, 0, 0, berlin))
+	after := schedule.Next(time.Date(2026, 3, 29, 0, 0, 0, 0, berlin))
+
+	for _, next := range []time.Time{before, after} {
+		local := next.In(berlin)
+		if local.Hour() != 9 || local.Minute() != 0 {
+			t.Errorf("期待本地时间 09:00, 实际 %s", local.Format(time.RFC3339))
+		}
+	}
+
+	if before.UTC().Hour() != 8 || after.UTC().Hour() != 7 {
+		t.Errorf("夏令时切换前后 UTC 时间不正确: %s, %s", before.UTC(), after.UTC())
+	}
+}
+
+// TestScheduler_ResolveLocation 测试时区优先级：提醒时区 > 用户时区 > 默认时区
+func TestScheduler_ResolveLocation(t *testing.T) {
+	scheduler := NewSchedulerService(newMockReminderRepository(), newMockReminderLogRepository(), newMockNotificationService()).(*schedulerService)
+	if err := scheduler.SetDefaultTimezone("UTC"); err != nil {
+		t.Fatalf("SetDefaultTimezone() 失败: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		reminder *models.Reminder
+		want     string
+	}{
+		{
+			name:     "提醒自身时区",
+			reminder: &models.Reminder{Timezone: "America/New_York", User: models.User{Timezone: "Asia/Tokyo"}},
+			want:     "America/New_York",
+		},
+		{
+			name:     "用户时区",
+			reminder: &models.Reminder{User: models.User{Timezone: "Asia/Tokyo"}},
+			want:     "Asia/Tokyo",
+		},
+		{
+			name:     "无效时区回退默认",
+			reminder: &models.Reminder{Timezone: "Invalid/Zone"},
+			want:     "UTC",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scheduler.resolveLocation(tt.reminder).String(); got != tt.want {
+				t.Errorf("resolveLocation() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if err := scheduler.SetDefaultTimezone("Invalid/Zone"); err == nil {
+		t.Error("SetDefaultTimezone() 期待无效时区返回错误")
+	}
+}
+
+func TestScheduler_DailyReminder(t *testing.T) {
+	mockReminderRepo := newMockReminderRepository()
+	mockLogRepo := newMockReminderLogRepository()
+	mockNotification := newMockNotificationService()
+
+	scheduler := NewSchedulerService(mockReminderRepo, mockLogRepo, mockNotification).(*schedulerService)
+
+	reminder := &models.Reminder{
+		ID:              500,
+		UserID:          1,
 		Title:           "每日提醒",
 		SchedulePattern: "daily",
 		TargetTime:      "09:30:00",
@@ -448,6 +1101,311 @@ func TestScheduler_DailyReminder(t *testing.T) {
 	}
 }
 
+// TestScheduler_MultipleTimes 测试一个提醒带多个触发时间
+func TestScheduler_MultipleTimes(t *testing.T) {
+	mockReminderRepo := newMockReminderRepository()
+	mockLogRepo := newMockReminderLogRepository()
+	mockNotification := newMockNotificationService()
+
+	scheduler := NewSchedulerService(mockReminderRepo, mockLogRepo, mockNotification).(*schedulerService)
+	loc := scheduler.location
+
+	reminder := &models.Reminder{
+		ID:              510,
+		UserID:          1,
+		Title:           "吃药",
+		SchedulePattern: "daily",
+		IsActive:        true,
+	}
+	reminder.SetTimes([]string{"20:00:00", "08:00:00", "13:00:00", "08:00:00"})
+
+	if reminder.TargetTime != "08:00:00" || reminder.ExtraTimes != "13:00:00,20:00:00" {
+		t.Fatalf("SetTimes() 结果错误: TargetTime=%s ExtraTimes=%s", reminder.TargetTime, reminder.ExtraTimes)
+	}
+
+	if err := scheduler.AddReminder(reminder); err != nil {
+		t.Fatalf("AddReminder() 失败: %v", err)
+	}
+
+	scheduler.mu.RLock()
+	entries := len(scheduler.jobs[reminder.ID])
+	scheduler.mu.RUnlock()
+	if entries != 3 {
+		t.Fatalf("期待每个时间一个cron任务，实际 %d 个", entries)
+	}
+	if len(scheduler.cron.Entries()) != 3 {
+		t.Fatalf("cron 中应有3个任务，实际 %d 个", len(scheduler.cron.Entries()))
+	}
+
+	// 错过的时间点按先后合并
+	now := time.Date(2026, 5, 2, 12, 0, 0, 0, loc)
+	reminder.CreatedAt = now.Add(-48 * time.Hour)
+	occurrences, err := scheduler.missedOccurrences(context.Background(), reminder, now.Add(-24*time.Hour), now)
+	if err != nil {
+		t.Fatalf("missedOccurrences() 失败: %v", err)
+	}
+	want := []time.Time{
+		time.Date(2026, 5, 1, 13, 0, 0, 0, loc),
+		time.Date(2026, 5, 1, 20, 0, 0, 0, loc),
+		time.Date(2026, 5, 2, 8, 0, 0, 0, loc),
+	}
+	if len(occurrences) != len(want) {
+		t.Fatalf("missedOccurrences() = %v, want %v", occurrences, want)
+	}
+	for i := range want {
+		if !occurrences[i].Equal(want[i]) {
+			t.Errorf("第 %d 次 = %s, want %s", i+1, occurrences[i], want[i])
+		}
+	}
+
+	if err := scheduler.RemoveReminder(reminder.ID); err != nil {
+		t.Fatalf("RemoveReminder() 失败: %v", err)
+	}
+	if len(scheduler.cron.Entries()) != 0 {
+		t.Errorf("移除后不应残留cron任务，实际 %d 个", len(scheduler.cron.Entries()))
+	}
+}
+
+// TestScheduler_OnceMultipleTimes 测试一次性提醒部分时间已过时只调度未到的时间
+func TestScheduler_OnceMultipleTimes(t *testing.T) {
+	mockReminderRepo := newMockReminderRepository()
+	mockLogRepo := newMockReminderLogRepository()
+	mockNotification := newMockNotificationService()
+
+	scheduler := NewSchedulerService(mockReminderRepo, mockLogRepo, mockNotification).(*schedulerService)
+	now := time.Now().In(scheduler.location)
+	if now.Hour() < 1 || now.Hour() > 21 {
+		t.Skip("临近零点，跳过同日多时间测试")
+	}
+
+	past := now.Add(-time.Hour)
+	future := now.Add(2 * time.Hour)
+	reminder := &models.Reminder{
+		ID:              511,
+		UserID:          1,
+		Title:           "今天两次",
+		SchedulePattern: "once:" + now.Format("2006-01-02"),
+		IsActive:        true,
+	}
+	reminder.SetTimes([]string{past.Format("15:04:00"), future.Format("15:04:00")})
+
+	if err := scheduler.AddReminder(reminder); err != nil {
+		t.Fatalf("AddReminder() 失败: %v", err)
+	}
+
+	scheduler.mu.RLock()
+	timers := len(scheduler.onceTimers[reminder.ID])
+	scheduler.mu.RUnlock()
+	if timers != 1 {
+		t.Fatalf("期待只为未到的时间创建定时器，实际 %d 个", timers)
+	}
+	if scheduler.onceExpired(reminder, now) {
+		t.Error("还有未到的时间点，不应视为过期")
+	}
+	if !scheduler.onceExpired(reminder, future.Add(time.Minute)) {
+		t.Error("所有时间点已过，应视为过期")
+	}
+
+	scheduler.RemoveReminder(reminder.ID)
+}
+
+// TestScheduler_EndConditions 测试截止日期和最多触发次数
+func TestScheduler_EndConditions(t *testing.T) {
+	mockReminderRepo := newMockReminderRepository()
+	mockLogRepo := newMockReminderLogRepository()
+	mockNotification := newMockNotificationService()
+	ctx := context.Background()
+
+	scheduler := NewSchedulerService(mockReminderRepo, mockLogRepo, mockNotification).(*schedulerService)
+	loc := scheduler.location
+
+	// 截止日期当天仍会触发，之后不再触发
+	until := &models.Reminder{
+		SchedulePattern: "daily",
+		TargetTime:      "09:00:00",
+		UntilDate:       "2026-05-03",
+	}
+	schedules, err := scheduler.buildSchedules(until)
+	if err != nil {
+		t.Fatalf("buildSchedules() 失败: %v", err)
+	}
+	schedule := multiSchedule(schedules)
+	if next := schedule.Next(time.Date(2026, 5, 2, 10, 0, 0, 0, loc)); !next.Equal(time.Date(2026, 5, 3, 9, 0, 0, 0, loc)) {
+		t.Errorf("截止日期当天应触发，实际 %s", next)
+	}
+	if next := schedule.Next(time.Date(2026, 5, 3, 10, 0, 0, 0, loc)); !next.IsZero() {
+		t.Errorf("截止日期之后不应触发，实际 %s", next)
+	}
+	if !scheduler.reminderEnded(until, time.Date(2026, 5, 3, 10, 0, 0, 0, loc)) {
+		t.Error("截止日期最后一次触发后应视为已结束")
+	}
+
+	// 最后一次触发后自动停用
+	limited := &models.Reminder{
+		UserID:          1,
+		Title:           "冥想21次",
+		SchedulePattern: "daily",
+		TargetTime:      "07:00:00",
+		IsActive:        true,
+		MaxOccurrences:  2,
+		OccurrenceCount: 1,
+	}
+	mockReminderRepo.Create(ctx, limited)
+	if err := scheduler.AddReminder(limited); err != nil {
+		t.Fatalf("AddReminder() 失败: %v", err)
+	}
+
+	occurrence := time.Now().Truncate(time.Minute)
+
+	// 未结束时只写触发次数，不整体更新提醒（整体更新会刷新 updated_at 导致同步时重建调度）
+	counting := &models.Reminder{
+		UserID:          1,
+		Title:           "跑步10次",
+		SchedulePattern: "daily",
+		TargetTime:      "06:00:00",
+		IsActive:        true,
+		MaxOccurrences:  10,
+	}
+	mockReminderRepo.Create(ctx, counting)
+	updates := mockReminderRepo.updates
+	scheduler.executeReminder(counting.ID, occurrence)
+	if stored, _ := mockReminderRepo.GetByID(ctx, counting.ID); stored.OccurrenceCount != 1 {
+		t.Errorf("触发后次数应为1，实际 %d", stored.OccurrenceCount)
+	}
+	if mockReminderRepo.updates != updates {
+		t.Error("记录触发次数不应整体更新提醒")
+	}
+	mockNotification.sentReminders = nil
+
+	scheduler.executeReminder(limited.ID, occurrence)
+
+	if len(mockNotification.sentReminders) != 1 {
+		t.Fatalf("期待发送1条提醒，实际 %d", len(mockNotification.sentReminders))
+	}
+	stored, _ := mockReminderRepo.GetByID(ctx, limited.ID)
+	if stored.IsActive || stored.OccurrenceCount != 2 {
+		t.Errorf("达到次数后应停用，IsActive=%v OccurrenceCount=%d", stored.IsActive, stored.OccurrenceCount)
+	}
+	scheduler.mu.RLock()
+	_, exists := scheduler.jobs[limited.ID]
+	scheduler.mu.RUnlock()
+	if exists {
+		t.Error("停用后应移除调度任务")
+	}
+
+	// 已停用的提醒不再发送
+	stored.IsActive = true
+	scheduler.executeReminder(limited.ID, occurrence.Add(24*time.Hour))
+	if len(mockNotification.sentReminders) != 1 {
+		t.Errorf("次数用完后不应再发送，实际发送 %d 条", len(mockNotification.sentReminders))
+	}
+}
+
+// fakeHolidayCalendar 测试用节假日日历，按地区记录放假日和调休上班日
+type fakeHolidayCalendar struct {
+	holidays map[string]bool // key: 地区/YYYY-MM-DD
+	workdays map[string]bool
+}
+
+func (f *fakeHolidayCalendar) IsWorkday(region string, date time.Time) bool {
+	key := region + "/" + date.Format("2006-01-02")
+	if f.workdays[key] {
+		return true
+	}
+	if f.holidays[key] {
+		return false
+	}
+	return holiday.Weekdays.IsWorkday(region, date)
+}
+
+func TestScheduler_WorkdaySchedule(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	calendar := &fakeHolidayCalendar{
+		holidays: map[string]bool{
+			"CN/2026-10-01": true, "CN/2026-10-02": true, "CN/2026-10-05": true,
+			"CN/2026-10-06": true, "CN/2026-10-07": true,
+		},
+		workdays: map[string]bool{"CN/2026-10-10": true},
+	}
+
+	tests := []struct {
+		name    string
+		pattern string
+		from    time.Time
+		want    time.Time
+	}{
+		{
+			name:    "跳过国庆假期",
+			pattern: "workday",
+			from:    time.Date(2026, 9, 30, 10, 0, 0, 0, loc),
+			want:    time.Date(2026, 10, 8, 9, 0, 0, 0, loc),
+		},
+		{
+			name:    "调休上班的周六",
+			pattern: "workday",
+			from:    time.Date(2026, 10, 9, 10, 0, 0, 0, loc),
+			want:    time.Date(2026, 10, 10, 9, 0, 0, 0, loc),
+		},
+		{
+			name:    "指定地区不使用默认地区的假期",
+			pattern: "workday:HK",
+			from:    time.Date(2026, 9, 30, 10, 0, 0, 0, loc),
+			want:    time.Date(2026, 10, 1, 9, 0, 0, 0, loc),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduler := &schedulerService{location: loc}
+			scheduler.SetHolidayCalendar(calendar, "CN")
+
+			schedule, err := scheduler.buildSchedule(&models.Reminder{
+				SchedulePattern: tt.pattern,
+				TargetTime:      "09:00:00",
+			})
+			if err != nil {
+				t.Fatalf("buildSchedule() 失败: %v", err)
+			}
+
+			if got := schedule.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduler_WorkdaySkipsHolidayAtFireTime(t *testing.T) {
+	mockReminderRepo := newMockReminderRepository()
+	mockLogRepo := newMockReminderLogRepository()
+	mockNotification := newMockNotificationService()
+	ctx := context.Background()
+
+	scheduler := NewSchedulerService(mockReminderRepo, mockLogRepo, mockNotification).(*schedulerService)
+
+	// 排好任务后日历更新，今天变为放假日
+	today := time.Now().In(scheduler.location).Format("2006-01-02")
+	scheduler.SetHolidayCalendar(&fakeHolidayCalendar{holidays: map[string]bool{"CN/" + today: true}}, "CN")
+
+	reminder := &models.Reminder{
+		UserID:          1,
+		Title:           "写日报",
+		SchedulePattern: "workday",
+		TargetTime:      "18:00:00",
+		IsActive:        true,
+	}
+	mockReminderRepo.Create(ctx, reminder)
+
+	scheduler.executeReminder(reminder.ID, time.Now())
+
+	if len(mockNotification.sentReminders) != 0 {
+		t.Errorf("放假日不应发送提醒，实际发送 %d 条", len(mockNotification.sentReminders))
+	}
+	stored, _ := mockReminderRepo.GetByID(ctx, reminder.ID)
+	if !stored.IsActive || stored.OccurrenceCount != 0 {
+		t.Errorf("跳过后提醒应保持不变，IsActive=%v OccurrenceCount=%d", stored.IsActive, stored.OccurrenceCount)
+	}
+}
+
 // TestScheduler_WeeklyReminder 测试每周提醒调度
 func TestScheduler_WeeklyReminder(t *testing.T) {
 	mockReminderRepo := newMockReminderRepository()
@@ -557,8 +1515,8 @@ func TestScheduler_RefreshSchedules(t *testing.T) {
 
 	// 验证调度任务已创建
 	scheduler.mu.RLock()
-	job1Exists := scheduler.jobs[reminder1.ID] != 0
-	job2Exists := scheduler.jobs[reminder2.ID] != 0
+	job1Exists := len(scheduler.jobs[reminder1.ID]) > 0
+	job2Exists := len(scheduler.jobs[reminder2.ID]) > 0
 	activeCount := len(scheduler.jobs) + len(scheduler.onceTimers)
 	scheduler.mu.RUnlock()
 
@@ -589,11 +1547,105 @@ func TestScheduler_MonthlyPattern(t *testing.T) {
 		IsActive:        true,
 	}
 
-	// 注意：当前实现不支持monthly模式，预期会失败
-	err := scheduler.AddReminder(reminder)
-	if err == nil {
-		t.Log("警告: monthly模式目前不支持，但没有返回错误")
-		scheduler.RemoveReminder(reminder.ID)
+	if err := scheduler.AddReminder(reminder); err != nil {
+		t.Fatalf("AddReminder() 失败: %v", err)
+	}
+
+	scheduler.mu.RLock()
+	_, exists := scheduler.jobs[reminder.ID]
+	scheduler.mu.RUnlock()
+	if !exists {
+		t.Fatal("期待创建每月调度任务")
+	}
+
+	scheduler.RemoveReminder(reminder.ID)
+}
+
+// TestScheduler_CalendarScheduleNext 测试每月/每年模式的触发时间计算
+func TestScheduler_CalendarScheduleNext(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	scheduler := &schedulerService{location: loc}
+
+	tests := []struct {
+		name    string
+		pattern string
+		from    time.Time
+		want    time.Time
+	}{
+		{
+			name:    "每月指定日期",
+			pattern: "monthly:1,15",
+			from:    time.Date(2026, 1, 15, 10, 0, 0, 0, loc),
+			want:    time.Date(2026, 2, 1, 9, 0, 0, 0, loc),
+		},
+		{
+			name:    "当天尚未到点",
+			pattern: "monthly:1,15",
+			from:    time.Date(2026, 1, 15, 8, 0, 0, 0, loc),
+			want:    time.Date(2026, 1, 15, 9, 0, 0, 0, loc),
+		},
+		{
+			name:    "31日跳过小月",
+			pattern: "monthly:31",
+			from:    time.Date(2026, 1, 31, 10, 0, 0, 0, loc),
+			want:    time.Date(2026, 3, 31, 9, 0, 0, 0, loc),
+		},
+		{
+			name:    "每月最后一天-二月",
+			pattern: "monthly:L",
+			from:    time.Date(2026, 2, 1, 0, 0, 0, 0, loc),
+			want:    time.Date(2026, 2, 28, 9, 0, 0, 0, loc),
+		},
+		{
+			name:    "每月最后一天-闰年二月",
+			pattern: "monthly:L",
+			from:    time.Date(2028, 2, 1, 0, 0, 0, 0, loc),
+			want:    time.Date(2028, 2, 29, 9, 0, 0, 0, loc),
+		},
+		{
+			name:    "每月第一个周一",
+			pattern: "monthly:1#1",
+			from:    time.Date(2026, 3, 3, 0, 0, 0, 0, loc),
+			want:    time.Date(2026, 4, 6, 9, 0, 0, 0, loc),
+		},
+		{
+			name:    "每月最后一个周五",
+			pattern: "monthly:5#L",
+			from:    time.Date(2026, 10, 1, 0, 0, 0, 0, loc),
+			want:    time.Date(2026, 10, 30, 9, 0, 0, 0, loc),
+		},
+		{
+			name:    "每年指定日期",
+			pattern: "yearly:12-25",
+			from:    time.Date(2026, 12, 26, 0, 0, 0, 0, loc),
+			want:    time.Date(2027, 12, 25, 9, 0, 0, 0, loc),
+		},
+		{
+			name:    "每年2月29日只在闰年触发",
+			pattern: "yearly:02-29",
+			from:    time.Date(2026, 1, 1, 0, 0, 0, 0, loc),
+			want:    time.Date(2028, 2, 29, 9, 0, 0, 0, loc),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := scheduler.buildSchedule(&models.Reminder{
+				SchedulePattern: tt.pattern,
+				TargetTime:      "09:00:00",
+			})
+			if err != nil {
+				t.Fatalf("buildSchedule() 失败: %v", err)
+			}
+
+			if got := schedule.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := scheduler.buildSchedule(&models.Reminder{SchedulePattern: "monthly:0", TargetTime: "09:00:00"}); err == nil {
+		t.Error("buildSchedule() 期待无效每月模式返回错误")
 	}
 }
 
@@ -898,3 +1950,759 @@ func findSubstringHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestScheduler_NextOccurrences(t *testing.T) {
+	scheduler := &schedulerService{location: time.UTC}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("缺少时区数据")
+	}
+	// 东京时间 2026-05-01 10:00（周五）
+	now := time.Date(2026, 5, 1, 1, 0, 0, 0, time.UTC)
+	pausedUntil := time.Date(2026, 5, 3, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		reminder *models.Reminder
+		n        int
+		want     []string
+	}{
+		{
+			name:     "按提醒时区计算",
+			reminder: &models.Reminder{SchedulePattern: "daily", TargetTime: "09:00:00", Timezone: "Asia/Tokyo", IsActive: true},
+			n:        2,
+			want:     []string{"2026-05-02 09:00", "2026-05-03 09:00"},
+		},
+		{
+			name:     "多个时间按先后合并",
+			reminder: &models.Reminder{SchedulePattern: "daily", TargetTime: "09:00:00", ExtraTimes: "21:00:00", Timezone: "Asia/Tokyo", IsActive: true},
+			n:        3,
+			want:     []string{"2026-05-01 21:00", "2026-05-02 09:00", "2026-05-02 21:00"},
+		},
+		{
+			name:     "跳过暂停期间",
+			reminder: &models.Reminder{SchedulePattern: "daily", TargetTime: "09:00:00", Timezone: "Asia/Tokyo", IsActive: true, PausedUntil: &pausedUntil},
+			n:        1,
+			want:     []string{"2026-05-04 09:00"},
+		},
+		{
+			name:     "截止日期",
+			reminder: &models.Reminder{SchedulePattern: "daily", TargetTime: "09:00:00", Timezone: "Asia/Tokyo", IsActive: true, UntilDate: "2026-05-03"},
+			n:        5,
+			want:     []string{"2026-05-02 09:00", "2026-05-03 09:00"},
+		},
+		{
+			name:     "剩余次数",
+			reminder: &models.Reminder{SchedulePattern: "daily", TargetTime: "09:00:00", Timezone: "Asia/Tokyo", IsActive: true, MaxOccurrences: 5, OccurrenceCount: 4},
+			n:        3,
+			want:     []string{"2026-05-02 09:00"},
+		},
+		{
+			name:     "一次性提醒",
+			reminder: &models.Reminder{SchedulePattern: "once:2026-05-01", TargetTime: "08:00:00", ExtraTimes: "18:00:00", Timezone: "Asia/Tokyo", IsActive: true},
+			n:        3,
+			want:     []string{"2026-05-01 18:00"},
+		},
+		{
+			name:     "已停用",
+			reminder: &models.Reminder{SchedulePattern: "daily", TargetTime: "09:00:00", IsActive: false},
+			n:        3,
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			occurrences, err := scheduler.NextOccurrences(tt.reminder, now, tt.n)
+			if err != nil {
+				t.Fatalf("NextOccurrences() 失败: %v", err)
+			}
+
+			var got []string
+			for _, occurrence := range occurrences {
+				if tt.reminder.Timezone != "" && occurrence.Location().String() != tokyo.String() {
+					t.Errorf("应按提醒时区返回，实际 %s", occurrence.Location())
+				}
+				got = append(got, occurrence.Format("2006-01-02 15:04"))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("NextOccurrences() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduler_SimulatedClock(t *testing.T) {
+	reminderRepo := newMockReminderRepository()
+	logRepo := newMockReminderLogRepository()
+	notification := newMockNotificationService()
+	ctx := context.Background()
+
+	start := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	simulated := clock.NewSimulated(start)
+
+	daily := &models.Reminder{
+		UserID:          1,
+		Title:           "喝水",
+		SchedulePattern: "daily",
+		TargetTime:      "09:00:00",
+		IsActive:        true,
+		CreatedAt:       start,
+	}
+	once := &models.Reminder{
+		UserID:          1,
+		Title:           "取快递",
+		SchedulePattern: "once:2026-05-02",
+		TargetTime:      "15:00:00",
+		IsActive:        true,
+		CreatedAt:       start,
+	}
+	reminderRepo.Create(ctx, daily)
+	reminderRepo.Create(ctx, once)
+
+	scheduler := NewSchedulerService(reminderRepo, logRepo, notification).(*schedulerService)
+	scheduler.location = time.UTC
+	scheduler.SetClock(simulated)
+	if err := scheduler.Start(); err != nil {
+		t.Fatalf("Start() 失败: %v", err)
+	}
+	defer scheduler.Stop()
+
+	simulated.Advance(3 * 24 * time.Hour)
+
+	if len(notification.sentReminders) != 4 {
+		t.Fatalf("3天内应发送3次每日提醒和1次一次性提醒，实际 %d", len(notification.sentReminders))
+	}
+
+	var sentAt []string
+	for _, id := range notification.sentReminders {
+		log, _ := logRepo.GetByID(ctx, id)
+		sentAt = append(sentAt, log.ScheduledTime.In(time.UTC).Format("01-02 15:04"))
+	}
+	want := []string{"05-01 09:00", "05-02 09:00", "05-02 15:00", "05-03 09:00"}
+	if fmt.Sprint(sentAt) != fmt.Sprint(want) {
+		t.Errorf("触发时间 = %v, want %v", sentAt, want)
+	}
+
+	if got, _ := reminderRepo.GetByID(ctx, once.ID); got.IsActive {
+		t.Error("一次性提醒触发后应停用")
+	}
+}
+
+func TestScheduler_ExecuteReminderIsIdempotent(t *testing.T) {
+	reminderRepo := newMockReminderRepository()
+	logRepo := newMockReminderLogRepository()
+	notification := newMockNotificationService()
+	ctx := context.Background()
+
+	scheduler := NewSchedulerService(reminderRepo, logRepo, notification).(*schedulerService)
+
+	reminder := &models.Reminder{
+		UserID:          1,
+		Title:           "喝水",
+		SchedulePattern: "daily",
+		TargetTime:      "09:00:00",
+		IsActive:        true,
+	}
+	reminderRepo.Create(ctx, reminder)
+
+	occurrence := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	// 刷新调度与旧任务并发时同一次触发会被执行多次，时刻的时区和亚秒部分可能不同
+	scheduler.executeReminder(reminder.ID, occurrence)
+	scheduler.executeReminder(reminder.ID, occurrence.In(time.FixedZone("UTC+8", 8*3600)))
+	scheduler.executeReminder(reminder.ID, occurrence.Add(500*time.Millisecond))
+
+	if len(notification.sentReminders) != 1 {
+		t.Fatalf("同一次触发只应发送一次，实际 %d", len(notification.sentReminders))
+	}
+	stored, _ := reminderRepo.GetByID(ctx, reminder.ID)
+	if stored.OccurrenceCount != 1 {
+		t.Errorf("触发次数应为1，实际 %d", stored.OccurrenceCount)
+	}
+
+	scheduler.executeReminder(reminder.ID, occurrence.AddDate(0, 0, 1))
+	if len(notification.sentReminders) != 2 {
+		t.Errorf("下一次触发应正常发送，实际共 %d 条", len(notification.sentReminders))
+	}
+}
+
+func TestOccurrenceAt(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	scheduler := &schedulerService{location: loc}
+	schedule, err := scheduler.buildSchedule(&models.Reminder{SchedulePattern: "daily", TargetTime: "09:00:00"})
+	if err != nil {
+		t.Fatalf("buildSchedule() 失败: %v", err)
+	}
+
+	planned := time.Date(2026, 5, 1, 9, 0, 0, 0, loc)
+	tests := []struct {
+		name  string
+		fired time.Time
+		want  time.Time
+	}{
+		{"准时触发", planned, planned},
+		{"毫秒级延迟", planned.Add(3 * time.Millisecond), planned},
+		{"排队等待", planned.Add(30 * time.Second), planned},
+		{"超出容忍范围", planned.Add(2*time.Minute + 400*time.Millisecond), planned.Add(2 * time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := occurrenceAt(schedule, tt.fired); !got.Equal(tt.want) {
+				t.Errorf("occurrenceAt() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduler_RefreshSchedulesIsIncremental(t *testing.T) {
+	reminderRepo := newMockReminderRepository()
+	ctx := context.Background()
+
+	start := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	simulated := clock.NewSimulated(start)
+
+	newDaily := func(title, targetTime string) *models.Reminder {
+		reminder := &models.Reminder{
+			UserID:          1,
+			Title:           title,
+			SchedulePattern: "daily",
+			TargetTime:      targetTime,
+			IsActive:        true,
+			CreatedAt:       start,
+			UpdatedAt:       start,
+		}
+		reminderRepo.Create(ctx, reminder)
+		return reminder
+	}
+	unchanged := newDaily("喝水", "09:00:00")
+	edited := newDaily("运动", "18:00:00")
+	deactivated := newDaily("读书", "21:00:00")
+
+	scheduler := NewSchedulerService(reminderRepo, newMockReminderLogRepository(), newMockNotificationService()).(*schedulerService)
+	scheduler.location = time.UTC
+	scheduler.SetClock(simulated)
+	if err := scheduler.SetReconcileInterval(time.Minute); err != nil {
+		t.Fatalf("SetReconcileInterval() 失败: %v", err)
+	}
+	if err := scheduler.Start(); err != nil {
+		t.Fatalf("Start() 失败: %v", err)
+	}
+	defer scheduler.Stop()
+
+	jobOf := func(id uint) *clockJob {
+		scheduler.mu.RLock()
+		defer scheduler.mu.RUnlock()
+		if jobs := scheduler.clockJobs[id]; len(jobs) > 0 {
+			return jobs[0]
+		}
+		return nil
+	}
+	unchangedJob, editedJob := jobOf(unchanged.ID), jobOf(edited.ID)
+
+	// 模拟其他进程直接修改数据库
+	edited.TargetTime = "19:00:00"
+	edited.UpdatedAt = start.Add(time.Second)
+	deactivated.IsActive = false
+	added := newDaily("冥想", "07:00:00")
+
+	// 定期同步在一个间隔后生效
+	simulated.Advance(time.Minute)
+
+	if jobOf(unchanged.ID) != unchangedJob {
+		t.Error("未变更的提醒不应重新调度")
+	}
+	if job := jobOf(edited.ID); job == nil || job == editedJob {
+		t.Error("已变更的提醒应重新调度")
+	} else if want := time.Date(2026, 5, 1, 19, 0, 0, 0, time.UTC); !job.next.Equal(want) {
+		t.Errorf("已变更的提醒下次触发 %s，期望 %s", job.next, want)
+	}
+	if jobOf(deactivated.ID) != nil {
+		t.Error("已停用的提醒应移除调度")
+	}
+	if jobOf(added.ID) == nil {
+		t.Error("新增的提醒应加入调度")
+	}
+
+	// 默认时区变化会影响未设置时区的提醒
+	if err := scheduler.SetDefaultTimezone("Asia/Tokyo"); err != nil {
+		t.Skip("缺少时区数据")
+	}
+	if err := scheduler.RefreshSchedules(); err != nil {
+		t.Fatalf("RefreshSchedules() 失败: %v", err)
+	}
+	if jobOf(unchanged.ID) == unchangedJob {
+		t.Error("时区变化后应重新调度")
+	}
+}
+
+func TestScheduler_FollowUpPolicy(t *testing.T) {
+	reminderRepo := newMockReminderRepository()
+	logRepo := newMockReminderLogRepository()
+	notification := newMockNotificationService()
+	ctx := context.Background()
+
+	start := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	simulated := clock.NewSimulated(start)
+
+	ignored := &models.Reminder{
+		UserID:          1,
+		Title:           "交周报",
+		SchedulePattern: "once:2026-05-01",
+		TargetTime:      "09:00:00",
+		IsActive:        true,
+		CreatedAt:       start,
+	}
+	answered := &models.Reminder{
+		UserID:          1,
+		Title:           "取快递",
+		SchedulePattern: "once:2026-05-01",
+		TargetTime:      "12:00:00",
+		IsActive:        true,
+		CreatedAt:       start,
+	}
+	reminderRepo.Create(ctx, ignored)
+	reminderRepo.Create(ctx, answered)
+
+	policy, err := models.ParseFollowUpPolicy("15m,1h;then=skipped")
+	if err != nil {
+		t.Fatalf("ParseFollowUpPolicy() 失败: %v", err)
+	}
+
+	scheduler := NewSchedulerService(reminderRepo, logRepo, notification).(*schedulerService)
+	scheduler.location = time.UTC
+	scheduler.SetClock(simulated)
+	scheduler.SetFollowUpPolicy(policy)
+	if err := scheduler.Start(); err != nil {
+		t.Fatalf("Start() 失败: %v", err)
+	}
+	defer scheduler.Stop()
+
+	// 09:00 发送，09:15 第一次关怀，10:15 最后一次关怀，11:15 仍未回复记为已跳过
+	simulated.AdvanceTo(time.Date(2026, 5, 1, 11, 30, 0, 0, time.UTC))
+
+	if len(notification.sentReminders) != 1 {
+		t.Fatalf("应发送1次提醒，实际 %d", len(notification.sentReminders))
+	}
+	ignoredLog, _ := logRepo.GetByID(ctx, notification.sentReminders[0])
+	if len(notification.sentFollowUps) != 2 || len(notification.finalFollowUp) != 1 {
+		t.Fatalf("应发送2次关怀且最后一次标记为最后提醒，实际 %v / %v", notification.sentFollowUps, notification.finalFollowUp)
+	}
+	if ignoredLog.FollowUpCount != 2 || !ignoredLog.LastFollowUpAt.Equal(time.Date(2026, 5, 1, 10, 15, 0, 0, time.UTC)) {
+		t.Errorf("关怀次数 = %d, 最近关怀时间 = %v", ignoredLog.FollowUpCount, ignoredLog.LastFollowUpAt)
+	}
+	if ignoredLog.Status != models.ReminderStatusSkipped {
+		t.Errorf("关怀用完后状态 = %s, want %s", ignoredLog.Status, models.ReminderStatusSkipped)
+	}
+
+	// 12:00 发送，12:15 关怀一次后用户回复，之后不再关怀
+	simulated.AdvanceTo(time.Date(2026, 5, 1, 12, 20, 0, 0, time.UTC))
+	if len(notification.sentReminders) != 2 || len(notification.sentFollowUps) != 3 {
+		t.Fatalf("12:20 前应发送2次提醒和3次关怀，实际 %d / %d", len(notification.sentReminders), len(notification.sentFollowUps))
+	}
+	answeredLog, _ := logRepo.GetByID(ctx, notification.sentReminders[1])
+	answeredLog.MarkAsCompleted("done")
+
+	simulated.Advance(24 * time.Hour)
+	if len(notification.sentFollowUps) != 3 {
+		t.Errorf("回复后不应继续关怀，实际共 %d 次", len(notification.sentFollowUps))
+	}
+	if _, pending := scheduler.followUpTimers[answeredLog.ID]; pending {
+		t.Error("回复后的关怀定时器应已清理")
+	}
+}
+
+func TestScheduler_ResumeWhenPauseExpires(t *testing.T) {
+	reminderRepo := newMockReminderRepository()
+	logRepo := newMockReminderLogRepository()
+	notification := newMockNotificationService()
+	ctx := context.Background()
+
+	start := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	simulated := clock.NewSimulated(start)
+
+	pausedUntil := time.Date(2026, 5, 3, 8, 0, 0, 0, time.UTC)
+	reminder := &models.Reminder{
+		UserID:          1,
+		Title:           "晨跑",
+		SchedulePattern: "daily",
+		TargetTime:      "09:00:00",
+		IsActive:        true,
+		PausedUntil:     &pausedUntil,
+		PauseReason:     "出差",
+		CreatedAt:       start,
+	}
+	reminderRepo.Create(ctx, reminder)
+
+	scheduler := NewSchedulerService(reminderRepo, logRepo, notification).(*schedulerService)
+	scheduler.location = time.UTC
+	scheduler.SetClock(simulated)
+	if err := scheduler.Start(); err != nil {
+		t.Fatalf("Start() 失败: %v", err)
+	}
+	defer scheduler.Stop()
+
+	simulated.AdvanceTo(time.Date(2026, 5, 3, 8, 30, 0, 0, time.UTC))
+	if len(notification.sentReminders) != 0 {
+		t.Fatalf("暂停期间不应发送提醒，实际 %d", len(notification.sentReminders))
+	}
+	if len(notification.sentResumed) != 1 || notification.sentResumed[0] != reminder.ID {
+		t.Fatalf("暂停到期应发送1次恢复通知，实际 %v", notification.sentResumed)
+	}
+	stored, _ := reminderRepo.GetByID(ctx, reminder.ID)
+	if stored.PausedUntil != nil || stored.PauseReason != "" {
+		t.Errorf("恢复后应清除暂停状态，PausedUntil=%v, PauseReason=%q", stored.PausedUntil, stored.PauseReason)
+	}
+
+	simulated.AdvanceTo(time.Date(2026, 5, 4, 9, 30, 0, 0, time.UTC))
+	if len(notification.sentReminders) != 2 {
+		t.Errorf("恢复后应按原计划每天提醒，实际 %d 次", len(notification.sentReminders))
+	}
+	if len(notification.sentResumed) != 1 {
+		t.Errorf("恢复通知只应发送一次，实际 %v", notification.sentResumed)
+	}
+}
+
+// TestScheduler_ResumeNoticeQuietly 测试恢复通知遵守免打扰时段，静默暂停到期后不通知
+func TestScheduler_ResumeNoticeQuietly(t *testing.T) {
+	reminderRepo := newMockReminderRepository()
+	notification := newMockNotificationService()
+	ctx := context.Background()
+
+	start := time.Date(2026, 5, 1, 20, 0, 0, 0, time.UTC)
+	simulated := clock.NewSimulated(start)
+
+	user := models.User{ID: 1, Timezone: "UTC", QuietHours: "22:00-07:00"}
+	pausedUntil := time.Date(2026, 5, 1, 23, 0, 0, 0, time.UTC)
+	newPaused := func(title string, quiet bool) *models.Reminder {
+		until := pausedUntil
+		reminder := &models.Reminder{
+			UserID: 1, User: user, Title: title,
+			SchedulePattern: "daily", TargetTime: "12:00:00",
+			IsActive: true, PausedUntil: &until, QuietResume: quiet, CreatedAt: start,
+		}
+		reminderRepo.Create(ctx, reminder)
+		return reminder
+	}
+	noisy := newPaused("晨跑", false)
+	silent := newPaused("复盘", true)
+
+	scheduler := NewSchedulerService(reminderRepo, newMockReminderLogRepository(), notification).(*schedulerService)
+	scheduler.location = time.UTC
+	scheduler.SetClock(simulated)
+	if err := scheduler.Start(); err != nil {
+		t.Fatalf("Start() 失败: %v", err)
+	}
+	defer scheduler.Stop()
+
+	simulated.AdvanceTo(time.Date(2026, 5, 2, 6, 59, 0, 0, time.UTC))
+	if len(notification.sentResumed) != 0 {
+		t.Fatalf("免打扰时段内不应发送恢复通知，实际 %v", notification.sentResumed)
+	}
+	for _, reminder := range []*models.Reminder{noisy, silent} {
+		if stored, _ := reminderRepo.GetByID(ctx, reminder.ID); stored.PausedUntil != nil || stored.QuietResume {
+			t.Errorf("暂停到期后应清除暂停状态 (ID: %d)", reminder.ID)
+		}
+	}
+
+	simulated.AdvanceTo(time.Date(2026, 5, 2, 7, 1, 0, 0, time.UTC))
+	if len(notification.sentResumed) != 1 || notification.sentResumed[0] != noisy.ID {
+		t.Errorf("免打扰结束后只应通知非静默暂停的提醒，实际 %v", notification.sentResumed)
+	}
+}
+
+func TestScheduler_QuietHours(t *testing.T) {
+	reminderRepo := newMockReminderRepository()
+	logRepo := newMockReminderLogRepository()
+	notification := newMockNotificationService()
+	ctx := context.Background()
+
+	// 2026-05-01 是周五，周五晚上按工作日时段，周六晚上按周末时段
+	start := time.Date(2026, 5, 1, 20, 0, 0, 0, time.UTC)
+	simulated := clock.NewSimulated(start)
+
+	user := models.User{ID: 1, Timezone: "UTC", QuietHours: "22:00-07:00;weekend=23:00-09:00"}
+	normal := &models.Reminder{
+		UserID:          1,
+		User:            user,
+		Title:           "写日记",
+		SchedulePattern: "daily",
+		TargetTime:      "23:30:00",
+		IsActive:        true,
+		CreatedAt:       start,
+	}
+	critical := &models.Reminder{
+		UserID:          1,
+		User:            user,
+		Title:           "吃药",
+		SchedulePattern: "daily",
+		TargetTime:      "23:30:00",
+		IsActive:        true,
+		Critical:        true,
+		CreatedAt:       start,
+	}
+	reminderRepo.Create(ctx, normal)
+	reminderRepo.Create(ctx, critical)
+
+	scheduler := NewSchedulerService(reminderRepo, logRepo, notification).(*schedulerService)
+	scheduler.location = time.UTC
+	scheduler.SetClock(simulated)
+	scheduler.SetFollowUpPolicy(models.FollowUpPolicy{Disabled: true})
+	if err := scheduler.Start(); err != nil {
+		t.Fatalf("Start() 失败: %v", err)
+	}
+	defer scheduler.Stop()
+
+	sentFor := func() []string {
+		var sent []string
+		for _, id := range notification.sentReminders {
+			log, _ := logRepo.GetByID(ctx, id)
+			sent = append(sent, fmt.Sprintf("%d@%s", log.ReminderID, log.SentTime.In(time.UTC).Format("01-02 15:04")))
+		}
+		return sent
+	}
+
+	simulated.AdvanceTo(time.Date(2026, 5, 2, 6, 0, 0, 0, time.UTC))
+	if got, want := sentFor(), []string{fmt.Sprintf("%d@05-01 23:30", critical.ID)}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("免打扰时段内只应发送重要提醒，got %v, want %v", got, want)
+	}
+
+	// 工作日时段 07:00 结束，周六晚上按周末时段推迟到周日 09:00
+	simulated.AdvanceTo(time.Date(2026, 5, 3, 10, 0, 0, 0, time.UTC))
+	want := []string{
+		fmt.Sprintf("%d@05-01 23:30", critical.ID),
+		fmt.Sprintf("%d@05-02 07:00", normal.ID),
+		fmt.Sprintf("%d@05-02 23:30", critical.ID),
+		fmt.Sprintf("%d@05-03 09:00", normal.ID),
+	}
+	if got := sentFor(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("发送顺序 = %v, want %v", got, want)
+	}
+}
+
+func TestScheduler_LeadNotices(t *testing.T) {
+	reminderRepo := newMockReminderRepository()
+	logRepo := newMockReminderLogRepository()
+	notification := newMockNotificationService()
+	ctx := context.Background()
+
+	start := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	simulated := clock.NewSimulated(start)
+
+	user := models.User{ID: 1, Timezone: "UTC"}
+	daily := &models.Reminder{
+		UserID:          1,
+		User:            user,
+		Title:           "站会",
+		SchedulePattern: "daily",
+		TargetTime:      "09:00:00",
+		LeadTimes:       "10m",
+		IsActive:        true,
+		CreatedAt:       start,
+	}
+	dentist := &models.Reminder{
+		UserID:          1,
+		User:            user,
+		Title:           "看牙医",
+		Type:            models.ReminderTypeTask,
+		SchedulePattern: "once:2026-05-02",
+		TargetTime:      "15:00:00",
+		LeadTimes:       "30m,1d,2d", // 提前2天的通知在创建时已经过了，不补发
+		IsActive:        true,
+		CreatedAt:       start,
+	}
+	reminderRepo.Create(ctx, daily)
+	reminderRepo.Create(ctx, dentist)
+
+	scheduler := NewSchedulerService(reminderRepo, logRepo, notification).(*schedulerService)
+	scheduler.location = time.UTC
+	scheduler.SetClock(simulated)
+	scheduler.SetFollowUpPolicy(models.FollowUpPolicy{Disabled: true})
+	if err := scheduler.Start(); err != nil {
+		t.Fatalf("Start() 失败: %v", err)
+	}
+	defer scheduler.Stop()
+
+	simulated.AdvanceTo(time.Date(2026, 5, 2, 16, 0, 0, 0, time.UTC))
+
+	var got []string
+	for _, lead := range notification.sentLeads {
+		got = append(got, fmt.Sprintf("%d@%s-%s", lead.reminderID, lead.occurrence.In(time.UTC).Format("01-02 15:04"), lead.offset))
+	}
+	want := []string{
+		fmt.Sprintf("%d@05-01 09:00-10m0s", daily.ID),
+		fmt.Sprintf("%d@05-02 15:00-24h0m0s", dentist.ID),
+		fmt.Sprintf("%d@05-02 09:00-10m0s", daily.ID),
+		fmt.Sprintf("%d@05-02 15:00-30m0s", dentist.ID),
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("提前通知 = %v, want %v", got, want)
+	}
+
+	// 提前通知不创建提醒记录，只有正式提醒计入统计
+	if len(notification.sentReminders) != 3 || len(logRepo.logs) != 3 {
+		t.Errorf("正式提醒 = %d, 提醒记录 = %d, want 3", len(notification.sentReminders), len(logRepo.logs))
+	}
+}
+
+func TestScheduler_DailyAgenda(t *testing.T) {
+	reminderRepo := newMockReminderRepository()
+	logRepo := newMockReminderLogRepository()
+	logRepo.reminderRepo = reminderRepo
+	notification := newMockNotificationService()
+	ctx := context.Background()
+
+	start := time.Date(2026, 5, 2, 6, 0, 0, 0, time.UTC)
+	simulated := clock.NewSimulated(start)
+
+	user := models.User{ID: 1, Timezone: "UTC", AgendaTime: "07:30"}
+	standup := &models.Reminder{
+		UserID: 1, User: user, Title: "站会",
+		SchedulePattern: "daily", TargetTime: "09:00:00",
+		IsActive: true, CreatedAt: start.AddDate(0, 0, -7),
+	}
+	review := &models.Reminder{
+		UserID: 1, User: user, Title: "复盘",
+		SchedulePattern: "daily", TargetTime: "20:00:00",
+		IsActive: true, CreatedAt: start.AddDate(0, 0, -7),
+	}
+	dentist := &models.Reminder{
+		UserID: 1, User: user, Title: "看牙医",
+		SchedulePattern: "once:2026-05-03", TargetTime: "15:00:00",
+		IsActive: true, CreatedAt: start,
+	}
+	for _, reminder := range []*models.Reminder{standup, review, dentist} {
+		reminderRepo.Create(ctx, reminder)
+	}
+
+	// 昨天：站会跳过了，复盘已完成
+	yesterday := start.AddDate(0, 0, -1)
+	logRepo.Create(ctx, &models.ReminderLog{ReminderID: standup.ID, ScheduledTime: yesterday.Add(3 * time.Hour), OccurrenceAt: models.OccurrenceKey(yesterday.Add(3 * time.Hour)), Status: models.ReminderStatusSkipped})
+	logRepo.Create(ctx, &models.ReminderLog{ReminderID: review.ID, ScheduledTime: yesterday.Add(14 * time.Hour), OccurrenceAt: models.OccurrenceKey(yesterday.Add(14 * time.Hour)), Status: models.ReminderStatusCompleted})
+
+	scheduler := NewSchedulerService(reminderRepo, logRepo, notification).(*schedulerService)
+	scheduler.location = time.UTC
+	scheduler.SetClock(simulated)
+	scheduler.SetFollowUpPolicy(models.FollowUpPolicy{Disabled: true})
+	if err := scheduler.Start(); err != nil {
+		t.Fatalf("Start() 失败: %v", err)
+	}
+	defer scheduler.Stop()
+
+	describe := func(agenda *Agenda) string {
+		var parts []string
+		for _, item := range agenda.Upcoming {
+			parts = append(parts, item.Occurrence.Format("01-02 15:04 ")+item.Reminder.Title)
+		}
+		parts = append(parts, "|")
+		for _, log := range agenda.Missed {
+			parts = append(parts, fmt.Sprintf("%s %s %s", log.ScheduledTime.Format("01-02 15:04"), log.Reminder.Title, log.Status))
+		}
+		return strings.Join(parts, ",")
+	}
+
+	simulated.AdvanceTo(time.Date(2026, 5, 2, 7, 30, 0, 0, time.UTC))
+	if len(notification.sentAgendas) != 1 {
+		t.Fatalf("07:30 应推送 1 份日程，实际为 %d", len(notification.sentAgendas))
+	}
+	want := "05-02 09:00 站会,05-02 20:00 复盘,|,05-01 09:00 站会 skipped"
+	if got := describe(notification.sentAgendas[0]); got != want {
+		t.Errorf("日程 = %s, want %s", got, want)
+	}
+
+	// 在日程中跳过今晚的复盘
+	tonight := time.Date(2026, 5, 2, 20, 0, 0, 0, time.UTC)
+	if err := scheduler.SkipOccurrence(ctx, review.ID, tonight.Add(time.Minute)); err == nil {
+		t.Error("不是计划触发时间时应返回错误")
+	}
+	if err := scheduler.SkipOccurrence(ctx, review.ID, tonight); err != nil {
+		t.Fatalf("SkipOccurrence() 失败: %v", err)
+	}
+	if err := scheduler.SkipOccurrence(ctx, review.ID, tonight); err == nil {
+		t.Error("重复跳过应返回错误")
+	}
+
+	simulated.AdvanceTo(time.Date(2026, 5, 3, 7, 30, 0, 0, time.UTC))
+	if len(notification.sentReminders) != 1 {
+		t.Errorf("跳过后当天只应发送站会提醒，实际发送 %d 条", len(notification.sentReminders))
+	}
+	if len(notification.sentAgendas) != 2 {
+		t.Fatalf("次日应再推送 1 份日程，实际共 %d 份", len(notification.sentAgendas))
+	}
+	want = "05-03 09:00 站会,05-03 15:00 看牙医,05-03 20:00 复盘,|,05-02 09:00 站会 sent,05-02 20:00 复盘 skipped"
+	if got := describe(notification.sentAgendas[1]); got != want {
+		t.Errorf("次日日程 = %s, want %s", got, want)
+	}
+
+	// 关闭后不再推送，定期同步读到的用户设置同样已关闭
+	for _, reminder := range []*models.Reminder{standup, review, dentist} {
+		reminder.User.AgendaTime = ""
+	}
+	scheduler.ScheduleAgenda(&models.User{ID: 1, Timezone: "UTC"})
+	simulated.AdvanceTo(time.Date(2026, 5, 4, 8, 0, 0, 0, time.UTC))
+	if len(notification.sentAgendas) != 2 {
+		t.Errorf("关闭日程后不应再推送，实际共 %d 份", len(notification.sentAgendas))
+	}
+}
+
+// TestScheduler_AgendaPauseResumesQuietly 测试日程中"今天暂停"到当天结束时静默恢复，午夜不发送恢复通知
+func TestScheduler_AgendaPauseResumesQuietly(t *testing.T) {
+	reminderRepo := newMockReminderRepository()
+	logRepo := newMockReminderLogRepository()
+	logRepo.reminderRepo = reminderRepo
+	notification := newMockNotificationService()
+	ctx := context.Background()
+
+	start := time.Date(2026, 5, 2, 7, 0, 0, 0, time.UTC)
+	simulated := clock.NewSimulated(start)
+
+	user := models.User{ID: 1, Timezone: "UTC"}
+	standup := &models.Reminder{
+		UserID: 1, User: user, Title: "站会",
+		SchedulePattern: "daily", TargetTime: "09:00:00",
+		IsActive: true, CreatedAt: start,
+	}
+	reminderRepo.Create(ctx, standup)
+
+	scheduler := NewSchedulerService(reminderRepo, logRepo, notification).(*schedulerService)
+	scheduler.location = time.UTC
+	scheduler.SetClock(simulated)
+	scheduler.SetFollowUpPolicy(models.FollowUpPolicy{Disabled: true})
+	if err := scheduler.Start(); err != nil {
+		t.Fatalf("Start() 失败: %v", err)
+	}
+	defer scheduler.Stop()
+
+	agenda, err := scheduler.BuildAgenda(ctx, &user, simulated.Now())
+	if err != nil {
+		t.Fatalf("BuildAgenda() 失败: %v", err)
+	}
+
+	// 与"今天暂停"按钮相同：暂停到日程当天结束，到期静默恢复
+	end := agenda.End()
+	standup.PausedUntil = &end
+	standup.QuietResume = true
+	if err := reminderRepo.Update(ctx, standup); err != nil {
+		t.Fatalf("Update() 失败: %v", err)
+	}
+	if err := scheduler.AddReminder(standup); err != nil {
+		t.Fatalf("AddReminder() 失败: %v", err)
+	}
+
+	simulated.AdvanceTo(end.Add(time.Hour))
+	if len(notification.sentResumed) != 0 {
+		t.Errorf("当天结束时不应发送恢复通知，实际 %v", notification.sentResumed)
+	}
+	if len(notification.sentReminders) != 0 {
+		t.Errorf("暂停当天不应提醒，实际 %d 条", len(notification.sentReminders))
+	}
+	if stored, _ := reminderRepo.GetByID(ctx, standup.ID); stored.PausedUntil != nil || stored.QuietResume {
+		t.Error("到期后应清除暂停状态")
+	}
+
+	simulated.AdvanceTo(end.Add(10 * time.Hour))
+	if len(notification.sentReminders) != 1 {
+		t.Errorf("次日应恢复提醒，实际 %d 条", len(notification.sentReminders))
+	}
+}
@@ -0,0 +1,366 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"mmemory/pkg/logger"
+	"mmemory/pkg/metrics"
+)
+
+const (
+	defaultSendGlobalRate   = 30          // Telegram 全局约每秒30条
+	defaultSendChatInterval = time.Second // 同一会话约每秒1条
+	defaultSendMaxRetries   = 5
+	defaultSendWorkers      = 8
+	sendBackoffBase         = 500 * time.Millisecond
+	sendBackoffMax          = 30 * time.Second
+	maxTrackedChats         = 1024 // 超过后清理已过期的会话限速记录
+)
+
+// SendQueueConfig 发送队列配置，零值使用 Telegram 的默认限制
+type SendQueueConfig struct {
+	GlobalRate   int           // 每秒最多发送的消息数
+	ChatInterval time.Duration // 同一会话两条消息的最小间隔，负数表示不限制
+	MaxRetries   int           // 429 和网络错误的最多重试次数，负数表示不重试
+	Workers      int           // 同时进行的请求数
+}
+
+type sendResult struct {
+	message tgbotapi.Message
+	err     error
+}
+
+// sendJob 排队中的消息
+type sendJob struct {
+	chattable  tgbotapi.Chattable
+	chatID     int64 // 0 表示无法识别会话，只受全局限速
+	enqueuedAt time.Time
+	notBefore  time.Time // 网络错误退避后才能重试
+	attempts   int
+	result     chan sendResult
+}
+
+// sendQueue Telegram 发送队列：按全局和单个会话的速率限制发送，遵循 429 的 retry_after，
+// 网络错误和服务端错误按指数退避重试；同一会话的消息按提交顺序逐条发送
+type sendQueue struct {
+	bot    BotAPI
+	config SendQueueConfig
+
+	mu          sync.Mutex
+	pending     []*sendJob
+	inFlight    map[int64]bool
+	chatReady   map[int64]time.Time // 会话下次可发送的时间
+	globalReady time.Time           // 全局下次可发送的时间
+	running     bool
+
+	wake  chan struct{}
+	slots chan struct{}
+	stop  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// NewSendQueue 创建发送队列，需调用 Start 启动；未启动时直接发送
+func NewSendQueue(bot BotAPI, config SendQueueConfig) SendQueue {
+	if config.GlobalRate <= 0 {
+		config.GlobalRate = defaultSendGlobalRate
+	}
+	if config.ChatInterval < 0 {
+		config.ChatInterval = 0
+	} else if config.ChatInterval == 0 {
+		config.ChatInterval = defaultSendChatInterval
+	}
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	} else if config.MaxRetries == 0 {
+		config.MaxRetries = defaultSendMaxRetries
+	}
+	if config.Workers <= 0 {
+		config.Workers = defaultSendWorkers
+	}
+
+	return &sendQueue{
+		bot:       bot,
+		config:    config,
+		inFlight:  make(map[int64]bool),
+		chatReady: make(map[int64]time.Time),
+	}
+}
+
+// Start 启动调度协程，重复调用无副作用
+func (q *sendQueue) Start() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.running {
+		return
+	}
+	q.running = true
+	q.wake = make(chan struct{}, 1)
+	q.slots = make(chan struct{}, q.config.Workers)
+	q.stop = make(chan struct{})
+
+	q.wg.Add(1)
+	go q.dispatch()
+}
+
+// Stop 停止发送，排队中的消息返回错误，等待进行中的请求结束
+func (q *sendQueue) Stop() {
+	q.mu.Lock()
+	if !q.running {
+		q.mu.Unlock()
+		return
+	}
+	q.running = false
+	close(q.stop)
+	pending := q.pending
+	q.pending = nil
+	q.mu.Unlock()
+
+	for _, job := range pending {
+		job.result <- sendResult{err: fmt.Errorf("发送队列已停止")}
+	}
+	q.wg.Wait()
+	metrics.SetTelegramSendQueueDepth(0)
+}
+
+// Send 排队发送并等待结果，调用方阻塞直到发送成功或不再重试
+func (q *sendQueue) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	job := &sendJob{
+		chattable:  c,
+		chatID:     chatIDOf(c),
+		enqueuedAt: time.Now(),
+		result:     make(chan sendResult, 1),
+	}
+
+	q.mu.Lock()
+	if !q.running {
+		q.mu.Unlock()
+		return q.bot.Send(c)
+	}
+	q.pending = append(q.pending, job)
+	metrics.SetTelegramSendQueueDepth(float64(len(q.pending)))
+	q.mu.Unlock()
+	q.notify()
+
+	result := <-job.result
+	status := "success"
+	if result.err != nil {
+		status = "error"
+	}
+	metrics.RecordTelegramSend(status, time.Since(job.enqueuedAt).Seconds())
+	return result.message, result.err
+}
+
+// QueueDepth 返回排队中的消息数量，不含进行中的请求
+func (q *sendQueue) QueueDepth() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+func (q *sendQueue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// dispatch 取出可发送的消息交给工作协程，没有可发送的消息时等到最早可发送的时间或有新消息
+func (q *sendQueue) dispatch() {
+	defer q.wg.Done()
+
+	for {
+		q.mu.Lock()
+		job, wait := q.nextLocked(time.Now())
+		q.mu.Unlock()
+
+		if job != nil {
+			select {
+			case q.slots <- struct{}{}:
+			case <-q.stop:
+				job.result <- sendResult{err: fmt.Errorf("发送队列已停止")}
+				return
+			}
+			q.wg.Add(1)
+			go q.send(job)
+			continue
+		}
+
+		var timer *time.Timer
+		var expired <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			expired = timer.C
+		}
+		select {
+		case <-q.wake:
+		case <-expired:
+		case <-q.stop:
+			return
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// nextLocked 按提交顺序找到第一条可以发送的消息并占用限速额度；没有时返回需要等待的时间，0 表示等待新事件
+func (q *sendQueue) nextLocked(now time.Time) (*sendJob, time.Duration) {
+	if len(q.pending) == 0 {
+		return nil, 0
+	}
+	if now.Before(q.globalReady) {
+		return nil, q.globalReady.Sub(now)
+	}
+
+	var wait time.Duration
+	blocked := make(map[int64]bool)
+	for i, job := range q.pending {
+		chat := job.chatID
+		if chat != 0 && (blocked[chat] || q.inFlight[chat]) {
+			continue
+		}
+
+		readyAt := job.notBefore
+		if ready, ok := q.chatReady[chat]; ok && chat != 0 && ready.After(readyAt) {
+			readyAt = ready
+		}
+		if now.Before(readyAt) {
+			// 同一会话后面的消息也要等待，保持发送顺序
+			blocked[chat] = true
+			if d := readyAt.Sub(now); wait == 0 || d < wait {
+				wait = d
+			}
+			continue
+		}
+
+		q.pending = append(q.pending[:i], q.pending[i+1:]...)
+		metrics.SetTelegramSendQueueDepth(float64(len(q.pending)))
+		if chat != 0 {
+			q.inFlight[chat] = true
+		}
+		q.globalReady = now.Add(time.Second / time.Duration(q.config.GlobalRate))
+		return job, 0
+	}
+	return nil, wait
+}
+
+func (q *sendQueue) send(job *sendJob) {
+	defer q.wg.Done()
+	defer func() { <-q.slots }()
+
+	message, err := q.bot.Send(job.chattable)
+	now := time.Now()
+
+	q.mu.Lock()
+	delete(q.inFlight, job.chatID)
+	if job.chatID != 0 {
+		q.chatReady[job.chatID] = now.Add(q.config.ChatInterval)
+		q.pruneLocked(now)
+	}
+
+	retryAfter, retryable := classifySendError(err)
+	if err != nil && retryable && job.attempts < q.config.MaxRetries && q.running {
+		job.attempts++
+		reason := "network"
+		if retryAfter > 0 {
+			reason = "rate_limited"
+			if job.chatID != 0 {
+				q.chatReady[job.chatID] = now.Add(retryAfter)
+			} else {
+				q.globalReady = now.Add(retryAfter)
+			}
+		} else {
+			job.notBefore = now.Add(sendBackoff(job.attempts))
+		}
+		// 放回队首，同一会话后面的消息继续排在它之后
+		q.pending = append([]*sendJob{job}, q.pending...)
+		metrics.SetTelegramSendQueueDepth(float64(len(q.pending)))
+		q.mu.Unlock()
+
+		metrics.RecordTelegramSendRetry(reason)
+		logger.Warnf("🔁 Telegram 发送失败，第 %d 次重试 (会话: %d, 原因: %s): %v", job.attempts, job.chatID, reason, err)
+		q.notify()
+		return
+	}
+	q.mu.Unlock()
+	q.notify()
+
+	job.result <- sendResult{message: message, err: err}
+}
+
+// pruneLocked 会话过多时清理已经可以发送的限速记录
+func (q *sendQueue) pruneLocked(now time.Time) {
+	if len(q.chatReady) <= maxTrackedChats {
+		return
+	}
+	for chat, ready := range q.chatReady {
+		if !ready.After(now) {
+			delete(q.chatReady, chat)
+		}
+	}
+}
+
+// sendBackoff 第 n 次重试前的等待时间：0.5s、1s、2s……最长30秒
+func sendBackoff(attempt int) time.Duration {
+	backoff := sendBackoffBase << (attempt - 1)
+	if backoff <= 0 || backoff > sendBackoffMax {
+		return sendBackoffMax
+	}
+	return backoff
+}
+
+// classifySendError 判断发送错误是否值得重试，429 返回 Telegram 要求的等待时间
+func classifySendError(err error) (time.Duration, bool) {
+	if err == nil {
+		return 0, false
+	}
+
+	var apiErr *tgbotapi.Error
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.RetryAfter > 0:
+			return time.Duration(apiErr.RetryAfter) * time.Second, true
+		case apiErr.Code == 429:
+			return time.Second, true
+		case apiErr.Code >= 500:
+			return 0, true
+		default:
+			return 0, false // 400、403 等重试也不会成功
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) {
+		return 0, true
+	}
+	return 0, false
+}
+
+// chatIDOf 取出消息所属的会话，用于单个会话限速
+func chatIDOf(c tgbotapi.Chattable) int64 {
+	switch msg := c.(type) {
+	case tgbotapi.MessageConfig:
+		return msg.ChatID
+	case tgbotapi.EditMessageTextConfig:
+		return msg.ChatID
+	case tgbotapi.EditMessageReplyMarkupConfig:
+		return msg.ChatID
+	case tgbotapi.DeleteMessageConfig:
+		return msg.ChatID
+	case tgbotapi.PhotoConfig:
+		return msg.ChatID
+	case tgbotapi.DocumentConfig:
+		return msg.ChatID
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// recordingBot 记录每次发送的时间，可按顺序返回预设错误
+type recordingBot struct {
+	mu     sync.Mutex
+	sends  []recordedSend
+	errors []error
+}
+
+type recordedSend struct {
+	chatID int64
+	text   string
+	at     time.Time
+}
+
+func (b *recordingBot) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	msg := c.(tgbotapi.MessageConfig)
+	b.sends = append(b.sends, recordedSend{chatID: msg.ChatID, text: msg.Text, at: time.Now()})
+	if len(b.errors) > 0 {
+		err := b.errors[0]
+		b.errors = b.errors[1:]
+		if err != nil {
+			return tgbotapi.Message{}, err
+		}
+	}
+	return tgbotapi.Message{MessageID: len(b.sends)}, nil
+}
+
+func (b *recordingBot) recorded() []recordedSend {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]recordedSend(nil), b.sends...)
+}
+
+func TestSendQueue_RateLimits(t *testing.T) {
+	bot := &recordingBot{}
+	queue := NewSendQueue(bot, SendQueueConfig{GlobalRate: 50, ChatInterval: 100 * time.Millisecond})
+	queue.Start()
+	defer queue.Stop()
+
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i, chatID := range []int64{1, 1, 1, 2} {
+		wg.Add(1)
+		go func(text string, chatID int64) {
+			defer wg.Done()
+			if _, err := queue.Send(tgbotapi.NewMessage(chatID, text)); err != nil {
+				t.Errorf("Send() error = %v", err)
+			}
+		}(string(rune('a'+i)), chatID)
+		time.Sleep(5 * time.Millisecond) // 保证提交顺序
+	}
+	wg.Wait()
+
+	sends := bot.recorded()
+	if len(sends) != 4 {
+		t.Fatalf("发送次数 = %d, want 4", len(sends))
+	}
+
+	var chat1 []recordedSend
+	for i, send := range sends {
+		if i > 0 && send.at.Sub(sends[i-1].at) < 15*time.Millisecond {
+			t.Errorf("全局间隔 %s 小于 20ms", send.at.Sub(sends[i-1].at))
+		}
+		if send.chatID == 1 {
+			chat1 = append(chat1, send)
+		} else if send.at.Sub(start) > 90*time.Millisecond {
+			t.Errorf("其他会话的消息不应等待会话1的限速，实际等待 %s", send.at.Sub(start))
+		}
+	}
+	for i := 1; i < len(chat1); i++ {
+		if chat1[i].text < chat1[i-1].text {
+			t.Errorf("同一会话应按提交顺序发送: %v", chat1)
+		}
+		if gap := chat1[i].at.Sub(chat1[i-1].at); gap < 95*time.Millisecond {
+			t.Errorf("同一会话间隔 %s 小于 100ms", gap)
+		}
+	}
+}
+
+func TestSendQueue_Retries(t *testing.T) {
+	tests := []struct {
+		name      string
+		errors    []error
+		wantErr   bool
+		wantSends int
+		minDelay  time.Duration
+	}{
+		{
+			name:      "429按retry_after等待",
+			errors:    []error{&tgbotapi.Error{Code: 429, Message: "Too Many Requests", ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 1}}},
+			wantSends: 2,
+			minDelay:  time.Second,
+		},
+		{
+			name:      "网络错误退避重试",
+			errors:    []error{&net.DNSError{Err: "timeout", IsTimeout: true}, &tgbotapi.Error{Code: 502, Message: "Bad Gateway"}},
+			wantSends: 3,
+			minDelay:  sendBackoff(1) + sendBackoff(2),
+		},
+		{
+			name:      "403不重试",
+			errors:    []error{&tgbotapi.Error{Code: 403, Message: "Forbidden: bot was blocked by the user"}},
+			wantErr:   true,
+			wantSends: 1,
+		},
+		{
+			name:      "超过重试次数后放弃",
+			errors:    []error{&net.DNSError{IsTemporary: true}, &net.DNSError{IsTemporary: true}, &net.DNSError{IsTemporary: true}},
+			wantErr:   true,
+			wantSends: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &recordingBot{errors: tt.errors}
+			queue := NewSendQueue(bot, SendQueueConfig{MaxRetries: 2, ChatInterval: -1})
+			queue.Start()
+			defer queue.Stop()
+
+			start := time.Now()
+			_, err := queue.Send(tgbotapi.NewMessage(1, "hi"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := len(bot.recorded()); got != tt.wantSends {
+				t.Errorf("发送次数 = %d, want %d", got, tt.wantSends)
+			}
+			if elapsed := time.Since(start); elapsed < tt.minDelay {
+				t.Errorf("重试等待 %s，应不少于 %s", elapsed, tt.minDelay)
+			}
+		})
+	}
+}
+
+func TestSendQueue_SendsDirectlyWhenStopped(t *testing.T) {
+	bot := &recordingBot{}
+	queue := NewSendQueue(bot, SendQueueConfig{})
+
+	if _, err := queue.Send(tgbotapi.NewMessage(1, "hi")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if len(bot.recorded()) != 1 || queue.QueueDepth() != 0 {
+		t.Errorf("未启动的队列应直接发送")
+	}
+}
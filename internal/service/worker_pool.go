@@ -0,0 +1,131 @@
+package service
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"mmemory/pkg/logger"
+	"mmemory/pkg/metrics"
+)
+
+const (
+	defaultMaxWorkers = 10
+	workerQueueFactor = 10              // 队列容量为工作线程数的倍数
+	slowQueueWait     = 5 * time.Second // 等待超过该时间时记录警告
+)
+
+// poolTask 等待执行的任务
+type poolTask struct {
+	name       string
+	fn         func()
+	enqueuedAt time.Time
+}
+
+// workerPool 固定数量工作线程的任务池，用于限制提醒执行的并发
+// 队列满时 Submit 会阻塞调用方直到有空位（延迟而不丢弃）
+type workerPool struct {
+	mu      sync.RWMutex // Submit 持有读锁发送，Stop 持有写锁关闭队列
+	tasks   chan poolTask
+	workers int
+	running bool
+	busy    int64
+	wg      sync.WaitGroup
+}
+
+// newWorkerPool 创建任务池，需调用 Start 启动工作线程
+func newWorkerPool(workers int) *workerPool {
+	if workers <= 0 {
+		workers = defaultMaxWorkers
+	}
+	return &workerPool{workers: workers}
+}
+
+// Start 启动工作线程，重复调用无副作用
+func (p *workerPool) Start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.running {
+		return
+	}
+
+	p.tasks = make(chan poolTask, p.workers*workerQueueFactor)
+	p.running = true
+	for i := 0; i < p.workers; i++ {
+		p.wg.Add(1)
+		go p.work(p.tasks)
+	}
+}
+
+// Stop 停止接收新任务，等待队列中的任务执行完毕
+func (p *workerPool) Stop() {
+	p.mu.Lock()
+	if !p.running {
+		p.mu.Unlock()
+		return
+	}
+	p.running = false
+	close(p.tasks)
+	p.mu.Unlock()
+
+	p.wg.Wait()
+	metrics.SetSchedulerQueueDepth(0)
+}
+
+// Submit 提交任务，队列已满时阻塞等待；任务池未运行时在当前 goroutine 直接执行
+func (p *workerPool) Submit(name string, fn func()) {
+	task := poolTask{name: name, fn: fn, enqueuedAt: time.Now()}
+
+	p.mu.RLock()
+	if !p.running {
+		p.mu.RUnlock()
+		p.run(task)
+		return
+	}
+
+	select {
+	case p.tasks <- task:
+	default:
+		logger.Warnf("⏳ 执行队列已满 (容量: %d)，等待空闲工作线程: %s", cap(p.tasks), name)
+		p.tasks <- task
+	}
+	metrics.SetSchedulerQueueDepth(float64(len(p.tasks)))
+	p.mu.RUnlock()
+}
+
+// QueueDepth 返回等待执行的任务数量
+func (p *workerPool) QueueDepth() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.tasks == nil {
+		return 0
+	}
+	return len(p.tasks)
+}
+
+func (p *workerPool) work(tasks <-chan poolTask) {
+	defer p.wg.Done()
+	for task := range tasks {
+		metrics.SetSchedulerQueueDepth(float64(len(tasks)))
+		p.run(task)
+	}
+}
+
+func (p *workerPool) run(task poolTask) {
+	wait := time.Since(task.enqueuedAt)
+	metrics.RecordSchedulerQueueWait(wait.Seconds())
+	if wait > slowQueueWait {
+		logger.Warnf("🐢 任务排队 %s 后才开始执行: %s", wait.Round(time.Millisecond), task.name)
+	}
+
+	metrics.SetSchedulerBusyWorkers(float64(atomic.AddInt64(&p.busy, 1)))
+	defer func() {
+		metrics.SetSchedulerBusyWorkers(float64(atomic.AddInt64(&p.busy, -1)))
+		if r := recover(); r != nil {
+			logger.Errorf("任务执行异常: %s: %v", task.name, r)
+		}
+	}()
+
+	task.fn()
+}
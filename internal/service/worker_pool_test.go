@@ -0,0 +1,131 @@
+package service
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerPool_LimitsConcurrency(t *testing.T) {
+	pool := newWorkerPool(2)
+	pool.Start()
+
+	var running, maxRunning, done int64
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pool.Submit("测试任务", func() {
+				current := atomic.AddInt64(&running, 1)
+				for {
+					max := atomic.LoadInt64(&maxRunning)
+					if current <= max || atomic.CompareAndSwapInt64(&maxRunning, max, current) {
+						break
+					}
+				}
+				<-release
+				atomic.AddInt64(&running, -1)
+				atomic.AddInt64(&done, 1)
+			})
+		}()
+	}
+
+	wg.Wait() // 10 个任务均已入队（容量 20）
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	pool.Stop()
+
+	if maxRunning > 2 {
+		t.Errorf("同时执行的任务数不应超过2，实际 %d", maxRunning)
+	}
+	if done != 10 {
+		t.Errorf("Stop 应等待全部任务执行完毕，实际完成 %d", done)
+	}
+}
+
+func TestWorkerPool_BackpressureDelaysInsteadOfDropping(t *testing.T) {
+	pool := newWorkerPool(1)
+	pool.Start()
+
+	release := make(chan struct{})
+	var done int64
+	total := 1 + 1*workerQueueFactor + 5 // 占满工作线程和队列后再多提交5个
+
+	submitted := make(chan struct{})
+	go func() {
+		for i := 0; i < total; i++ {
+			pool.Submit("测试任务", func() {
+				<-release
+				atomic.AddInt64(&done, 1)
+			})
+		}
+		close(submitted)
+	}()
+
+	select {
+	case <-submitted:
+		t.Fatal("队列已满时 Submit 应阻塞")
+	case <-time.After(100 * time.Millisecond):
+	}
+	if depth := pool.QueueDepth(); depth != workerQueueFactor {
+		t.Errorf("队列深度应为 %d，实际 %d", workerQueueFactor, depth)
+	}
+
+	close(release)
+	<-submitted
+	pool.Stop()
+
+	if done != int64(total) {
+		t.Errorf("所有任务都应执行，期望 %d 实际 %d", total, done)
+	}
+}
+
+func TestWorkerPool_SubmitWhenStopped(t *testing.T) {
+	pool := newWorkerPool(1)
+
+	executed := false
+	pool.Submit("测试任务", func() { executed = true })
+	if !executed {
+		t.Error("任务池未运行时应直接执行任务")
+	}
+
+	// 停止后可重新启动
+	pool.Start()
+	pool.Stop()
+	pool.Start()
+	ran := make(chan struct{})
+	pool.Submit("测试任务", func() { close(ran) })
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Error("重新启动后任务应被执行")
+	}
+	pool.Stop()
+}
+
+func TestScheduler_SetMaxWorkers(t *testing.T) {
+	scheduler := NewSchedulerService(newMockReminderRepository(), newMockReminderLogRepository(), newMockNotificationService()).(*schedulerService)
+	defer scheduler.Stop()
+
+	if err := scheduler.SetMaxWorkers(0); err == nil {
+		t.Error("工作线程数为0应返回错误")
+	}
+	if err := scheduler.SetMaxWorkers(3); err != nil {
+		t.Fatalf("SetMaxWorkers() 失败: %v", err)
+	}
+	if scheduler.pool.workers != 3 {
+		t.Errorf("工作线程数应为3，实际 %d", scheduler.pool.workers)
+	}
+
+	done := make(chan struct{})
+	scheduler.dispatch("测试任务", func() { close(done) })
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("任务应由新的工作线程池执行")
+	}
+}
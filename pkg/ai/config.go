@@ -95,7 +95,17 @@ func getDefaultReminderPrompt() string {
 时间格式说明:
 - 支持绝对时间: "明天8点", "下周一9点"
 - 支持相对时间: "1小时后", "明天"
-- 支持重复模式: "每天", "每周一三五", "工作日"
+- 支持重复模式: "每天", "每周一三五", "工作日", "每月15号", "每月最后一天", "每月第一个周一", "每年3月8日", "每2小时", "每3天", "隔周"
+- schedule_pattern 取值说明:
+  - 工作日: "workday"（按法定节假日跳过放假日、包含调休上班日）；只有明确说"周一到周五"时才用 "weekly:1,2,3,4,5"
+  - 每月指定日期: "monthly:1,15"；每月最后一天: "monthly:L"
+  - 每月第N个星期几: "monthly:星期#N"，星期 0-6（0为周日），N 为 1-5 或 L（最后一个），如每月第一个周一 "monthly:1#1"
+  - 每年指定日期: "yearly:MM-DD"，如 "yearly:03-08"
+  - 间隔重复: "every:Nh"（每N小时）、"every:Nh@HH:MM-HH:MM"（时间段内每N小时）、"every:Nd"（每N天）、"every:Nw"（每N周，隔周为 "every:2w"）
+    如"9点到18点每2小时提醒我喝水"为 "every:2h@09:00-18:00"，time 填写时间段开始时间
+- 结束条件: "持续21天"、"直到2026年12月31日" 填写 reminder.until_date（YYYY-MM-DD，含当天）；"共10次" 填写 reminder.max_occurrences；没有则省略
+- 提前通知: "提前半小时提醒我"、"提前1天和1小时通知我" 填写 reminder.lead_times（逗号分隔，单位 m/h/d，如 "30m"、"1d,1h"）；没有则省略
+- 同一提醒有多个时间点（如"每天8点、13点和20点吃药"）时创建一条提醒：hour/minute 填最早的时间，time.times 列出全部时间 ["08:00","13:00","20:00"]
 
 请返回以下JSON格式(不要包含markdown代码块标记):
 {
@@ -109,10 +119,14 @@ func getDefaultReminderPrompt() string {
       "minute": 0,
       "timezone": "Asia/Shanghai",
       "is_relative_time": false,
-      "relative_desc": ""
+      "relative_desc": "",
+      "times": ["08:00", "20:00"]
     },
-    "schedule_pattern": "daily|weekly:1,3,5|monthly:1,15|once",
-    "description": "详细描述"
+    "schedule_pattern": "daily|weekly:1,3,5|workday|monthly:1,15|monthly:L|monthly:1#1|yearly:03-08|every:2h@09:00-18:00|every:3d|once",
+    "description": "详细描述",
+    "until_date": "2026-12-31",
+    "max_occurrences": 21,
+    "lead_times": "1d,1h,10m"
   },
   "delete": {
     "keywords": ["健身", "晚上"],
@@ -146,6 +160,18 @@ func getDefaultReminderPrompt() string {
 用户: "每天早上8点提醒我喝水"
 返回: {"intent":"reminder","confidence":0.95,"reminder":{"title":"喝水","type":"habit","time":{"hour":8,"minute":0,"timezone":"Asia/Shanghai"},"schedule_pattern":"daily"}}
 
+用户: "每月最后一个周五下午5点提醒我写月报"
+返回: {"intent":"reminder","confidence":0.93,"reminder":{"title":"写月报","type":"habit","time":{"hour":17,"minute":0,"timezone":"Asia/Shanghai"},"schedule_pattern":"monthly:5#L"}}
+
+用户: "每天8点、13点和20点提醒我吃药"
+返回: {"intent":"reminder","confidence":0.94,"reminder":{"title":"吃药","type":"habit","time":{"hour":8,"minute":0,"timezone":"Asia/Shanghai","times":["08:00","13:00","20:00"]},"schedule_pattern":"daily"}}
+
+用户: "每天早上7点提醒我冥想，坚持21天"（当前日期 2026-03-01）
+返回: {"intent":"reminder","confidence":0.93,"reminder":{"title":"冥想","type":"habit","time":{"hour":7,"minute":0,"timezone":"Asia/Shanghai"},"schedule_pattern":"daily","until_date":"2026-03-21"}}
+
+用户: "明天下午3点看牙医，提前1天和半小时提醒我"（当前日期 2026-03-01）
+返回: {"intent":"reminder","confidence":0.92,"reminder":{"title":"看牙医","type":"task","time":{"hour":15,"minute":0,"timezone":"Asia/Shanghai"},"schedule_pattern":"once:2026-03-02","lead_times":"1d,30m"}}
+
 用户: "撤销今晚的健身提醒"
 返回: {"intent":"delete","confidence":0.92,"delete":{"keywords":["健身","今晚"],"criteria":"删除今晚的健身提醒"}}
 
@@ -1,12 +1,16 @@
 package ai
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
 	"mmemory/internal/models"
 )
 
+// DefaultTimezone 解析器在用户未指明时区时使用的默认时区
+const DefaultTimezone = "Asia/Shanghai"
+
 // ParseIntent 解析意图类型
 type ParseIntent string
 
@@ -82,6 +86,9 @@ type ReminderInfo struct {
 	Time            TimeInfo               `json:"time"`
 	SchedulePattern models.SchedulePattern `json:"schedule_pattern"`
 	Description     string                 `json:"description,omitempty"`
+	UntilDate       string                 `json:"until_date,omitempty"`      // 截止日期 YYYY-MM-DD
+	MaxOccurrences  int                    `json:"max_occurrences,omitempty"` // 最多提醒次数
+	LeadTimes       string                 `json:"lead_times,omitempty"`      // 提前通知，如 1d,1h,10m
 }
 
 // TimeInfo 时间信息结构
@@ -92,6 +99,9 @@ type TimeInfo struct {
 	ScheduleDetails string `json:"schedule_details,omitempty"` // "weekly:1,3,5"
 	IsRelativeTime  bool   `json:"is_relative_time"`           // 是否为相对时间
 	RelativeDesc    string `json:"relative_desc,omitempty"`    // "明天", "下周一"
+
+	// Times 多个触发时间（HH:MM），如 ["08:00","13:00","20:00"]，与 Hour/Minute 合并去重
+	Times []string `json:"times,omitempty"`
 }
 
 // ChatInfo 对话信息结构
@@ -291,6 +301,26 @@ func (pr *ParseResult) validateReminderInfo() []string {
 		errors = append(errors, "timezone is required")
 	}
 
+	if r.UntilDate != "" {
+		if _, err := time.Parse("2006-01-02", r.UntilDate); err != nil {
+			errors = append(errors, "until_date must be YYYY-MM-DD")
+		}
+	}
+
+	if r.MaxOccurrences < 0 {
+		errors = append(errors, "max_occurrences cannot be negative")
+	}
+
+	if _, err := models.ParseLeadTimes(r.LeadTimes); err != nil {
+		errors = append(errors, fmt.Sprintf("invalid lead_times %q, expected e.g. 1d,1h,10m", r.LeadTimes))
+	}
+
+	for _, t := range r.Time.Times {
+		if _, err := time.Parse("15:04", strings.TrimSpace(t)); err != nil {
+			errors = append(errors, fmt.Sprintf("invalid time %q, expected HH:MM", t))
+		}
+	}
+
 	return errors
 }
 
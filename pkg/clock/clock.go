@@ -0,0 +1,176 @@
+// Package clock 提供可替换的时钟，便于在测试和模拟中控制时间
+package clock
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// Clock 时钟接口
+type Clock interface {
+	Now() time.Time
+	// AfterFunc 在 d 之后调用 f，语义同 time.AfterFunc
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer 由 AfterFunc 返回的定时器
+type Timer interface {
+	// Stop 取消尚未触发的定时器，已触发或已取消时返回 false
+	Stop() bool
+}
+
+// Real 使用系统时间的时钟
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// IsReal 判断是否为系统时钟（nil 视为系统时钟）
+func IsReal(c Clock) bool {
+	if c == nil {
+		return true
+	}
+	_, ok := c.(realClock)
+	return ok
+}
+
+// Simulated 模拟时钟，时间只在调用 Advance/AdvanceTo 时前进
+// 到期的定时器按触发时间先后在调用方 goroutine 中依次执行，结果确定可复现
+type Simulated struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers timerQueue
+	seq    uint64
+}
+
+// NewSimulated 创建从 start 开始的模拟时钟
+func NewSimulated(start time.Time) *Simulated {
+	return &Simulated{now: start}
+}
+
+// Now 返回当前模拟时间
+func (s *Simulated) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+// AfterFunc 注册定时器，d <= 0 时在下一次 Advance 时立即触发
+func (s *Simulated) AfterFunc(d time.Duration, f func()) Timer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if d < 0 {
+		d = 0
+	}
+	s.seq++
+	t := &simulatedTimer{clock: s, when: s.now.Add(d), seq: s.seq, f: f, index: -1}
+	heap.Push(&s.timers, t)
+	return t
+}
+
+// Advance 将时间推进 d，期间到期的定时器（包括回调中新注册的）依次触发
+func (s *Simulated) Advance(d time.Duration) {
+	s.AdvanceTo(s.Now().Add(d))
+}
+
+// AdvanceTo 将时间推进到 target，target 早于当前时间时只触发已到期的定时器
+func (s *Simulated) AdvanceTo(target time.Time) {
+	for {
+		s.mu.Lock()
+		if len(s.timers) == 0 || s.timers[0].when.After(target) {
+			if target.After(s.now) {
+				s.now = target
+			}
+			s.mu.Unlock()
+			return
+		}
+
+		t := heap.Pop(&s.timers).(*simulatedTimer)
+		if t.when.After(s.now) {
+			s.now = t.when
+		}
+		s.mu.Unlock()
+
+		// 回调可能再次注册定时器，必须在释放锁后执行
+		t.f()
+	}
+}
+
+// Pending 返回尚未触发的定时器数量
+func (s *Simulated) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.timers)
+}
+
+// NextFire 返回最早的定时器触发时间，没有定时器时返回 false
+func (s *Simulated) NextFire() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.timers) == 0 {
+		return time.Time{}, false
+	}
+	return s.timers[0].when, true
+}
+
+type simulatedTimer struct {
+	clock *Simulated
+	when  time.Time
+	seq   uint64 // 同一时刻按注册顺序触发
+	f     func()
+	index int // 在队列中的位置，-1 表示已触发或已取消
+}
+
+func (t *simulatedTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	if t.index < 0 {
+		return false
+	}
+	heap.Remove(&t.clock.timers, t.index)
+	return true
+}
+
+// timerQueue 按触发时间排序的最小堆
+type timerQueue []*simulatedTimer
+
+func (q timerQueue) Len() int { return len(q) }
+
+func (q timerQueue) Less(i, j int) bool {
+	if q[i].when.Equal(q[j].when) {
+		return q[i].seq < q[j].seq
+	}
+	return q[i].when.Before(q[j].when)
+}
+
+func (q timerQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *timerQueue) Push(x interface{}) {
+	t := x.(*simulatedTimer)
+	t.index = len(*q)
+	*q = append(*q, t)
+}
+
+func (q *timerQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	t := old[n-1]
+	old[n-1] = nil
+	t.index = -1
+	*q = old[:n-1]
+	return t
+}
@@ -0,0 +1,78 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSimulated_FiresTimersInOrder(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewSimulated(start)
+
+	var fired []string
+	record := func(name string) func() {
+		return func() { fired = append(fired, name+"@"+c.Now().Format("15:04")) }
+	}
+
+	c.AfterFunc(2*time.Hour, record("b"))
+	c.AfterFunc(time.Hour, record("a"))
+	c.AfterFunc(2*time.Hour, record("c")) // 同一时刻按注册顺序
+	stopped := c.AfterFunc(90*time.Minute, record("stopped"))
+	c.AfterFunc(5*time.Hour, record("later"))
+
+	if !stopped.Stop() {
+		t.Error("未触发的定时器应能取消")
+	}
+	if stopped.Stop() {
+		t.Error("重复取消应返回 false")
+	}
+
+	c.Advance(3 * time.Hour)
+
+	want := []string{"a@01:00", "b@02:00", "c@02:00"}
+	if len(fired) != len(want) {
+		t.Fatalf("触发 %v，期望 %v", fired, want)
+	}
+	for i := range want {
+		if fired[i] != want[i] {
+			t.Errorf("第 %d 个触发 %s，期望 %s", i+1, fired[i], want[i])
+		}
+	}
+	if got := c.Now(); !got.Equal(start.Add(3 * time.Hour)) {
+		t.Errorf("Now() = %s", got)
+	}
+	if c.Pending() != 1 {
+		t.Errorf("Pending() = %d，期望 1", c.Pending())
+	}
+}
+
+func TestSimulated_TimerRegisteredInCallback(t *testing.T) {
+	c := NewSimulated(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	count := 0
+	var tick func()
+	tick = func() {
+		count++
+		c.AfterFunc(time.Hour, tick)
+	}
+	c.AfterFunc(time.Hour, tick)
+
+	c.Advance(24 * time.Hour)
+	if count != 24 {
+		t.Errorf("每小时触发一次，24小时内应触发24次，实际 %d", count)
+	}
+
+	next, ok := c.NextFire()
+	if !ok || !next.Equal(time.Date(2026, 1, 2, 1, 0, 0, 0, time.UTC)) {
+		t.Errorf("NextFire() = %s, %v", next, ok)
+	}
+}
+
+func TestIsReal(t *testing.T) {
+	if !IsReal(Real) || !IsReal(nil) {
+		t.Error("Real 和 nil 应视为系统时钟")
+	}
+	if IsReal(NewSimulated(time.Now())) {
+		t.Error("模拟时钟不应视为系统时钟")
+	}
+}
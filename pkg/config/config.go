@@ -22,12 +22,22 @@ type Config struct {
 	App       AppConfig       `mapstructure:"app"`
 	Monitoring MonitoringConfig `mapstructure:"monitoring"`
 	AI        AIConfig        `mapstructure:"ai"`
+	Notification NotificationConfig `mapstructure:"notification"`
 }
 
 type BotConfig struct {
-	Token   string        `mapstructure:"token"`
-	Debug   bool          `mapstructure:"debug"`
-	Webhook WebhookConfig `mapstructure:"webhook"`
+	Token     string          `mapstructure:"token"`
+	Debug     bool            `mapstructure:"debug"`
+	Webhook   WebhookConfig   `mapstructure:"webhook"`
+	SendQueue SendQueueConfig `mapstructure:"send_queue"`
+}
+
+// SendQueueConfig Telegram 发送队列的限速与重试
+type SendQueueConfig struct {
+	GlobalRate   int           `mapstructure:"global_rate"`   // 每秒最多发送的消息数
+	ChatInterval time.Duration `mapstructure:"chat_interval"` // 同一会话两条消息的最小间隔
+	MaxRetries   int           `mapstructure:"max_retries"`   // 429 和网络错误的最多重试次数
+	Workers      int           `mapstructure:"workers"`       // 同时进行的请求数
 }
 
 type WebhookConfig struct {
@@ -49,8 +59,45 @@ type ServerConfig struct {
 }
 
 type SchedulerConfig struct {
-	Timezone   string `mapstructure:"timezone"`
-	MaxWorkers int    `mapstructure:"max_workers"`
+	Timezone          string        `mapstructure:"timezone"`
+	MaxWorkers        int           `mapstructure:"max_workers"`
+	MissedPolicy      string        `mapstructure:"missed_policy"`      // 停机期间错过提醒的补偿策略: late, digest, mark
+	MissedLookback    time.Duration `mapstructure:"missed_lookback"`    // 启动时向前追溯错过提醒的最长时间
+	HolidayDir        string        `mapstructure:"holiday_dir"`        // 节假日日历目录，每个地区一个 YAML/JSON 文件
+	HolidayRegion     string        `mapstructure:"holiday_region"`     // 工作日提醒默认使用的节假日地区，如 CN
+	LeaderElection    bool          `mapstructure:"leader_election"`    // 多实例部署时通过数据库租约选举主实例，只有主实例触发提醒
+	LeaseTTL          time.Duration `mapstructure:"lease_ttl"`          // 调度租约有效期，主实例宕机后备用实例最迟约 4/3 倍该时间接管
+	InstanceID        string        `mapstructure:"instance_id"`        // 实例标识，为空时使用 主机名-进程号
+	ReconcileInterval time.Duration `mapstructure:"reconcile_interval"` // 与数据库增量同步调度的间隔，0 表示只在启动和热更新时同步
+	FollowUpPolicy    string        `mapstructure:"follow_up_policy"`   // 默认关怀策略，如 "1h;max=3"，"off" 表示不发送关怀
+	ResumeNotice      bool          `mapstructure:"resume_notice"`      // 暂停到期自动恢复时通知用户
+}
+
+// NotificationConfig 通知渠道配置，Telegram 渠道始终可用
+type NotificationConfig struct {
+	DefaultChannels string              `mapstructure:"default_channels"` // 用户和提醒都未设置时依次尝试的渠道，如 "telegram,email"
+	TemplateDir     string              `mapstructure:"template_dir"`     // 消息模板目录，每个语言一个 YAML/JSON 文件
+	Email           EmailConfig         `mapstructure:"email"`
+	Webhook         NotifyWebhookConfig `mapstructure:"webhook"`
+}
+
+// EmailConfig SMTP 邮件渠道
+type EmailConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
+}
+
+// NotifyWebhookConfig 用户自定义 HTTP 回调渠道
+type NotifyWebhookConfig struct {
+	Enabled bool          `mapstructure:"enabled"`
+	Secret  string        `mapstructure:"secret"`  // HMAC-SHA256 签名密钥
+	Timeout time.Duration `mapstructure:"timeout"` // 单次请求超时
+	// AllowPrivate 允许回调到本机、内网等地址，默认关闭，防止用户借回调访问内部服务
+	AllowPrivate bool `mapstructure:"allow_private"`
 }
 
 type LoggingConfig struct {
@@ -209,6 +256,10 @@ func (cm *ConfigManager) setDefaults() {
 	cm.viper.SetDefault("bot.debug", false)
 	cm.viper.SetDefault("bot.webhook.enabled", false)
 	cm.viper.SetDefault("bot.webhook.port", 8443)
+	cm.viper.SetDefault("bot.send_queue.global_rate", 30)
+	cm.viper.SetDefault("bot.send_queue.chat_interval", "1s")
+	cm.viper.SetDefault("bot.send_queue.max_retries", 5)
+	cm.viper.SetDefault("bot.send_queue.workers", 8)
 	
 	cm.viper.SetDefault("database.driver", "sqlite3")
 	cm.viper.SetDefault("database.dsn", "./data/mmemory.db")
@@ -220,6 +271,23 @@ func (cm *ConfigManager) setDefaults() {
 	
 	cm.viper.SetDefault("scheduler.timezone", "Asia/Shanghai")
 	cm.viper.SetDefault("scheduler.max_workers", 10)
+	cm.viper.SetDefault("scheduler.missed_policy", "late")
+	cm.viper.SetDefault("scheduler.missed_lookback", "24h")
+	cm.viper.SetDefault("scheduler.holiday_dir", "./configs/holidays")
+	cm.viper.SetDefault("scheduler.holiday_region", "CN")
+	cm.viper.SetDefault("scheduler.leader_election", false)
+	cm.viper.SetDefault("scheduler.lease_ttl", "30s")
+	cm.viper.SetDefault("scheduler.reconcile_interval", "5m")
+	cm.viper.SetDefault("scheduler.follow_up_policy", "1h;max=3")
+	cm.viper.SetDefault("scheduler.resume_notice", true)
+
+	cm.viper.SetDefault("notification.default_channels", "telegram")
+	cm.viper.SetDefault("notification.template_dir", "./configs/templates")
+	cm.viper.SetDefault("notification.email.enabled", false)
+	cm.viper.SetDefault("notification.email.port", 587)
+	cm.viper.SetDefault("notification.webhook.enabled", false)
+	cm.viper.SetDefault("notification.webhook.timeout", "10s")
+	cm.viper.SetDefault("notification.webhook.allow_private", false)
 	
 	cm.viper.SetDefault("logging.level", "info")
 	cm.viper.SetDefault("logging.format", "json")
@@ -381,6 +449,23 @@ func (cm *ConfigManager) validate(config *Config) error {
 		errors = append(errors, "调度器最大工作线程数必须大于0")
 	}
 
+	validMissedPolicies := map[string]bool{"": true, "late": true, "digest": true, "mark": true}
+	if !validMissedPolicies[config.Scheduler.MissedPolicy] {
+		errors = append(errors, "错过提醒补偿策略必须是 late、digest 或 mark")
+	}
+
+	if config.Scheduler.MissedLookback < 0 {
+		errors = append(errors, "错过提醒追溯时间不能为负数")
+	}
+
+	if config.Scheduler.LeaderElection && config.Scheduler.LeaseTTL < 3*time.Second {
+		errors = append(errors, "调度租约有效期不能小于3秒")
+	}
+
+	if config.Scheduler.ReconcileInterval < 0 {
+		errors = append(errors, "调度同步间隔不能为负数")
+	}
+
 	// 验证日志配置
 	validLogLevels := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
 	if !validLogLevels[config.Logging.Level] {
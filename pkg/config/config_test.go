@@ -22,12 +22,39 @@ func TestConfigManager_Load(t *testing.T) {
 			},
 			wantErr: false,
 			check: func(cfg *Config) {
+				if q := cfg.Bot.SendQueue; q.GlobalRate != 30 || q.ChatInterval != time.Second || q.MaxRetries != 5 || q.Workers != 8 {
+					t.Errorf("期望发送队列默认为 30/s、每会话1s、重试5次、8个并发，实际为 %+v", q)
+				}
 				if cfg.Database.Driver != "sqlite3" {
 					t.Errorf("期望数据库驱动默认为 sqlite3，实际为 %s", cfg.Database.Driver)
 				}
 				if cfg.Scheduler.Timezone != "Asia/Shanghai" {
 					t.Errorf("期望时区默认为 Asia/Shanghai，实际为 %s", cfg.Scheduler.Timezone)
 				}
+				if cfg.Scheduler.MissedPolicy != "late" || cfg.Scheduler.MissedLookback != 24*time.Hour {
+					t.Errorf("期望错过提醒策略默认为 late/24h，实际为 %s/%s", cfg.Scheduler.MissedPolicy, cfg.Scheduler.MissedLookback)
+				}
+				if cfg.Scheduler.HolidayDir != "./configs/holidays" || cfg.Scheduler.HolidayRegion != "CN" {
+					t.Errorf("期望节假日日历默认为 ./configs/holidays/CN，实际为 %s/%s", cfg.Scheduler.HolidayDir, cfg.Scheduler.HolidayRegion)
+				}
+				if cfg.Scheduler.ReconcileInterval != 5*time.Minute {
+					t.Errorf("期望调度同步间隔默认为 5m，实际为 %s", cfg.Scheduler.ReconcileInterval)
+				}
+				if cfg.Scheduler.FollowUpPolicy != "1h;max=3" {
+					t.Errorf("期望关怀策略默认为 1h;max=3，实际为 %s", cfg.Scheduler.FollowUpPolicy)
+				}
+				if !cfg.Scheduler.ResumeNotice {
+					t.Error("期望暂停到期恢复通知默认开启")
+				}
+				if cfg.Notification.DefaultChannels != "telegram" || cfg.Notification.Email.Enabled || cfg.Notification.Webhook.Enabled {
+					t.Errorf("期望默认只启用 Telegram 渠道，实际为 %+v", cfg.Notification)
+				}
+				if cfg.Notification.Webhook.Timeout != 10*time.Second {
+					t.Errorf("期望 Webhook 超时默认为 10s，实际为 %s", cfg.Notification.Webhook.Timeout)
+				}
+				if cfg.Notification.TemplateDir != "./configs/templates" {
+					t.Errorf("期望消息模板目录默认为 ./configs/templates，实际为 %s", cfg.Notification.TemplateDir)
+				}
 				if cfg.Logging.Level != "info" {
 					t.Errorf("期望日志级别默认为 info，实际为 %s", cfg.Logging.Level)
 				}
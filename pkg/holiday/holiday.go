@@ -0,0 +1,286 @@
+// Package holiday 提供节假日与调休日历，用于判断某天是否为法定工作日
+package holiday
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const dateLayout = "2006-01-02"
+
+// Calendar 节假日日历
+type Calendar interface {
+	// IsWorkday 判断 date 所在日期在指定地区是否为工作日（含调休上班日）
+	IsWorkday(region string, date time.Time) bool
+}
+
+// Weekdays 不含节假日信息的默认日历：周一到周五为工作日
+var Weekdays Calendar = weekdayCalendar{}
+
+type weekdayCalendar struct{}
+
+func (weekdayCalendar) IsWorkday(_ string, date time.Time) bool {
+	return isWeekday(date)
+}
+
+func isWeekday(date time.Time) bool {
+	weekday := date.Weekday()
+	return weekday != time.Saturday && weekday != time.Sunday
+}
+
+// Entry 一组节假日或调休日期
+type Entry struct {
+	Name  string   `json:"name" yaml:"name"`
+	Dates []string `json:"dates" yaml:"dates"` // 单个日期 "2026-01-01" 或区间 "2026-02-15~2026-02-23"
+}
+
+// File 单个地区的日历文件内容
+type File struct {
+	Region   string  `json:"region" yaml:"region"`
+	Holidays []Entry `json:"holidays" yaml:"holidays"` // 放假日期
+	Workdays []Entry `json:"workdays" yaml:"workdays"` // 调休上班日期（通常为周末）
+}
+
+// region 展开后的地区日历，key 为 YYYY-MM-DD
+type region struct {
+	holidays map[string]string
+	workdays map[string]string
+}
+
+// FileCalendar 从本地目录加载的节假日日历
+// 每个地区一个 YAML 或 JSON 文件，文件名即地区代码（如 CN.yaml），未配置的地区按周一到周五计算
+type FileCalendar struct {
+	mu      sync.RWMutex
+	dir     string
+	regions map[string]*region
+}
+
+// NewFileCalendar 创建节假日日历，需调用 Load 加载文件
+func NewFileCalendar(dir string) *FileCalendar {
+	return &FileCalendar{
+		dir:     dir,
+		regions: make(map[string]*region),
+	}
+}
+
+// Load 从目录加载全部日历文件，任一文件有误时保留原有数据
+func (c *FileCalendar) Load() error {
+	c.mu.RLock()
+	dir := c.dir
+	c.mu.RUnlock()
+
+	regions, err := loadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.regions = regions
+	c.mu.Unlock()
+	return nil
+}
+
+// Reload 切换到新目录并重新加载（用于配置热更新），dir 为空时沿用原目录
+func (c *FileCalendar) Reload(dir string) error {
+	if dir == "" {
+		c.mu.RLock()
+		dir = c.dir
+		c.mu.RUnlock()
+	}
+
+	regions, err := loadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.dir = dir
+	c.regions = regions
+	c.mu.Unlock()
+	return nil
+}
+
+// Regions 返回已加载的地区代码
+func (c *FileCalendar) Regions() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	names := make([]string, 0, len(c.regions))
+	for name := range c.regions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Holiday 返回指定日期的节假日名称，不是节假日时返回 false
+func (c *FileCalendar) Holiday(regionCode string, date time.Time) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	r, ok := c.regions[strings.ToUpper(regionCode)]
+	if !ok {
+		return "", false
+	}
+	name, ok := r.holidays[date.Format(dateLayout)]
+	return name, ok
+}
+
+// IsWorkday 实现 Calendar：调休上班日为工作日，节假日不是工作日，其余按周一到周五计算
+func (c *FileCalendar) IsWorkday(regionCode string, date time.Time) bool {
+	c.mu.RLock()
+	r, ok := c.regions[strings.ToUpper(regionCode)]
+	c.mu.RUnlock()
+
+	if ok {
+		key := date.Format(dateLayout)
+		if _, workday := r.workdays[key]; workday {
+			return true
+		}
+		if _, holiday := r.holidays[key]; holiday {
+			return false
+		}
+	}
+	return isWeekday(date)
+}
+
+func loadDir(dir string) (map[string]*region, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取节假日目录失败: %w", err)
+	}
+
+	regions := make(map[string]*region)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		file, err := loadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		code := file.Region
+		if code == "" {
+			code = strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		}
+		code = strings.ToUpper(code)
+
+		r, err := expand(file)
+		if err != nil {
+			return nil, fmt.Errorf("节假日文件 %s 有误: %w", path, err)
+		}
+		if existing, ok := regions[code]; ok {
+			merge(existing, r)
+		} else {
+			regions[code] = r
+		}
+	}
+
+	return regions, nil
+}
+
+func loadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取节假日文件失败: %w", err)
+	}
+
+	file := &File{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, file)
+	} else {
+		err = yaml.Unmarshal(data, file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析节假日文件 %s 失败: %w", path, err)
+	}
+	return file, nil
+}
+
+// expand 将日期和区间展开为逐日的集合
+func expand(file *File) (*region, error) {
+	r := &region{
+		holidays: make(map[string]string),
+		workdays: make(map[string]string),
+	}
+
+	for _, group := range []struct {
+		entries []Entry
+		target  map[string]string
+	}{
+		{file.Holidays, r.holidays},
+		{file.Workdays, r.workdays},
+	} {
+		for _, entry := range group.entries {
+			for _, spec := range entry.Dates {
+				days, err := expandDates(spec)
+				if err != nil {
+					return nil, err
+				}
+				for _, day := range days {
+					group.target[day] = entry.Name
+				}
+			}
+		}
+	}
+
+	for day := range r.workdays {
+		if _, ok := r.holidays[day]; ok {
+			return nil, fmt.Errorf("日期 %s 同时被设为节假日和调休上班日", day)
+		}
+	}
+	return r, nil
+}
+
+// maxRangeDays 单个日期区间的最大天数，防止写错年份导致展开过多
+const maxRangeDays = 60
+
+func expandDates(spec string) ([]string, error) {
+	spec = strings.TrimSpace(spec)
+	from, to := spec, spec
+	if idx := strings.Index(spec, "~"); idx >= 0 {
+		from, to = strings.TrimSpace(spec[:idx]), strings.TrimSpace(spec[idx+1:])
+	}
+
+	start, err := time.Parse(dateLayout, from)
+	if err != nil {
+		return nil, fmt.Errorf("无效的日期: %s", from)
+	}
+	end, err := time.Parse(dateLayout, to)
+	if err != nil {
+		return nil, fmt.Errorf("无效的日期: %s", to)
+	}
+	if end.Before(start) || end.Sub(start) > maxRangeDays*24*time.Hour {
+		return nil, fmt.Errorf("无效的日期区间: %s", spec)
+	}
+
+	var days []string
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		days = append(days, day.Format(dateLayout))
+	}
+	return days, nil
+}
+
+func merge(dst, src *region) {
+	for day, name := range src.holidays {
+		dst.holidays[day] = name
+	}
+	for day, name := range src.workdays {
+		dst.workdays[day] = name
+	}
+}
@@ -0,0 +1,104 @@
+package holiday
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatalf("写入测试文件失败: %v", err)
+	}
+}
+
+func date(s string) time.Time {
+	d, _ := time.Parse(dateLayout, s)
+	return d
+}
+
+func TestFileCalendar_IsWorkday(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "cn.yaml", `
+holidays:
+  - name: 春节
+    dates: [2026-02-15~2026-02-23]
+  - name: 元旦
+    dates: [2026-01-01]
+workdays:
+  - name: 春节调休
+    dates: [2026-02-14, 2026-02-28]
+`)
+	writeFile(t, dir, "HK.json", `{"region":"hk","holidays":[{"name":"圣诞节","dates":["2026-12-25"]}]}`)
+
+	calendar := NewFileCalendar(dir)
+	if err := calendar.Load(); err != nil {
+		t.Fatalf("Load() 失败: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		region string
+		date   string
+		want   bool
+	}{
+		{"普通工作日", "CN", "2026-02-10", true},
+		{"普通周末", "CN", "2026-02-08", false},
+		{"节假日区间内的周一", "CN", "2026-02-16", false},
+		{"调休上班的周六", "CN", "2026-02-14", true},
+		{"地区代码不区分大小写", "cn", "2026-01-01", false},
+		{"JSON文件", "HK", "2026-12-25", false},
+		{"未配置的地区按周一到周五", "US", "2026-01-01", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calendar.IsWorkday(tt.region, date(tt.date)); got != tt.want {
+				t.Errorf("IsWorkday(%s, %s) = %v, want %v", tt.region, tt.date, got, tt.want)
+			}
+		})
+	}
+
+	if name, ok := calendar.Holiday("CN", date("2026-02-20")); !ok || name != "春节" {
+		t.Errorf("Holiday() = %q, %v", name, ok)
+	}
+	if regions := calendar.Regions(); len(regions) != 2 || regions[0] != "CN" || regions[1] != "HK" {
+		t.Errorf("Regions() = %v", regions)
+	}
+}
+
+func TestFileCalendar_ReloadKeepsDataOnError(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "CN.yaml", "holidays:\n  - name: 元旦\n    dates: [2026-01-01]\n")
+
+	calendar := NewFileCalendar(dir)
+	if err := calendar.Load(); err != nil {
+		t.Fatalf("Load() 失败: %v", err)
+	}
+
+	writeFile(t, dir, "CN.yaml", "holidays:\n  - name: 元旦\n    dates: [2026-13-01]\n")
+	if err := calendar.Reload(""); err == nil {
+		t.Fatal("无效日期应返回错误")
+	}
+	if calendar.IsWorkday("CN", date("2026-01-01")) {
+		t.Error("加载失败时应保留原有日历")
+	}
+
+	writeFile(t, dir, "CN.yaml", "workdays:\n  - name: 调休\n    dates: [2026-01-03]\n")
+	if err := calendar.Reload(""); err != nil {
+		t.Fatalf("Reload() 失败: %v", err)
+	}
+	if !calendar.IsWorkday("CN", date("2026-01-01")) || !calendar.IsWorkday("CN", date("2026-01-03")) {
+		t.Error("重新加载后应使用新的日历")
+	}
+}
+
+func TestExpandDates_Errors(t *testing.T) {
+	for _, spec := range []string{"2026-02-30", "2026-03-01~2026-02-01", "2026-01-01~2027-01-01", "明天"} {
+		if _, err := expandDates(spec); err == nil {
+			t.Errorf("expandDates(%q) 期望返回错误", spec)
+		}
+	}
+}
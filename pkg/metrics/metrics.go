@@ -77,6 +77,35 @@ var (
 		[]string{"status"},
 	)
 
+	SchedulerQueueDepth = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "mmemory_scheduler_queue_depth",
+			Help: "Number of reminder executions waiting for a worker",
+		},
+	)
+
+	SchedulerBusyWorkers = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "mmemory_scheduler_busy_workers",
+			Help: "Number of workers currently executing reminders",
+		},
+	)
+
+	SchedulerLeader = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "mmemory_scheduler_leader",
+			Help: "Whether this instance holds the scheduler lease (1) or is a standby (0)",
+		},
+	)
+
+	SchedulerQueueWaitDuration = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "mmemory_scheduler_queue_wait_seconds",
+			Help:    "Time reminder executions spend waiting for a worker",
+			Buckets: []float64{0.001, 0.01, 0.1, 0.5, 1, 5, 15, 30, 60, 120},
+		},
+	)
+
 	// 数据库相关指标
 	DatabaseQueriesTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
@@ -113,6 +142,30 @@ var (
 		[]string{"type", "status"},
 	)
 
+	TelegramSendQueueDepth = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "mmemory_telegram_send_queue_depth",
+			Help: "Number of outbound Telegram messages waiting for the rate limiter",
+		},
+	)
+
+	TelegramSendDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "mmemory_telegram_send_duration_seconds",
+			Help:    "Time from queueing an outbound Telegram message until it is sent or given up, including retries",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 5, 15, 30, 60, 120},
+		},
+		[]string{"status"},
+	)
+
+	TelegramSendRetriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mmemory_telegram_send_retries_total",
+			Help: "Total number of outbound Telegram message retries",
+		},
+		[]string{"reason"},
+	)
+
 	// 错误相关指标
 	ErrorsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
@@ -186,6 +239,26 @@ func RecordSchedulerExecution(status string) {
 	SchedulerExecutionsTotal.WithLabelValues(status).Inc()
 }
 
+// SetSchedulerQueueDepth 设置等待执行的任务数量
+func SetSchedulerQueueDepth(depth float64) {
+	SchedulerQueueDepth.Set(depth)
+}
+
+// SetSchedulerBusyWorkers 设置正在执行任务的工作线程数量
+func SetSchedulerBusyWorkers(count float64) {
+	SchedulerBusyWorkers.Set(count)
+}
+
+// SetSchedulerLeader 设置当前实例是否为调度主实例
+func SetSchedulerLeader(leader float64) {
+	SchedulerLeader.Set(leader)
+}
+
+// RecordSchedulerQueueWait 记录任务从提交到开始执行的等待时间
+func RecordSchedulerQueueWait(duration float64) {
+	SchedulerQueueWaitDuration.Observe(duration)
+}
+
 // RecordDatabaseQuery 记录数据库查询
 func RecordDatabaseQuery(operation, status string) {
 	DatabaseQueriesTotal.WithLabelValues(operation, status).Inc()
@@ -206,6 +279,21 @@ func RecordNotificationSend(notificationType, status string, duration float64) {
 	NotificationSendDuration.WithLabelValues(notificationType, status).Observe(duration)
 }
 
+// SetTelegramSendQueueDepth 设置等待发送的 Telegram 消息数量
+func SetTelegramSendQueueDepth(depth float64) {
+	TelegramSendQueueDepth.Set(depth)
+}
+
+// RecordTelegramSend 记录 Telegram 消息从排队到发送完成（含重试）的耗时
+func RecordTelegramSend(status string, duration float64) {
+	TelegramSendDuration.WithLabelValues(status).Observe(duration)
+}
+
+// RecordTelegramSendRetry 记录 Telegram 消息重试，reason 为 rate_limited 或 network
+func RecordTelegramSendRetry(reason string) {
+	TelegramSendRetriesTotal.WithLabelValues(reason).Inc()
+}
+
 // RecordError 记录错误
 func RecordError(service, operation, errorType string) {
 	ErrorsTotal.WithLabelValues(service, operation, errorType).Inc()
@@ -0,0 +1,76 @@
+package msgtemplate
+
+// builtin 内置的中文模板，模板文件缺失或未定义某个模板时使用
+var builtin = map[string]string{
+	Reminder + ".habit": `⏰ <b>习惯提醒</b>
+
+📝 {{html .Title}}
+{{- if .Streak}}
+🔥 已连续完成 {{.Streak}} 次{{end}}
+
+已经到了约定的时间，完成了吗？`,
+
+	Reminder + ".task": `📋 <b>任务提醒</b>
+
+📝 {{html .Title}}
+{{- if .Description}}
+💬 {{html .Description}}{{end}}
+
+该处理这个任务了，准备好了吗？`,
+
+	Reminder: `🔔 <b>提醒</b>
+
+📝 {{html .Title}}
+
+时间到了，请查看！`,
+
+	FollowUp: `🤔 <b>还没完成吗？</b>
+
+📝 {{html .Title}}
+
+没关系，有什么困难吗？需要延期还是跳过？`,
+
+	FollowUpRepeat: `😊 <b>温馨提醒</b>
+
+📝 {{html .Title}}
+
+这个任务还在等着你呢，要不要处理一下？`,
+
+	FollowUpFinal: `💪 <b>最后提醒</b>
+
+📝 {{html .Title}}
+
+今天确实不方便的话，可以选择跳过哦～`,
+
+	LeadNotice: `🔔 <b>提前提醒</b>
+
+📝 {{html .Title}}
+⏰ {{.ScheduledTime.Format "01-02 15:04"}}（{{duration .Lead "天" "小时" "分钟"}}后）
+
+到点时还会正式提醒你`,
+
+	Resumed: `▶️ <b>你的提醒已恢复</b>
+
+📝 {{html .Title}}
+
+暂停已结束，将按原计划继续提醒`,
+
+	MissedDigest: `📭 <b>服务暂停期间错过的提醒</b>
+{{range .Missed}}
+• {{.ScheduledTime.Format "01-02 15:04"}}  {{html .Title}}{{end}}
+
+如有需要，记得补上哦～`,
+
+	Agenda: `☀️ <b>今日日程</b> {{.Date.Format "01-02"}}
+{{- if .Agenda}}
+{{range .Agenda}}
+• {{.ScheduledTime.Format "15:04"}}  {{html .Title}}{{end}}
+{{- else}}
+
+今天没有待提醒的事项{{end}}
+{{- if .Missed}}
+
+📭 <b>昨天未完成</b>
+{{range .Missed}}
+• {{.ScheduledTime.Format "15:04"}}  {{html .Title}}{{if .Skipped}}（已跳过）{{end}}{{end}}{{end}}`,
+}
@@ -0,0 +1,276 @@
+// Package msgtemplate 按语言加载通知消息模板，模板使用 text/template 语法
+package msgtemplate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLocale 找不到用户语言或模板时使用的语言
+const DefaultLocale = "zh-CN"
+
+// 模板名称，可追加提醒类型作为变体，如 reminder.habit，变体不存在时使用不带类型的模板
+const (
+	Reminder       = "reminder"         // 提醒
+	FollowUp       = "follow_up"        // 第一次关怀
+	FollowUpRepeat = "follow_up_repeat" // 之后的关怀
+	FollowUpFinal  = "follow_up_final"  // 最后一次关怀
+	LeadNotice     = "lead_notice"      // 提前通知
+	Resumed        = "resumed"          // 暂停到期恢复
+	MissedDigest   = "missed_digest"    // 错过提醒汇总
+	Agenda         = "agenda"           // 每日日程
+)
+
+// Data 模板可用的变量，时间已换算为提醒所在时区
+type Data struct {
+	Title           string
+	Description     string
+	Type            string        // habit 或 task
+	Streak          int           // 本次之前连续完成的次数
+	OccurrenceCount int           // 已触发次数
+	MaxOccurrences  int           // 最多触发次数，0 表示不限
+	ScheduledTime   time.Time     // 计划时间
+	FollowUpCount   int           // 已发送的关怀次数
+	Lead            time.Duration // 提前通知的提前量
+	Missed          []MissedItem  // 错过的提醒；每日日程中为前一天未完成的提醒
+	Date            time.Time     // 每日日程的日期
+	Agenda          []AgendaItem  // 每日日程中当天待提醒的事项
+}
+
+// MissedItem 错过提醒汇总中的一条
+type MissedItem struct {
+	Title         string
+	ScheduledTime time.Time
+	Skipped       bool // 用户选择了跳过
+}
+
+// AgendaItem 每日日程中的一次提醒
+type AgendaItem struct {
+	Title         string
+	ScheduledTime time.Time
+}
+
+// File 单个语言的模板文件内容
+type File struct {
+	Locale    string            `json:"locale" yaml:"locale"`
+	Templates map[string]string `json:"templates" yaml:"templates"`
+}
+
+// Set 从本地目录加载的模板集合
+// 每个语言一个 YAML 或 JSON 文件，文件名即语言代码（如 zh-CN.yaml、en.yaml）；
+// 文件中未定义的模板使用内置的中文模板
+type Set struct {
+	mu      sync.RWMutex
+	dir     string
+	locales map[string]*locale // key 为小写的语言代码
+}
+
+type locale struct {
+	code      string
+	templates map[string]*template.Template
+}
+
+// NewSet 创建模板集合，只包含内置模板，需调用 Load 加载目录中的文件
+func NewSet(dir string) *Set {
+	locales, err := build(nil)
+	if err != nil {
+		panic(fmt.Sprintf("内置消息模板有误: %v", err))
+	}
+	return &Set{dir: dir, locales: locales}
+}
+
+// Load 从目录加载全部模板文件，任一文件有误时保留原有模板
+func (s *Set) Load() error {
+	s.mu.RLock()
+	dir := s.dir
+	s.mu.RUnlock()
+	return s.Reload(dir)
+}
+
+// Reload 切换到新目录并重新加载（用于配置热更新），dir 为空时沿用原目录
+func (s *Set) Reload(dir string) error {
+	if dir == "" {
+		s.mu.RLock()
+		dir = s.dir
+		s.mu.RUnlock()
+	}
+
+	files, err := loadDir(dir)
+	if err != nil {
+		return err
+	}
+	locales, err := build(files)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.dir = dir
+	s.locales = locales
+	s.mu.Unlock()
+	return nil
+}
+
+// Locales 返回已加载的语言代码
+func (s *Set) Locales() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	codes := make([]string, 0, len(s.locales))
+	for _, l := range s.locales {
+		codes = append(codes, l.code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Render 渲染模板：依次尝试用户语言、同一语种（en-US → en）和默认语言，
+// 每个语言先找 name.variant 再找 name
+func (s *Set) Render(localeCode, name, variant string, data Data) (string, error) {
+	s.mu.RLock()
+	tmpl := s.lookup(localeCode, name, variant)
+	s.mu.RUnlock()
+
+	if tmpl == nil {
+		return "", fmt.Errorf("消息模板不存在: %s", name)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("渲染消息模板 %s 失败: %w", tmpl.Name(), err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func (s *Set) lookup(localeCode, name, variant string) *template.Template {
+	candidates := []string{strings.ToLower(localeCode)}
+	if base, _, ok := strings.Cut(candidates[0], "-"); ok {
+		candidates = append(candidates, base)
+	}
+	candidates = append(candidates, strings.ToLower(DefaultLocale))
+
+	for _, code := range candidates {
+		l, ok := s.locales[code]
+		if !ok {
+			continue
+		}
+		if variant != "" {
+			if tmpl, ok := l.templates[name+"."+variant]; ok {
+				return tmpl
+			}
+		}
+		if tmpl, ok := l.templates[name]; ok {
+			return tmpl
+		}
+	}
+	return nil
+}
+
+// funcs 模板中可用的函数，另有 text/template 内置的 html 等
+var funcs = template.FuncMap{
+	// duration 按给定的天、小时、分钟单位描述时长，如 {{duration .Lead "天" "小时" "分钟"}}
+	"duration": func(d time.Duration, day, hour, minute string) string {
+		var b strings.Builder
+		if days := int(d / (24 * time.Hour)); days > 0 {
+			fmt.Fprintf(&b, "%d%s", days, day)
+		}
+		if hours := int(d % (24 * time.Hour) / time.Hour); hours > 0 {
+			fmt.Fprintf(&b, "%d%s", hours, hour)
+		}
+		if minutes := int(d % time.Hour / time.Minute); minutes > 0 || b.Len() == 0 {
+			fmt.Fprintf(&b, "%d%s", minutes, minute)
+		}
+		return strings.TrimSpace(b.String())
+	},
+}
+
+// build 解析内置模板和文件中的模板，并用空数据试渲染以尽早发现写错的变量
+func build(files []*File) (map[string]*locale, error) {
+	locales := make(map[string]*locale)
+	add := func(code string, sources map[string]string) error {
+		key := strings.ToLower(code)
+		l, ok := locales[key]
+		if !ok {
+			l = &locale{code: code, templates: make(map[string]*template.Template)}
+			locales[key] = l
+		}
+		for name, source := range sources {
+			tmpl, err := template.New(code + "/" + name).Funcs(funcs).Parse(source)
+			if err != nil {
+				return fmt.Errorf("解析消息模板 %s/%s 失败: %w", code, name, err)
+			}
+			if err := tmpl.Execute(new(bytes.Buffer), Data{Missed: []MissedItem{{}}, Agenda: []AgendaItem{{}}}); err != nil {
+				return fmt.Errorf("消息模板 %s/%s 有误: %w", code, name, err)
+			}
+			l.templates[name] = tmpl
+		}
+		return nil
+	}
+
+	if err := add(DefaultLocale, builtin); err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if err := add(file.Locale, file.Templates); err != nil {
+			return nil, err
+		}
+	}
+	return locales, nil
+}
+
+func loadDir(dir string) ([]*File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取消息模板目录失败: %w", err)
+	}
+
+	var files []*File
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		file, err := loadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if file.Locale == "" {
+			file.Locale = strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func loadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取消息模板文件失败: %w", err)
+	}
+
+	file := &File{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, file)
+	} else {
+		err = yaml.Unmarshal(data, file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析消息模板文件 %s 失败: %w", path, err)
+	}
+	return file, nil
+}
@@ -0,0 +1,175 @@
+package msgtemplate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatalf("写入测试文件失败: %v", err)
+	}
+}
+
+func TestSet_Render(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "en.yaml", `
+templates:
+  reminder: "Reminder: {{.Title}}"
+  reminder.habit: "Habit: {{.Title}} ({{.Streak}})"
+`)
+	writeFile(t, dir, "ja.json", `{"locale":"ja","templates":{"reminder":"リマインダー: {{.Title}}"}}`)
+
+	set := NewSet(dir)
+	if err := set.Load(); err != nil {
+		t.Fatalf("Load() 失败: %v", err)
+	}
+
+	data := Data{Title: "喝水", Streak: 3}
+	tests := []struct {
+		name     string
+		locale   string
+		template string
+		variant  string
+		want     string
+	}{
+		{"按类型选择变体", "en", Reminder, "habit", "Habit: 喝水 (3)"},
+		{"变体不存在时使用通用模板", "en", Reminder, "task", "Reminder: 喝水"},
+		{"按语种回退", "en-US", Reminder, "", "Reminder: 喝水"},
+		{"语言代码不区分大小写", "EN", Reminder, "", "Reminder: 喝水"},
+		{"JSON文件", "ja", Reminder, "", "リマインダー: 喝水"},
+		{"文件中未定义的模板使用内置中文", "en", FollowUpFinal, "", "💪 <b>最后提醒</b>"},
+		{"未知语言使用默认语言", "ru", Reminder, "habit", "⏰ <b>习惯提醒</b>"},
+		{"空语言使用默认语言", "", Reminder, "task", "📋 <b>任务提醒</b>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := set.Render(tt.locale, tt.template, tt.variant, data)
+			if err != nil {
+				t.Fatalf("Render() 失败: %v", err)
+			}
+			if !strings.HasPrefix(got, tt.want) {
+				t.Errorf("Render() = %q, want prefix %q", got, tt.want)
+			}
+		})
+	}
+
+	if locales := set.Locales(); strings.Join(locales, ",") != "en,ja,zh-CN" {
+		t.Errorf("Locales() = %v", locales)
+	}
+}
+
+func TestSet_Builtin(t *testing.T) {
+	set := NewSet("")
+	scheduled := time.Date(2026, 3, 2, 8, 30, 0, 0, time.UTC)
+
+	got, err := set.Render(DefaultLocale, Reminder, "habit", Data{Title: "<读书>", Streak: 5})
+	if err != nil {
+		t.Fatalf("Render() 失败: %v", err)
+	}
+	if !strings.Contains(got, "&lt;读书&gt;") || !strings.Contains(got, "已连续完成 5 次") {
+		t.Errorf("习惯提醒应转义标题并显示连续次数: %q", got)
+	}
+
+	got, _ = set.Render(DefaultLocale, Reminder, "habit", Data{Title: "读书"})
+	if strings.Contains(got, "连续") {
+		t.Errorf("没有连续记录时不应显示连续次数: %q", got)
+	}
+
+	got, _ = set.Render(DefaultLocale, LeadNotice, "", Data{Title: "开会", ScheduledTime: scheduled, Lead: 26*time.Hour + 30*time.Minute})
+	if !strings.Contains(got, "03-02 08:30（1天2小时30分钟后）") {
+		t.Errorf("提前通知时间不正确: %q", got)
+	}
+
+	got, _ = set.Render(DefaultLocale, MissedDigest, "", Data{Missed: []MissedItem{
+		{Title: "喝水", ScheduledTime: scheduled},
+		{Title: "读书", ScheduledTime: scheduled.Add(time.Hour)},
+	}})
+	if !strings.Contains(got, "• 03-02 08:30  喝水\n• 03-02 09:30  读书") {
+		t.Errorf("错过提醒汇总不正确: %q", got)
+	}
+}
+
+func TestSet_ReloadKeepsTemplatesOnError(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "en.yaml", `templates: {reminder: "v1 {{.Title}}"}`)
+
+	set := NewSet(dir)
+	if err := set.Load(); err != nil {
+		t.Fatalf("Load() 失败: %v", err)
+	}
+
+	render := func() string {
+		got, err := set.Render("en", Reminder, "", Data{Title: "x"})
+		if err != nil {
+			t.Fatalf("Render() 失败: %v", err)
+		}
+		return got
+	}
+
+	writeFile(t, dir, "en.yaml", `templates: {reminder: "v2 {{.Title}}"}`)
+	if err := set.Reload(""); err != nil {
+		t.Fatalf("Reload() 失败: %v", err)
+	}
+	if got := render(); got != "v2 x" {
+		t.Errorf("重新加载后 = %q, want %q", got, "v2 x")
+	}
+
+	for name, content := range map[string]string{
+		"语法错误":   `templates: {reminder: "{{.Title"}`,
+		"未知变量":   `templates: {reminder: "{{.Unknown}}"}`,
+		"YAML错误": "templates: [",
+	} {
+		writeFile(t, dir, "en.yaml", content)
+		if err := set.Reload(""); err == nil {
+			t.Errorf("%s: Reload() 应返回错误", name)
+		}
+		if got := render(); got != "v2 x" {
+			t.Errorf("%s: 加载失败后应保留原模板，实际为 %q", name, got)
+		}
+	}
+
+	if err := set.Reload(filepath.Join(dir, "missing")); err == nil {
+		t.Error("目录不存在时 Reload() 应返回错误")
+	}
+}
+
+func TestSet_ShippedTemplates(t *testing.T) {
+	set := NewSet(filepath.Join("..", "..", "configs", "templates"))
+	if err := set.Load(); err != nil {
+		t.Fatalf("加载 configs/templates 失败: %v", err)
+	}
+
+	data := Data{Title: "喝水", Streak: 2, ScheduledTime: time.Date(2026, 3, 2, 8, 30, 0, 0, time.UTC), Lead: 90 * time.Minute}
+	for _, locale := range []string{"zh-CN", "en"} {
+		for _, name := range []string{Reminder, FollowUp, FollowUpRepeat, FollowUpFinal, LeadNotice, Resumed} {
+			got, err := set.Render(locale, name, "habit", data)
+			if err != nil || !strings.Contains(got, "喝水") {
+				t.Errorf("%s/%s = %q, %v", locale, name, got, err)
+			}
+		}
+	}
+
+	got, _ := set.Render("en-GB", LeadNotice, "", data)
+	if !strings.Contains(got, "(in 1h 30min)") {
+		t.Errorf("英文提前通知 = %q", got)
+	}
+
+	data.Date = data.ScheduledTime
+	data.Agenda = []AgendaItem{{Title: "喝水", ScheduledTime: data.ScheduledTime}}
+	data.Missed = []MissedItem{{Title: "读书", ScheduledTime: data.ScheduledTime, Skipped: true}}
+	for locale, want := range map[string]string{
+		"zh-CN": "• 08:30  喝水\n\n📭 <b>昨天未完成</b>\n\n• 08:30  读书（已跳过）",
+		"en":    "• 08:30  喝水\n\n📭 <b>Unfinished yesterday</b>\n\n• 08:30  读书 (skipped)",
+	} {
+		got, err := set.Render(locale, Agenda, "", data)
+		if err != nil || !strings.Contains(got, want) {
+			t.Errorf("%s 每日日程 = %q, %v", locale, got, err)
+		}
+	}
+}
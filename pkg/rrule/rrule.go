@@ -0,0 +1,605 @@
+// Package rrule 实现 RFC 5545 RRULE 的常用子集，用于高级用户自定义提醒重复规则
+//
+// 支持的属性: FREQ(DAILY/WEEKLY/MONTHLY/YEARLY)、INTERVAL、BYDAY、BYMONTHDAY、
+// BYMONTH、BYSETPOS、COUNT、UNTIL。触发的时分秒取自 DTSTART。
+// 另外支持用 "|EXRULE:" 追加排除规则，例如
+// "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR|EXRULE:FREQ=MONTHLY;BYDAY=FR;BYSETPOS=-1"
+// 表示"每个工作日，但不包括每月最后一个周五"。
+package rrule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency 重复频率
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+const (
+	exrulePrefix = "EXRULE:"
+	rrulePrefix  = "RRULE:"
+
+	// maxPeriods 单次展开最多遍历的周期数，防止永远无法匹配的规则死循环
+	maxPeriods = 10000
+	// maxExcluded 连续被排除规则过滤的最大次数
+	maxExcluded = 1000
+)
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// WeekdayNum BYDAY 中的一项，N 为 0 表示每个该星期几，负数表示倒数第几个
+type WeekdayNum struct {
+	Weekday time.Weekday
+	N       int
+}
+
+// Rule 解析后的 RRULE
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []time.Month
+	BySetPos   []int
+	Count      int
+
+	until      time.Time
+	untilLocal bool // UNTIL 为日期或不带 Z 的本地时间，按 DTSTART 所在时区解释
+}
+
+// Set 由 RRULE 和若干 EXRULE 组成的重复规则
+type Set struct {
+	RRule   *Rule
+	ExRules []*Rule
+}
+
+// ParseSet 解析 "RRULE|EXRULE:..." 格式的规则
+func ParseSet(s string) (*Set, error) {
+	parts := strings.Split(strings.TrimSpace(s), "|")
+
+	rule, err := Parse(parts[0])
+	if err != nil {
+		return nil, err
+	}
+
+	set := &Set{RRule: rule}
+	for _, part := range parts[1:] {
+		part = strings.TrimSpace(part)
+		if !strings.HasPrefix(strings.ToUpper(part), exrulePrefix) {
+			return nil, fmt.Errorf("排除规则必须以 EXRULE: 开头: %s", part)
+		}
+		exrule, err := Parse(part[len(exrulePrefix):])
+		if err != nil {
+			return nil, fmt.Errorf("EXRULE 无效: %w", err)
+		}
+		set.ExRules = append(set.ExRules, exrule)
+	}
+
+	return set, nil
+}
+
+// Parse 解析单条 RRULE，如 "FREQ=MONTHLY;BYDAY=MO;BYSETPOS=1"
+func Parse(s string) (*Rule, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	s = strings.TrimPrefix(s, rrulePrefix)
+	if s == "" {
+		return nil, fmt.Errorf("RRULE 不能为空")
+	}
+
+	rule := &Rule{Interval: 1}
+	seen := make(map[string]bool)
+
+	for _, item := range strings.Split(s, ";") {
+		if item == "" {
+			continue
+		}
+
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return nil, fmt.Errorf("RRULE 属性格式错误: %s", item)
+		}
+		key, value := kv[0], kv[1]
+		if seen[key] {
+			return nil, fmt.Errorf("RRULE 属性重复: %s", key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			switch Frequency(value) {
+			case Daily, Weekly, Monthly, Yearly:
+				rule.Freq = Frequency(value)
+			default:
+				return nil, fmt.Errorf("不支持的 FREQ: %s（支持 DAILY、WEEKLY、MONTHLY、YEARLY）", value)
+			}
+		case "INTERVAL":
+			rule.Interval, err = parseInt(key, value, 1, 1000)
+		case "COUNT":
+			rule.Count, err = parseInt(key, value, 1, 10000)
+		case "UNTIL":
+			err = rule.parseUntil(value)
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseIntList(key, value, 31)
+		case "BYMONTH":
+			var months []int
+			months, err = parseIntList(key, value, 12)
+			for _, m := range months {
+				if m < 0 {
+					return nil, fmt.Errorf("BYMONTH 不能为负数: %d", m)
+				}
+				rule.ByMonth = append(rule.ByMonth, time.Month(m))
+			}
+		case "BYSETPOS":
+			rule.BySetPos, err = parseIntList(key, value, 366)
+		case "WKST":
+			if value != "MO" {
+				return nil, fmt.Errorf("仅支持 WKST=MO")
+			}
+		default:
+			return nil, fmt.Errorf("不支持的 RRULE 属性: %s", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := rule.validate(); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *Rule) validate() error {
+	if r.Freq == "" {
+		return fmt.Errorf("RRULE 缺少 FREQ")
+	}
+	if r.Count > 0 && !r.until.IsZero() {
+		return fmt.Errorf("COUNT 和 UNTIL 不能同时使用")
+	}
+	if len(r.BySetPos) > 0 && len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 && len(r.ByMonth) == 0 {
+		return fmt.Errorf("BYSETPOS 需要与 BYDAY、BYMONTHDAY 或 BYMONTH 一起使用")
+	}
+	for _, day := range r.ByDay {
+		if day.N != 0 && r.Freq != Monthly && r.Freq != Yearly {
+			return fmt.Errorf("BYDAY 的序号只能用于 MONTHLY 或 YEARLY")
+		}
+		if day.N > 5 || day.N < -5 {
+			return fmt.Errorf("BYDAY 的序号必须在 -5 到 5 之间")
+		}
+	}
+	if r.Freq == Yearly && len(r.ByDay) > 0 && len(r.ByMonth) == 0 {
+		return fmt.Errorf("YEARLY 规则使用 BYDAY 时需要同时指定 BYMONTH")
+	}
+	if r.Freq == Weekly && len(r.ByMonthDay) > 0 {
+		return fmt.Errorf("WEEKLY 规则不能使用 BYMONTHDAY")
+	}
+	return nil
+}
+
+func (r *Rule) parseUntil(value string) error {
+	layouts := []struct {
+		layout string
+		local  bool
+	}{
+		{"20060102T150405Z", false},
+		{"20060102T150405", true},
+		{"20060102", true},
+	}
+
+	for _, l := range layouts {
+		t, err := time.Parse(l.layout, value)
+		if err != nil {
+			continue
+		}
+		if l.layout == "20060102" {
+			// 仅日期时包含当天全部时间
+			t = t.Add(24*time.Hour - time.Second)
+		}
+		r.until = t
+		r.untilLocal = l.local
+		return nil
+	}
+
+	return fmt.Errorf("UNTIL 格式错误: %s（应为 YYYYMMDD 或 YYYYMMDDTHHMMSSZ）", value)
+}
+
+// untilIn 返回按 DTSTART 时区解释后的 UNTIL
+func (r *Rule) untilIn(loc *time.Location) time.Time {
+	if r.until.IsZero() || !r.untilLocal {
+		return r.until
+	}
+	u := r.until
+	return time.Date(u.Year(), u.Month(), u.Day(), u.Hour(), u.Minute(), u.Second(), 0, loc)
+}
+
+// String 返回规范化后的 RRULE 文本
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]string, len(r.ByMonth))
+		for i, m := range r.ByMonth {
+			months[i] = strconv.Itoa(int(m))
+		}
+		parts = append(parts, "BYMONTH="+strings.Join(months, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			code := strings.ToUpper(d.Weekday.String()[:2])
+			if d.N != 0 {
+				code = strconv.Itoa(d.N) + code
+			}
+			days[i] = code
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.BySetPos) > 0 {
+		parts = append(parts, "BYSETPOS="+joinInts(r.BySetPos))
+	}
+	if r.Count > 0 {
+		parts = append(parts, fmt.Sprintf("COUNT=%d", r.Count))
+	}
+	if !r.until.IsZero() {
+		if r.untilLocal {
+			parts = append(parts, "UNTIL="+r.until.Format("20060102T150405"))
+		} else {
+			parts = append(parts, "UNTIL="+r.until.Format("20060102T150405Z"))
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+// String 返回规范化后的规则文本
+func (s *Set) String() string {
+	parts := []string{s.RRule.String()}
+	for _, exrule := range s.ExRules {
+		parts = append(parts, exrulePrefix+exrule.String())
+	}
+	return strings.Join(parts, "|")
+}
+
+// Next 返回 after 之后的第一次触发时间，没有更多触发时返回零值
+func (s *Set) Next(dtstart, after time.Time) time.Time {
+	next := s.RRule.Next(dtstart, after)
+	for i := 0; i < maxExcluded && !next.IsZero(); i++ {
+		if !s.excluded(dtstart, next) {
+			return next
+		}
+		next = s.RRule.Next(dtstart, next)
+	}
+	return time.Time{}
+}
+
+// Occurrences 返回 after 之后最多 n 次触发时间，用于预览
+func (s *Set) Occurrences(dtstart, after time.Time, n int) []time.Time {
+	var result []time.Time
+	for next := s.Next(dtstart, after); !next.IsZero() && len(result) < n; next = s.Next(dtstart, next) {
+		result = append(result, next)
+	}
+	return result
+}
+
+func (s *Set) excluded(dtstart, t time.Time) bool {
+	for _, exrule := range s.ExRules {
+		if exrule.Next(dtstart, t.Add(-time.Second)).Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Next 返回 after 之后的第一次触发时间，没有更多触发时返回零值
+func (r *Rule) Next(dtstart, after time.Time) time.Time {
+	loc := dtstart.Location()
+	until := r.untilIn(loc)
+
+	period := r.periodStart(dtstart)
+	// 没有 COUNT 时可以直接跳到 after 附近的周期，避免从 DTSTART 逐个展开
+	if r.Count == 0 && after.After(dtstart) {
+		skip := r.periodsBetween(period, r.periodStart(after.In(loc)))
+		skip = skip / r.Interval * r.Interval
+		if skip > r.Interval {
+			period = r.advance(period, skip-r.Interval)
+		}
+	}
+
+	emitted := 0
+	for i := 0; i < maxPeriods; i++ {
+		for _, candidate := range r.candidates(period, dtstart) {
+			if candidate.Before(dtstart) {
+				continue
+			}
+			if !until.IsZero() && candidate.After(until) {
+				return time.Time{}
+			}
+			emitted++
+			if r.Count > 0 && emitted > r.Count {
+				return time.Time{}
+			}
+			if candidate.After(after) {
+				return candidate
+			}
+		}
+		period = r.advance(period, r.Interval)
+	}
+
+	return time.Time{}
+}
+
+// periodStart 返回 t 所在周期的开始日期（零点）
+func (r *Rule) periodStart(t time.Time) time.Time {
+	y, m, d := t.Date()
+	loc := t.Location()
+	switch r.Freq {
+	case Weekly:
+		offset := (int(t.Weekday()) + 6) % 7 // 以周一为一周的开始
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, loc)
+	case Monthly:
+		return time.Date(y, m, 1, 0, 0, 0, 0, loc)
+	case Yearly:
+		return time.Date(y, time.January, 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, loc)
+	}
+}
+
+// advance 将周期向后移动 n 个单位
+func (r *Rule) advance(period time.Time, n int) time.Time {
+	switch r.Freq {
+	case Weekly:
+		return period.AddDate(0, 0, 7*n)
+	case Monthly:
+		return period.AddDate(0, n, 0)
+	case Yearly:
+		return period.AddDate(n, 0, 0)
+	default:
+		return period.AddDate(0, 0, n)
+	}
+}
+
+// periodsBetween 返回两个周期开始日期之间相差的周期数
+func (r *Rule) periodsBetween(from, to time.Time) int {
+	switch r.Freq {
+	case Weekly:
+		return (civilDays(to) - civilDays(from)) / 7
+	case Monthly:
+		return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+	case Yearly:
+		return to.Year() - from.Year()
+	default:
+		return civilDays(to) - civilDays(from)
+	}
+}
+
+// candidates 返回一个周期内满足规则的触发时间（已排序并应用 BYSETPOS）
+func (r *Rule) candidates(period, dtstart time.Time) []time.Time {
+	var days []time.Time
+
+	switch r.Freq {
+	case Daily:
+		if r.matchMonth(period.Month()) && r.matchMonthDay(period) && r.matchWeekday(period) {
+			days = append(days, period)
+		}
+	case Weekly:
+		for i := 0; i < 7; i++ {
+			day := period.AddDate(0, 0, i)
+			if !r.matchMonth(day.Month()) {
+				continue
+			}
+			if len(r.ByDay) == 0 && day.Weekday() != dtstart.Weekday() {
+				continue
+			}
+			if r.matchWeekday(day) {
+				days = append(days, day)
+			}
+		}
+	case Monthly:
+		if r.matchMonth(period.Month()) {
+			days = r.monthDays(period.Year(), period.Month(), dtstart, period.Location())
+		}
+	case Yearly:
+		months := r.ByMonth
+		if len(months) == 0 {
+			if len(r.ByMonthDay) > 0 {
+				// 只指定 BYMONTHDAY 时对每个月生效
+				months = []time.Month{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+			} else {
+				months = []time.Month{dtstart.Month()}
+			}
+		}
+		sorted := append([]time.Month(nil), months...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		for _, month := range sorted {
+			days = append(days, r.monthDays(period.Year(), month, dtstart, period.Location())...)
+		}
+	}
+
+	days = r.applySetPos(days)
+
+	result := make([]time.Time, 0, len(days))
+	for _, day := range days {
+		result = append(result, time.Date(day.Year(), day.Month(), day.Day(),
+			dtstart.Hour(), dtstart.Minute(), dtstart.Second(), 0, dtstart.Location()))
+	}
+	return result
+}
+
+// monthDays 返回某月中满足 BYMONTHDAY/BYDAY 的日期
+func (r *Rule) monthDays(year int, month time.Month, dtstart time.Time, loc *time.Location) []time.Time {
+	lastDay := daysIn(year, month)
+
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		if dtstart.Day() > lastDay {
+			return nil
+		}
+		return []time.Time{time.Date(year, month, dtstart.Day(), 0, 0, 0, 0, loc)}
+	}
+
+	var days []time.Time
+	for d := 1; d <= lastDay; d++ {
+		day := time.Date(year, month, d, 0, 0, 0, 0, loc)
+		if r.matchMonthDay(day) && r.matchNthWeekday(day, lastDay) {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+func (r *Rule) matchMonth(month time.Month) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, m := range r.ByMonth {
+		if m == month {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchMonthDay(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	lastDay := daysIn(day.Year(), day.Month())
+	for _, d := range r.ByMonthDay {
+		if d == day.Day() || (d < 0 && lastDay+d+1 == day.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchWeekday(day time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if wd.Weekday == day.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// matchNthWeekday 判断日期是否满足 BYDAY（含序号，序号按所在月份计算）
+func (r *Rule) matchNthWeekday(day time.Time, lastDay int) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if wd.Weekday != day.Weekday() {
+			continue
+		}
+		switch {
+		case wd.N == 0:
+			return true
+		case wd.N > 0 && (day.Day()-1)/7+1 == wd.N:
+			return true
+		case wd.N < 0 && (lastDay-day.Day())/7+1 == -wd.N:
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) applySetPos(days []time.Time) []time.Time {
+	if len(r.BySetPos) == 0 || len(days) == 0 {
+		return days
+	}
+
+	var result []time.Time
+	for i, day := range days {
+		for _, pos := range r.BySetPos {
+			if pos == i+1 || pos == i-len(days) {
+				result = append(result, day)
+				break
+			}
+		}
+	}
+	return result
+}
+
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var days []WeekdayNum
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("BYDAY 格式错误: %s", item)
+		}
+		code := item[len(item)-2:]
+		weekday, ok := weekdayCodes[code]
+		if !ok {
+			return nil, fmt.Errorf("BYDAY 星期代码错误: %s（应为 MO、TU、WE、TH、FR、SA、SU）", item)
+		}
+
+		n := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			var err error
+			n, err = strconv.Atoi(prefix)
+			if err != nil || n == 0 {
+				return nil, fmt.Errorf("BYDAY 序号错误: %s", item)
+			}
+		}
+		days = append(days, WeekdayNum{Weekday: weekday, N: n})
+	}
+	return days, nil
+}
+
+func parseInt(key, value string, min, max int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("%s 必须是 %d 到 %d 之间的整数: %s", key, min, max, value)
+	}
+	return n, nil
+}
+
+// parseIntList 解析逗号分隔的整数列表，允许负数但不允许 0
+func parseIntList(key, value string, max int) ([]int, error) {
+	var result []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(item)
+		if err != nil || n == 0 || n > max || n < -max {
+			return nil, fmt.Errorf("%s 取值错误: %s", key, item)
+		}
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ",")
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func civilDays(t time.Time) int {
+	return int(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
@@ -0,0 +1,157 @@
+package rrule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		wantErr string
+	}{
+		{"缺少FREQ", "INTERVAL=2", "缺少 FREQ"},
+		{"不支持的频率", "FREQ=HOURLY", "不支持的 FREQ"},
+		{"未知属性", "FREQ=DAILY;BYHOUR=9", "不支持的 RRULE 属性"},
+		{"属性重复", "FREQ=DAILY;FREQ=WEEKLY", "属性重复"},
+		{"COUNT与UNTIL冲突", "FREQ=DAILY;COUNT=3;UNTIL=20261231", "不能同时使用"},
+		{"BYDAY序号用于WEEKLY", "FREQ=WEEKLY;BYDAY=1MO", "只能用于 MONTHLY 或 YEARLY"},
+		{"星期代码错误", "FREQ=WEEKLY;BYDAY=XX", "星期代码错误"},
+		{"BYSETPOS单独使用", "FREQ=MONTHLY;BYSETPOS=1", "BYSETPOS 需要"},
+		{"UNTIL格式错误", "FREQ=DAILY;UNTIL=2026-12-31", "UNTIL 格式错误"},
+		{"INTERVAL为0", "FREQ=DAILY;INTERVAL=0", "INTERVAL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.rule)
+			if err == nil {
+				t.Fatalf("Parse(%q) 期望返回错误", tt.rule)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse(%q) 错误 = %v，期望包含 %q", tt.rule, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSet_Occurrences(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	// 2026-01-01 是周四
+	dtstart := time.Date(2026, 1, 1, 9, 0, 0, 0, loc)
+
+	tests := []struct {
+		name  string
+		rule  string
+		after time.Time
+		want  []string
+	}{
+		{
+			name:  "每个工作日",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+			after: dtstart.Add(-time.Second),
+			want:  []string{"2026-01-01", "2026-01-02", "2026-01-05", "2026-01-06"},
+		},
+		{
+			name:  "每月第一个周一",
+			rule:  "FREQ=MONTHLY;BYDAY=1MO",
+			after: dtstart,
+			want:  []string{"2026-01-05", "2026-02-02", "2026-03-02"},
+		},
+		{
+			name:  "每月最后一个工作日",
+			rule:  "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			after: dtstart,
+			want:  []string{"2026-01-30", "2026-02-27", "2026-03-31"},
+		},
+		{
+			name:  "每月倒数第一天",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1",
+			after: dtstart,
+			want:  []string{"2026-01-31", "2026-02-28", "2026-03-31"},
+		},
+		{
+			name:  "隔周周二",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU",
+			after: dtstart,
+			want:  []string{"2026-01-13", "2026-01-27", "2026-02-10"},
+		},
+		{
+			name:  "COUNT限制次数",
+			rule:  "FREQ=DAILY;COUNT=3",
+			after: dtstart.Add(-time.Second),
+			want:  []string{"2026-01-01", "2026-01-02", "2026-01-03"},
+		},
+		{
+			name:  "UNTIL截止日期",
+			rule:  "FREQ=WEEKLY;UNTIL=20260115",
+			after: dtstart.Add(-time.Second),
+			want:  []string{"2026-01-01", "2026-01-08", "2026-01-15"},
+		},
+		{
+			name:  "每年11月第四个周四",
+			rule:  "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH",
+			after: dtstart,
+			want:  []string{"2026-11-26", "2027-11-25"},
+		},
+		{
+			name:  "工作日但排除每月最后一个周五",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR|EXRULE:FREQ=MONTHLY;BYDAY=FR;BYSETPOS=-1",
+			after: time.Date(2026, 1, 28, 12, 0, 0, 0, loc),
+			want:  []string{"2026-01-29", "2026-02-02", "2026-02-03"},
+		},
+		{
+			name:  "远期跳跃计算",
+			rule:  "FREQ=DAILY;INTERVAL=3",
+			after: time.Date(2030, 6, 1, 0, 0, 0, 0, loc),
+			want:  []string{"2030-06-03", "2030-06-06"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := ParseSet(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseSet(%q) 失败: %v", tt.rule, err)
+			}
+
+			got := set.Occurrences(dtstart, tt.after, len(tt.want))
+			if len(got) != len(tt.want) {
+				t.Fatalf("Occurrences() 返回 %d 个，期望 %d 个: %v", len(got), len(tt.want), got)
+			}
+			for i, occurrence := range got {
+				if date := occurrence.Format("2006-01-02"); date != tt.want[i] {
+					t.Errorf("第 %d 次 = %s，期望 %s", i+1, date, tt.want[i])
+				}
+				if occurrence.Hour() != 9 || occurrence.Location() != loc {
+					t.Errorf("第 %d 次时间应为 DTSTART 时区的 09:00，实际 %s", i+1, occurrence)
+				}
+			}
+		})
+	}
+}
+
+func TestSet_NextAfterCountExhausted(t *testing.T) {
+	dtstart := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	set, err := ParseSet("FREQ=DAILY;COUNT=2")
+	if err != nil {
+		t.Fatalf("ParseSet() 失败: %v", err)
+	}
+
+	if next := set.Next(dtstart, dtstart.AddDate(0, 0, 1)); !next.IsZero() {
+		t.Errorf("COUNT 用尽后应返回零值，实际 %s", next)
+	}
+}
+
+func TestSet_String(t *testing.T) {
+	set, err := ParseSet("RRULE:freq=monthly;byday=-1fr|exrule:freq=yearly;bymonth=12;byday=-1FR")
+	if err != nil {
+		t.Fatalf("ParseSet() 失败: %v", err)
+	}
+	want := "FREQ=MONTHLY;BYDAY=-1FR|EXRULE:FREQ=YEARLY;BYMONTH=12;BYDAY=-1FR"
+	if got := set.String(); got != want {
+		t.Errorf("String() = %s，期望 %s", got, want)
+	}
+}